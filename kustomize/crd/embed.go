@@ -66,6 +66,14 @@ var (
 	//go:embed bases/kwok.x-k8s.io_clusterresourceusages.yaml
 	ClusterResourceUsage []byte
 
+	// Probe is the custom resource definition for probes.
+	//go:embed bases/kwok.x-k8s.io_probes.yaml
+	Probe []byte
+
+	// ClusterProbe is the custom resource definition for cluster probes.
+	//go:embed bases/kwok.x-k8s.io_clusterprobes.yaml
+	ClusterProbe []byte
+
 	// Metric is the custom resource definition for metrics.
 	//go:embed bases/kwok.x-k8s.io_metrics.yaml
 	Metric []byte
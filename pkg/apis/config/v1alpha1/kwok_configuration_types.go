@@ -48,6 +48,7 @@ type KwokConfigurationOptions struct {
 	EnableCRDs []string `json:"enableCRDs,omitempty"`
 
 	// The default IP assigned to the Pod on maintained Nodes.
+	// A comma-separated pair of IPv4 and IPv6 CIDRs allocates dual-stack IPs.
 	// is the default value for flag --cidr
 	// +default="10.0.0.0/24"
 	CIDR string `json:"cidr,omitempty"`
@@ -146,6 +147,12 @@ type KwokConfigurationOptions struct {
 	// +default=4
 	NodeLeaseParallelism uint `json:"nodeLeaseParallelism,omitempty"`
 
+	// NodeLeaseShardGroup is the name of the group of kwok replicas that share the managed nodes.
+	// The nodes are distributed among the live replicas in the same group,
+	// and the nodes of a dead replica are taken over by the others.
+	// It requires the node lease, empty means all the managed nodes are held by any replica.
+	NodeLeaseShardGroup string `json:"nodeLeaseShardGroup,omitempty"`
+
 	// PodsOnNodeSyncParallelism is the number of workers to sync pods on nodes in parallel.
 	// +default=1
 	PodsOnNodeSyncParallelism uint `json:"podsOnNodeSyncParallelism,omitempty"`
@@ -157,6 +164,60 @@ type KwokConfigurationOptions struct {
 	// EnablePodsOnNodeSyncStreamWatch enables stream watch for workers to sync pods on nodes.
 	// +default=false
 	EnablePodsOnNodeSyncStreamWatch *bool `json:"enablePodsOnNodeSyncStreamWatch"`
+
+	// EnableNodePressureEviction enables the simulation of node-pressure eviction,
+	// nodes get the MemoryPressure or DiskPressure condition and pods on them are evicted
+	// when the usage from ResourceUsage and ClusterResourceUsage crosses the allocatable of nodes.
+	// +default=false
+	EnableNodePressureEviction *bool `json:"enableNodePressureEviction"`
+
+	// RandomSeed is the seed of the random source used by stages,
+	// such as the jitter of delays, the weighted selection and the Rand function of CEL.
+	// Zero means a seed based on the current time is used.
+	RandomSeed int64 `json:"randomSeed,omitempty"`
+
+	// EnableLeaderElection enables the leader election among the replicas of kwok,
+	// only the leader plays the stages of nodes, pods and other resources,
+	// and the others are on standby to take over when the leader is gone.
+	// +default=false
+	EnableLeaderElection *bool `json:"enableLeaderElection"`
+
+	// LeaderElectionLeaseName is the name of the lease in the kube-system namespace used for the leader election.
+	// +default="kwok-controller"
+	LeaderElectionLeaseName string `json:"leaderElectionLeaseName,omitempty"`
+
+	// EnableNodeStatusFromPods enables the simulation of the fields of the status of nodes
+	// that kubelet reports from the pods on them, such as the images, the volumes in use and the allocatable,
+	// they are available to the stages of nodes by the template functions.
+	// +default=false
+	EnableNodeStatusFromPods *bool `json:"enableNodeStatusFromPods"`
+
+	// EnableNodeAllocatableFromPods shrinks the allocatable pods and ephemeral storage of nodes
+	// by the pods scheduled to them, which is reported by the stages of nodes with EnableNodeStatusFromPods.
+	// +default=false
+	EnableNodeAllocatableFromPods *bool `json:"enableNodeAllocatableFromPods"`
+
+	// AdminTokenFile is the file containing the bearer token of the admin API of the server,
+	// which introspects and pauses the controller, the admin API is disabled if it is empty.
+	AdminTokenFile string `json:"adminTokenFile,omitempty"`
+
+	// PodLogsDir is the directory to write the logs of the containers from the Logs and ClusterLogs
+	// in the CRI format and the same layout as /var/log/pods of kubelet,
+	// so that the node-level log collectors can read them, the logs are not written if it is empty.
+	PodLogsDir string `json:"podLogsDir,omitempty"`
+
+	// PodLogsMaxSizeBytes is the maximum size of a log file in the PodLogsDir before it is rotated.
+	// +default=10485760
+	PodLogsMaxSizeBytes int64 `json:"podLogsMaxSizeBytes,omitempty"`
+
+	// PodLogsMaxFiles is the maximum number of log files of a container in the PodLogsDir, including the current one.
+	// +default=5
+	PodLogsMaxFiles uint `json:"podLogsMaxFiles,omitempty"`
+
+	// EnableStatsSummary enables the kubelet Summary API and the /metrics/resource endpoint of the nodes on the server,
+	// the stats of the nodes, pods, containers and volumes are derived from the ResourceUsage and ClusterResourceUsage.
+	// +default=false
+	EnableStatsSummary *bool `json:"enableStatsSummary"`
 }
 
 // TracingConfiguration provides versioned configuration for OpenTelemetry tracing clients.
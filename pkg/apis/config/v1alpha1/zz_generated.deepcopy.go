@@ -244,6 +244,31 @@ func (in *KwokConfigurationOptions) DeepCopyInto(out *KwokConfigurationOptions)
 		*out = new(bool)
 		**out = **in
 	}
+	if in.EnableNodePressureEviction != nil {
+		in, out := &in.EnableNodePressureEviction, &out.EnableNodePressureEviction
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableLeaderElection != nil {
+		in, out := &in.EnableLeaderElection, &out.EnableLeaderElection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableNodeStatusFromPods != nil {
+		in, out := &in.EnableNodeStatusFromPods, &out.EnableNodeStatusFromPods
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableNodeAllocatableFromPods != nil {
+		in, out := &in.EnableNodeAllocatableFromPods, &out.EnableNodeAllocatableFromPods
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnableStatsSummary != nil {
+		in, out := &in.EnableStatsSummary, &out.EnableStatsSummary
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
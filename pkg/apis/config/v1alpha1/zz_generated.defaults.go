@@ -74,6 +74,35 @@ func SetObjectDefaults_KwokConfiguration(in *KwokConfiguration) {
 		var ptrVar1 bool = false
 		in.Options.EnablePodsOnNodeSyncStreamWatch = &ptrVar1
 	}
+	if in.Options.EnableNodePressureEviction == nil {
+		var ptrVar1 bool = false
+		in.Options.EnableNodePressureEviction = &ptrVar1
+	}
+	if in.Options.EnableLeaderElection == nil {
+		var ptrVar1 bool = false
+		in.Options.EnableLeaderElection = &ptrVar1
+	}
+	if in.Options.LeaderElectionLeaseName == "" {
+		in.Options.LeaderElectionLeaseName = "kwok-controller"
+	}
+	if in.Options.EnableNodeStatusFromPods == nil {
+		var ptrVar1 bool = false
+		in.Options.EnableNodeStatusFromPods = &ptrVar1
+	}
+	if in.Options.EnableNodeAllocatableFromPods == nil {
+		var ptrVar1 bool = false
+		in.Options.EnableNodeAllocatableFromPods = &ptrVar1
+	}
+	if in.Options.PodLogsMaxSizeBytes == 0 {
+		in.Options.PodLogsMaxSizeBytes = 10485760
+	}
+	if in.Options.PodLogsMaxFiles == 0 {
+		in.Options.PodLogsMaxFiles = 5
+	}
+	if in.Options.EnableStatsSummary == nil {
+		var ptrVar1 bool = false
+		in.Options.EnableStatsSummary = &ptrVar1
+	}
 }
 
 func SetObjectDefaults_KwokctlConfiguration(in *KwokctlConfiguration) {
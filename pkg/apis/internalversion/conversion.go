@@ -382,6 +382,50 @@ func ConvertToInternalClusterResourceUsage(in *v1alpha1.ClusterResourceUsage) (*
 	return &out, nil
 }
 
+// ConvertToV1Alpha1Probe converts an internal version Probe to a v1alpha1.Probe.
+func ConvertToV1Alpha1Probe(in *Probe) (*v1alpha1.Probe, error) {
+	var out v1alpha1.Probe
+	out.APIVersion = v1alpha1.GroupVersion.String()
+	out.Kind = v1alpha1.ProbeKind
+	err := Convert_internalversion_Probe_To_v1alpha1_Probe(in, &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConvertToInternalProbe converts a v1alpha1.Probe to an internal version.
+func ConvertToInternalProbe(in *v1alpha1.Probe) (*Probe, error) {
+	var out Probe
+	err := Convert_v1alpha1_Probe_To_internalversion_Probe(in, &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConvertToV1Alpha1ClusterProbe converts an internal version ClusterProbe to a v1alpha1.ClusterProbe.
+func ConvertToV1Alpha1ClusterProbe(in *ClusterProbe) (*v1alpha1.ClusterProbe, error) {
+	var out v1alpha1.ClusterProbe
+	out.APIVersion = v1alpha1.GroupVersion.String()
+	out.Kind = v1alpha1.ClusterProbeKind
+	err := Convert_internalversion_ClusterProbe_To_v1alpha1_ClusterProbe(in, &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ConvertToInternalClusterProbe converts a v1alpha1.ClusterProbe to an internal version.
+func ConvertToInternalClusterProbe(in *v1alpha1.ClusterProbe) (*ClusterProbe, error) {
+	var out ClusterProbe
+	err := Convert_v1alpha1_ClusterProbe_To_internalversion_ClusterProbe(in, &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // ConvertToV1Alpha1Metric converts an internal version Metric to a v1alpha1.Metric.
 func ConvertToV1Alpha1Metric(in *Metric) (*v1alpha1.Metric, error) {
 	var out v1alpha1.Metric
@@ -42,6 +42,11 @@ type ExecTarget struct {
 	Containers []string
 	// Local holds information how to exec to a local target.
 	Local *ExecTargetLocal
+	// Scripted holds canned responses to exec with, it takes precedence over Local and Container,
+	// which are used only if no response matches the command.
+	Scripted *ExecTargetScripted
+	// Container holds information how to exec in a container started on demand for each container of the pod.
+	Container *ExecTargetContainer
 }
 
 // ExecTargetLocal holds information how to exec to a local target.
@@ -54,6 +59,52 @@ type ExecTargetLocal struct {
 	SecurityContext *SecurityContext
 }
 
+// ExecTargetContainer holds information how to exec in a container started on demand.
+type ExecTargetContainer struct {
+	// Runtime is the container runtime to start the container with, one of docker, podman or nerdctl.
+	// If not set, docker is used.
+	Runtime string
+	// Image is the image of the container, which is expected to provide the sleep command.
+	Image string
+	// WorkDir is the working directory to exec with.
+	WorkDir string
+	// Envs is a list of environment variables to exec with.
+	Envs []EnvVar
+	// SecurityContext is the user context to exec.
+	SecurityContext *SecurityContext
+}
+
+// ExecTargetScripted holds canned responses for the commands executed in the container.
+type ExecTargetScripted struct {
+	// Responses is a list of responses, the first one matching the command is used.
+	Responses []ExecResponse
+}
+
+// ExecResponse holds a canned response for the matching commands.
+type ExecResponse struct {
+	// Match is how to match the command line, which is the command and its arguments joined by spaces.
+	Match ExecCommandMatch
+	// Stdout is the content written to the standard output.
+	Stdout string
+	// Stderr is the content written to the standard error.
+	Stderr string
+	// ExitCode is the exit code of the command.
+	ExitCode int32
+	// DelayMilliseconds is the time to wait before responding.
+	DelayMilliseconds int64
+}
+
+// ExecCommandMatch holds how to match the command line,
+// only one of the fields is expected to be set, and any command line matches if none is set.
+type ExecCommandMatch struct {
+	// Exact matches the command line exactly.
+	Exact string
+	// Prefix matches the command line starting with the prefix.
+	Prefix string
+	// Regex matches the command line with the regular expression.
+	Regex string
+}
+
 // EnvVar represents an environment variable present in a Container.
 type EnvVar struct {
 	// Name of the environment variable.
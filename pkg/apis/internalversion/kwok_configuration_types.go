@@ -37,6 +37,7 @@ type KwokConfigurationOptions struct {
 	EnableCRDs []string
 
 	// The default IP assigned to the Pod on maintained Nodes.
+	// A comma-separated pair of IPv4 and IPv6 CIDRs allocates dual-stack IPs.
 	CIDR string
 
 	// The ip of all nodes maintained by the Kwok
@@ -101,6 +102,12 @@ type KwokConfigurationOptions struct {
 	// NodeLeaseParallelism is the number of NodeLeases that are allowed to be processed in parallel.
 	NodeLeaseParallelism uint
 
+	// NodeLeaseShardGroup is the name of the group of kwok replicas that share the managed nodes.
+	// The nodes are distributed among the live replicas in the same group,
+	// and the nodes of a dead replica are taken over by the others.
+	// It requires the node lease, empty means all the managed nodes are held by any replica.
+	NodeLeaseShardGroup string
+
 	// PodsOnNodeSyncParallelism is the number of workers to sync pods on nodes in parallel.
 	PodsOnNodeSyncParallelism uint
 
@@ -109,6 +116,39 @@ type KwokConfigurationOptions struct {
 
 	// EnablePodsOnNodeSyncStreamWatch enables stream watch for workers to sync pods on nodes.
 	EnablePodsOnNodeSyncStreamWatch bool
+
+	// EnableNodePressureEviction enables the simulation of node-pressure eviction.
+	EnableNodePressureEviction bool
+
+	// RandomSeed is the seed of the random source used by stages.
+	RandomSeed int64
+
+	// EnableLeaderElection enables the leader election among the replicas of kwok.
+	EnableLeaderElection bool
+
+	// LeaderElectionLeaseName is the name of the lease used for the leader election.
+	LeaderElectionLeaseName string
+
+	// EnableNodeStatusFromPods enables the simulation of the status of nodes from the pods on them.
+	EnableNodeStatusFromPods bool
+
+	// EnableNodeAllocatableFromPods shrinks the allocatable of nodes by the pods scheduled to them.
+	EnableNodeAllocatableFromPods bool
+
+	// AdminTokenFile is the file containing the bearer token of the admin API of the server.
+	AdminTokenFile string
+
+	// PodLogsDir is the directory to write the logs of the containers in the CRI format.
+	PodLogsDir string
+
+	// PodLogsMaxSizeBytes is the maximum size of a log file in the PodLogsDir before it is rotated.
+	PodLogsMaxSizeBytes int64
+
+	// PodLogsMaxFiles is the maximum number of log files of a container in the PodLogsDir.
+	PodLogsMaxFiles uint
+
+	// EnableStatsSummary enables the kubelet Summary API and the /metrics/resource endpoint of the nodes.
+	EnableStatsSummary bool
 }
 
 // TracingConfiguration provides versioned configuration for OpenTelemetry tracing clients.
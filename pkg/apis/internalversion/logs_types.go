@@ -45,4 +45,20 @@ type Log struct {
 	PreviousLogsFile string
 	// Follow up if true
 	Follow bool
+	// Generator holds information how to generate synthetic logs instead of reading the LogsFile.
+	Generator *LogGenerator
+}
+
+// LogGenerator holds information how to generate synthetic logs.
+// The lines are generated at the rate since the container started,
+// and the content of each line only depends on its index and the seed.
+type LogGenerator struct {
+	// Template is the Go template to render each line of the logs,
+	// it is rendered with .Index, .Time, .PodName, .PodNamespace, .Container and .Rand of the line.
+	Template string
+	// LinesPerSecond is the number of lines generated per second.
+	// If not set, one line is generated per second.
+	LinesPerSecond int64
+	// Seed is the seed of the random values of .Rand in the template.
+	Seed int64
 }
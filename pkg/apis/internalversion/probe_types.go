@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package internalversion
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Probe provides the simulated probe results for a single pod.
+type Probe struct {
+	// Standard list metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	metav1.ObjectMeta
+	// Spec holds spec for probe.
+	Spec ProbeSpec
+}
+
+// ProbeSpec holds spec for probe.
+type ProbeSpec struct {
+	// Probes is a list of the simulated probes for the containers of the pod.
+	Probes []ProbeContainer
+}
+
+// ProbeContainer holds the simulated probes for containers.
+type ProbeContainer struct {
+	// Containers is list of container names.
+	Containers []string
+	// Startup is the results of the startup probe over time.
+	Startup []ProbeResult
+	// Readiness is the results of the readiness probe over time.
+	Readiness []ProbeResult
+	// Liveness is the results of the liveness probe over time.
+	Liveness []ProbeResult
+}
+
+// ProbeResult holds a result of the probe since a time after the container started.
+type ProbeResult struct {
+	// AfterMilliseconds is the time since the container started when the result begins.
+	AfterMilliseconds int64
+	// Success indicates whether the probe succeeds.
+	Success bool
+}
@@ -50,6 +50,32 @@ type StageSpec struct {
 	// Each step can define an event, patch, finalizer modification, or deletion action.
 	// Steps are executed in order when the stage is applied.
 	Steps []StageStep
+	// RateLimit limits the rate and the concurrency of playing this stage,
+	// so that a heavy stage does not starve the others.
+	RateLimit *StageRateLimit
+	// FailureRate is the percentage of the times this stage fails when it is matched,
+	// the FailureStage is played instead of this stage if it fails.
+	FailureRate int
+	// FailureRateFrom is the expression used to get the value of FailureRate.
+	// If it is a number type, convert to int.
+	// If it is a string type, the value get will be parsed by strconv.ParseInt.
+	FailureRateFrom *ExpressionFrom
+	// FailureStage is the name of the stage played when this stage fails,
+	// nothing is played if it is not set.
+	FailureStage string
+}
+
+// StageRateLimit limits the rate and the concurrency of playing a stage.
+type StageRateLimit struct {
+	// QPS is the max number of times per second the stage is played.
+	// Zero means no limit.
+	QPS int32
+	// Burst is the max number of times the stage is played at once within the QPS.
+	// If it is zero, the QPS is used.
+	Burst int32
+	// MaxInFlight is the max number of resources playing the stage at the same time.
+	// Zero means no limit.
+	MaxInFlight int32
 }
 
 // StageResourceRef specifies the kind and version of the resource.
@@ -91,6 +117,19 @@ type StageStep struct {
 	Delete bool
 	// Apply means that a resource will be applied.
 	Apply *StageApply
+	// Wait means that the next steps will wait until the condition is satisfied.
+	Wait *StageWait
+}
+
+// StageWait describes a step that waits until the condition is satisfied.
+type StageWait struct {
+	// CEL is the expression which must evaluate to true to continue the next steps.
+	CEL ExpressionCEL
+	// TimeoutMilliseconds indicates the max time to wait, it waits forever if not set.
+	TimeoutMilliseconds *int64
+	// TimeoutStage indicates the name of the stage played when the wait times out,
+	// the next steps are abandoned if not set.
+	TimeoutStage string
 }
 
 // StageApply describes the application of a resource in the next stage.
@@ -150,6 +150,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ClusterProbe)(nil), (*v1alpha1.ClusterProbe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ClusterProbe_To_v1alpha1_ClusterProbe(a.(*ClusterProbe), b.(*v1alpha1.ClusterProbe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ClusterProbe)(nil), (*ClusterProbe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ClusterProbe_To_internalversion_ClusterProbe(a.(*v1alpha1.ClusterProbe), b.(*ClusterProbe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ClusterProbeSpec)(nil), (*v1alpha1.ClusterProbeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ClusterProbeSpec_To_v1alpha1_ClusterProbeSpec(a.(*ClusterProbeSpec), b.(*v1alpha1.ClusterProbeSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ClusterProbeSpec)(nil), (*ClusterProbeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ClusterProbeSpec_To_internalversion_ClusterProbeSpec(a.(*v1alpha1.ClusterProbeSpec), b.(*ClusterProbeSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ClusterResourceUsage)(nil), (*v1alpha1.ClusterResourceUsage)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_ClusterResourceUsage_To_v1alpha1_ClusterResourceUsage(a.(*ClusterResourceUsage), b.(*v1alpha1.ClusterResourceUsage), scope)
 	}); err != nil {
@@ -230,6 +250,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ExecCommandMatch)(nil), (*v1alpha1.ExecCommandMatch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ExecCommandMatch_To_v1alpha1_ExecCommandMatch(a.(*ExecCommandMatch), b.(*v1alpha1.ExecCommandMatch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ExecCommandMatch)(nil), (*ExecCommandMatch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ExecCommandMatch_To_internalversion_ExecCommandMatch(a.(*v1alpha1.ExecCommandMatch), b.(*ExecCommandMatch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ExecResponse)(nil), (*v1alpha1.ExecResponse)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ExecResponse_To_v1alpha1_ExecResponse(a.(*ExecResponse), b.(*v1alpha1.ExecResponse), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ExecResponse)(nil), (*ExecResponse)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ExecResponse_To_internalversion_ExecResponse(a.(*v1alpha1.ExecResponse), b.(*ExecResponse), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ExecSpec)(nil), (*v1alpha1.ExecSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_ExecSpec_To_v1alpha1_ExecSpec(a.(*ExecSpec), b.(*v1alpha1.ExecSpec), scope)
 	}); err != nil {
@@ -250,6 +290,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ExecTargetContainer)(nil), (*v1alpha1.ExecTargetContainer)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ExecTargetContainer_To_v1alpha1_ExecTargetContainer(a.(*ExecTargetContainer), b.(*v1alpha1.ExecTargetContainer), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ExecTargetContainer)(nil), (*ExecTargetContainer)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ExecTargetContainer_To_internalversion_ExecTargetContainer(a.(*v1alpha1.ExecTargetContainer), b.(*ExecTargetContainer), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ExecTargetLocal)(nil), (*v1alpha1.ExecTargetLocal)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_ExecTargetLocal_To_v1alpha1_ExecTargetLocal(a.(*ExecTargetLocal), b.(*v1alpha1.ExecTargetLocal), scope)
 	}); err != nil {
@@ -260,6 +310,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ExecTargetScripted)(nil), (*v1alpha1.ExecTargetScripted)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ExecTargetScripted_To_v1alpha1_ExecTargetScripted(a.(*ExecTargetScripted), b.(*v1alpha1.ExecTargetScripted), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ExecTargetScripted)(nil), (*ExecTargetScripted)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ExecTargetScripted_To_internalversion_ExecTargetScripted(a.(*v1alpha1.ExecTargetScripted), b.(*ExecTargetScripted), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ExpressionCEL)(nil), (*v1alpha1.ExpressionCEL)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_ExpressionCEL_To_v1alpha1_ExpressionCEL(a.(*ExpressionCEL), b.(*v1alpha1.ExpressionCEL), scope)
 	}); err != nil {
@@ -405,6 +465,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*LogGenerator)(nil), (*v1alpha1.LogGenerator)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_LogGenerator_To_v1alpha1_LogGenerator(a.(*LogGenerator), b.(*v1alpha1.LogGenerator), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.LogGenerator)(nil), (*LogGenerator)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_LogGenerator_To_internalversion_LogGenerator(a.(*v1alpha1.LogGenerator), b.(*LogGenerator), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Logs)(nil), (*v1alpha1.Logs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_Logs_To_v1alpha1_Logs(a.(*Logs), b.(*v1alpha1.Logs), scope)
 	}); err != nil {
@@ -520,6 +590,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*Probe)(nil), (*v1alpha1.Probe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_Probe_To_v1alpha1_Probe(a.(*Probe), b.(*v1alpha1.Probe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.Probe)(nil), (*Probe)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Probe_To_internalversion_Probe(a.(*v1alpha1.Probe), b.(*Probe), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProbeContainer)(nil), (*v1alpha1.ProbeContainer)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ProbeContainer_To_v1alpha1_ProbeContainer(a.(*ProbeContainer), b.(*v1alpha1.ProbeContainer), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ProbeContainer)(nil), (*ProbeContainer)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ProbeContainer_To_internalversion_ProbeContainer(a.(*v1alpha1.ProbeContainer), b.(*ProbeContainer), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProbeResult)(nil), (*v1alpha1.ProbeResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ProbeResult_To_v1alpha1_ProbeResult(a.(*ProbeResult), b.(*v1alpha1.ProbeResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ProbeResult)(nil), (*ProbeResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ProbeResult_To_internalversion_ProbeResult(a.(*v1alpha1.ProbeResult), b.(*ProbeResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProbeSpec)(nil), (*v1alpha1.ProbeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_ProbeSpec_To_v1alpha1_ProbeSpec(a.(*ProbeSpec), b.(*v1alpha1.ProbeSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.ProbeSpec)(nil), (*ProbeSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ProbeSpec_To_internalversion_ProbeSpec(a.(*v1alpha1.ProbeSpec), b.(*ProbeSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ResourceUsage)(nil), (*v1alpha1.ResourceUsage)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_ResourceUsage_To_v1alpha1_ResourceUsage(a.(*ResourceUsage), b.(*v1alpha1.ResourceUsage), scope)
 	}); err != nil {
@@ -640,6 +750,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*StageRateLimit)(nil), (*v1alpha1.StageRateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_StageRateLimit_To_v1alpha1_StageRateLimit(a.(*StageRateLimit), b.(*v1alpha1.StageRateLimit), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.StageRateLimit)(nil), (*StageRateLimit)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StageRateLimit_To_internalversion_StageRateLimit(a.(*v1alpha1.StageRateLimit), b.(*StageRateLimit), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*StageResourceRef)(nil), (*v1alpha1.StageResourceRef)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_StageResourceRef_To_v1alpha1_StageResourceRef(a.(*StageResourceRef), b.(*v1alpha1.StageResourceRef), scope)
 	}); err != nil {
@@ -670,6 +790,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*StageWait)(nil), (*v1alpha1.StageWait)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_internalversion_StageWait_To_v1alpha1_StageWait(a.(*StageWait), b.(*v1alpha1.StageWait), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha1.StageWait)(nil), (*StageWait)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StageWait_To_internalversion_StageWait(a.(*v1alpha1.StageWait), b.(*StageWait), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*TracingConfiguration)(nil), (*configv1alpha1.TracingConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_internalversion_TracingConfiguration_To_v1alpha1_TracingConfiguration(a.(*TracingConfiguration), b.(*configv1alpha1.TracingConfiguration), scope)
 	}); err != nil {
@@ -1047,6 +1177,56 @@ func Convert_v1alpha1_ClusterPortForwardSpec_To_internalversion_ClusterPortForwa
 	return autoConvert_v1alpha1_ClusterPortForwardSpec_To_internalversion_ClusterPortForwardSpec(in, out, s)
 }
 
+func autoConvert_internalversion_ClusterProbe_To_v1alpha1_ClusterProbe(in *ClusterProbe, out *v1alpha1.ClusterProbe, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_internalversion_ClusterProbeSpec_To_v1alpha1_ClusterProbeSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_internalversion_ClusterProbe_To_v1alpha1_ClusterProbe is an autogenerated conversion function.
+func Convert_internalversion_ClusterProbe_To_v1alpha1_ClusterProbe(in *ClusterProbe, out *v1alpha1.ClusterProbe, s conversion.Scope) error {
+	return autoConvert_internalversion_ClusterProbe_To_v1alpha1_ClusterProbe(in, out, s)
+}
+
+func autoConvert_v1alpha1_ClusterProbe_To_internalversion_ClusterProbe(in *v1alpha1.ClusterProbe, out *ClusterProbe, s conversion.Scope) error {
+	// INFO: in.TypeMeta opted out of conversion generation
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_ClusterProbeSpec_To_internalversion_ClusterProbeSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	// INFO: in.Status opted out of conversion generation
+	return nil
+}
+
+// Convert_v1alpha1_ClusterProbe_To_internalversion_ClusterProbe is an autogenerated conversion function.
+func Convert_v1alpha1_ClusterProbe_To_internalversion_ClusterProbe(in *v1alpha1.ClusterProbe, out *ClusterProbe, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ClusterProbe_To_internalversion_ClusterProbe(in, out, s)
+}
+
+func autoConvert_internalversion_ClusterProbeSpec_To_v1alpha1_ClusterProbeSpec(in *ClusterProbeSpec, out *v1alpha1.ClusterProbeSpec, s conversion.Scope) error {
+	out.Selector = (*v1alpha1.ObjectSelector)(unsafe.Pointer(in.Selector))
+	out.Probes = *(*[]v1alpha1.ProbeContainer)(unsafe.Pointer(&in.Probes))
+	return nil
+}
+
+// Convert_internalversion_ClusterProbeSpec_To_v1alpha1_ClusterProbeSpec is an autogenerated conversion function.
+func Convert_internalversion_ClusterProbeSpec_To_v1alpha1_ClusterProbeSpec(in *ClusterProbeSpec, out *v1alpha1.ClusterProbeSpec, s conversion.Scope) error {
+	return autoConvert_internalversion_ClusterProbeSpec_To_v1alpha1_ClusterProbeSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_ClusterProbeSpec_To_internalversion_ClusterProbeSpec(in *v1alpha1.ClusterProbeSpec, out *ClusterProbeSpec, s conversion.Scope) error {
+	out.Selector = (*ObjectSelector)(unsafe.Pointer(in.Selector))
+	out.Probes = *(*[]ProbeContainer)(unsafe.Pointer(&in.Probes))
+	return nil
+}
+
+// Convert_v1alpha1_ClusterProbeSpec_To_internalversion_ClusterProbeSpec is an autogenerated conversion function.
+func Convert_v1alpha1_ClusterProbeSpec_To_internalversion_ClusterProbeSpec(in *v1alpha1.ClusterProbeSpec, out *ClusterProbeSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ClusterProbeSpec_To_internalversion_ClusterProbeSpec(in, out, s)
+}
+
 func autoConvert_internalversion_ClusterResourceUsage_To_v1alpha1_ClusterResourceUsage(in *ClusterResourceUsage, out *v1alpha1.ClusterResourceUsage, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_internalversion_ClusterResourceUsageSpec_To_v1alpha1_ClusterResourceUsageSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1313,6 +1493,62 @@ func Convert_v1alpha1_Exec_To_internalversion_Exec(in *v1alpha1.Exec, out *Exec,
 	return autoConvert_v1alpha1_Exec_To_internalversion_Exec(in, out, s)
 }
 
+func autoConvert_internalversion_ExecCommandMatch_To_v1alpha1_ExecCommandMatch(in *ExecCommandMatch, out *v1alpha1.ExecCommandMatch, s conversion.Scope) error {
+	out.Exact = in.Exact
+	out.Prefix = in.Prefix
+	out.Regex = in.Regex
+	return nil
+}
+
+// Convert_internalversion_ExecCommandMatch_To_v1alpha1_ExecCommandMatch is an autogenerated conversion function.
+func Convert_internalversion_ExecCommandMatch_To_v1alpha1_ExecCommandMatch(in *ExecCommandMatch, out *v1alpha1.ExecCommandMatch, s conversion.Scope) error {
+	return autoConvert_internalversion_ExecCommandMatch_To_v1alpha1_ExecCommandMatch(in, out, s)
+}
+
+func autoConvert_v1alpha1_ExecCommandMatch_To_internalversion_ExecCommandMatch(in *v1alpha1.ExecCommandMatch, out *ExecCommandMatch, s conversion.Scope) error {
+	out.Exact = in.Exact
+	out.Prefix = in.Prefix
+	out.Regex = in.Regex
+	return nil
+}
+
+// Convert_v1alpha1_ExecCommandMatch_To_internalversion_ExecCommandMatch is an autogenerated conversion function.
+func Convert_v1alpha1_ExecCommandMatch_To_internalversion_ExecCommandMatch(in *v1alpha1.ExecCommandMatch, out *ExecCommandMatch, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ExecCommandMatch_To_internalversion_ExecCommandMatch(in, out, s)
+}
+
+func autoConvert_internalversion_ExecResponse_To_v1alpha1_ExecResponse(in *ExecResponse, out *v1alpha1.ExecResponse, s conversion.Scope) error {
+	if err := Convert_internalversion_ExecCommandMatch_To_v1alpha1_ExecCommandMatch(&in.Match, &out.Match, s); err != nil {
+		return err
+	}
+	out.Stdout = in.Stdout
+	out.Stderr = in.Stderr
+	out.ExitCode = in.ExitCode
+	out.DelayMilliseconds = in.DelayMilliseconds
+	return nil
+}
+
+// Convert_internalversion_ExecResponse_To_v1alpha1_ExecResponse is an autogenerated conversion function.
+func Convert_internalversion_ExecResponse_To_v1alpha1_ExecResponse(in *ExecResponse, out *v1alpha1.ExecResponse, s conversion.Scope) error {
+	return autoConvert_internalversion_ExecResponse_To_v1alpha1_ExecResponse(in, out, s)
+}
+
+func autoConvert_v1alpha1_ExecResponse_To_internalversion_ExecResponse(in *v1alpha1.ExecResponse, out *ExecResponse, s conversion.Scope) error {
+	if err := Convert_v1alpha1_ExecCommandMatch_To_internalversion_ExecCommandMatch(&in.Match, &out.Match, s); err != nil {
+		return err
+	}
+	out.Stdout = in.Stdout
+	out.Stderr = in.Stderr
+	out.ExitCode = in.ExitCode
+	out.DelayMilliseconds = in.DelayMilliseconds
+	return nil
+}
+
+// Convert_v1alpha1_ExecResponse_To_internalversion_ExecResponse is an autogenerated conversion function.
+func Convert_v1alpha1_ExecResponse_To_internalversion_ExecResponse(in *v1alpha1.ExecResponse, out *ExecResponse, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ExecResponse_To_internalversion_ExecResponse(in, out, s)
+}
+
 func autoConvert_internalversion_ExecSpec_To_v1alpha1_ExecSpec(in *ExecSpec, out *v1alpha1.ExecSpec, s conversion.Scope) error {
 	out.Execs = *(*[]v1alpha1.ExecTarget)(unsafe.Pointer(&in.Execs))
 	return nil
@@ -1336,6 +1572,8 @@ func Convert_v1alpha1_ExecSpec_To_internalversion_ExecSpec(in *v1alpha1.ExecSpec
 func autoConvert_internalversion_ExecTarget_To_v1alpha1_ExecTarget(in *ExecTarget, out *v1alpha1.ExecTarget, s conversion.Scope) error {
 	out.Containers = *(*[]string)(unsafe.Pointer(&in.Containers))
 	out.Local = (*v1alpha1.ExecTargetLocal)(unsafe.Pointer(in.Local))
+	out.Scripted = (*v1alpha1.ExecTargetScripted)(unsafe.Pointer(in.Scripted))
+	out.Container = (*v1alpha1.ExecTargetContainer)(unsafe.Pointer(in.Container))
 	return nil
 }
 
@@ -1347,6 +1585,8 @@ func Convert_internalversion_ExecTarget_To_v1alpha1_ExecTarget(in *ExecTarget, o
 func autoConvert_v1alpha1_ExecTarget_To_internalversion_ExecTarget(in *v1alpha1.ExecTarget, out *ExecTarget, s conversion.Scope) error {
 	out.Containers = *(*[]string)(unsafe.Pointer(&in.Containers))
 	out.Local = (*ExecTargetLocal)(unsafe.Pointer(in.Local))
+	out.Scripted = (*ExecTargetScripted)(unsafe.Pointer(in.Scripted))
+	out.Container = (*ExecTargetContainer)(unsafe.Pointer(in.Container))
 	return nil
 }
 
@@ -1355,6 +1595,34 @@ func Convert_v1alpha1_ExecTarget_To_internalversion_ExecTarget(in *v1alpha1.Exec
 	return autoConvert_v1alpha1_ExecTarget_To_internalversion_ExecTarget(in, out, s)
 }
 
+func autoConvert_internalversion_ExecTargetContainer_To_v1alpha1_ExecTargetContainer(in *ExecTargetContainer, out *v1alpha1.ExecTargetContainer, s conversion.Scope) error {
+	out.Runtime = in.Runtime
+	out.Image = in.Image
+	out.WorkDir = in.WorkDir
+	out.Envs = *(*[]v1alpha1.EnvVar)(unsafe.Pointer(&in.Envs))
+	out.SecurityContext = (*v1alpha1.SecurityContext)(unsafe.Pointer(in.SecurityContext))
+	return nil
+}
+
+// Convert_internalversion_ExecTargetContainer_To_v1alpha1_ExecTargetContainer is an autogenerated conversion function.
+func Convert_internalversion_ExecTargetContainer_To_v1alpha1_ExecTargetContainer(in *ExecTargetContainer, out *v1alpha1.ExecTargetContainer, s conversion.Scope) error {
+	return autoConvert_internalversion_ExecTargetContainer_To_v1alpha1_ExecTargetContainer(in, out, s)
+}
+
+func autoConvert_v1alpha1_ExecTargetContainer_To_internalversion_ExecTargetContainer(in *v1alpha1.ExecTargetContainer, out *ExecTargetContainer, s conversion.Scope) error {
+	out.Runtime = in.Runtime
+	out.Image = in.Image
+	out.WorkDir = in.WorkDir
+	out.Envs = *(*[]EnvVar)(unsafe.Pointer(&in.Envs))
+	out.SecurityContext = (*SecurityContext)(unsafe.Pointer(in.SecurityContext))
+	return nil
+}
+
+// Convert_v1alpha1_ExecTargetContainer_To_internalversion_ExecTargetContainer is an autogenerated conversion function.
+func Convert_v1alpha1_ExecTargetContainer_To_internalversion_ExecTargetContainer(in *v1alpha1.ExecTargetContainer, out *ExecTargetContainer, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ExecTargetContainer_To_internalversion_ExecTargetContainer(in, out, s)
+}
+
 func autoConvert_internalversion_ExecTargetLocal_To_v1alpha1_ExecTargetLocal(in *ExecTargetLocal, out *v1alpha1.ExecTargetLocal, s conversion.Scope) error {
 	out.WorkDir = in.WorkDir
 	out.Envs = *(*[]v1alpha1.EnvVar)(unsafe.Pointer(&in.Envs))
@@ -1379,6 +1647,26 @@ func Convert_v1alpha1_ExecTargetLocal_To_internalversion_ExecTargetLocal(in *v1a
 	return autoConvert_v1alpha1_ExecTargetLocal_To_internalversion_ExecTargetLocal(in, out, s)
 }
 
+func autoConvert_internalversion_ExecTargetScripted_To_v1alpha1_ExecTargetScripted(in *ExecTargetScripted, out *v1alpha1.ExecTargetScripted, s conversion.Scope) error {
+	out.Responses = *(*[]v1alpha1.ExecResponse)(unsafe.Pointer(&in.Responses))
+	return nil
+}
+
+// Convert_internalversion_ExecTargetScripted_To_v1alpha1_ExecTargetScripted is an autogenerated conversion function.
+func Convert_internalversion_ExecTargetScripted_To_v1alpha1_ExecTargetScripted(in *ExecTargetScripted, out *v1alpha1.ExecTargetScripted, s conversion.Scope) error {
+	return autoConvert_internalversion_ExecTargetScripted_To_v1alpha1_ExecTargetScripted(in, out, s)
+}
+
+func autoConvert_v1alpha1_ExecTargetScripted_To_internalversion_ExecTargetScripted(in *v1alpha1.ExecTargetScripted, out *ExecTargetScripted, s conversion.Scope) error {
+	out.Responses = *(*[]ExecResponse)(unsafe.Pointer(&in.Responses))
+	return nil
+}
+
+// Convert_v1alpha1_ExecTargetScripted_To_internalversion_ExecTargetScripted is an autogenerated conversion function.
+func Convert_v1alpha1_ExecTargetScripted_To_internalversion_ExecTargetScripted(in *v1alpha1.ExecTargetScripted, out *ExecTargetScripted, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ExecTargetScripted_To_internalversion_ExecTargetScripted(in, out, s)
+}
+
 func autoConvert_internalversion_ExpressionCEL_To_v1alpha1_ExpressionCEL(in *ExpressionCEL, out *v1alpha1.ExpressionCEL, s conversion.Scope) error {
 	out.Expression = in.Expression
 	return nil
@@ -1610,6 +1898,7 @@ func autoConvert_internalversion_KwokConfigurationOptions_To_v1alpha1_KwokConfig
 	out.NodePlayStageParallelism = in.NodePlayStageParallelism
 	out.NodeLeaseDurationSeconds = in.NodeLeaseDurationSeconds
 	out.NodeLeaseParallelism = in.NodeLeaseParallelism
+	out.NodeLeaseShardGroup = in.NodeLeaseShardGroup
 	out.PodsOnNodeSyncParallelism = in.PodsOnNodeSyncParallelism
 	if err := v1.Convert_bool_To_Pointer_bool(&in.EnablePodsOnNodeSyncListPager, &out.EnablePodsOnNodeSyncListPager, s); err != nil {
 		return err
@@ -1617,6 +1906,27 @@ func autoConvert_internalversion_KwokConfigurationOptions_To_v1alpha1_KwokConfig
 	if err := v1.Convert_bool_To_Pointer_bool(&in.EnablePodsOnNodeSyncStreamWatch, &out.EnablePodsOnNodeSyncStreamWatch, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EnableNodePressureEviction, &out.EnableNodePressureEviction, s); err != nil {
+		return err
+	}
+	out.RandomSeed = in.RandomSeed
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EnableLeaderElection, &out.EnableLeaderElection, s); err != nil {
+		return err
+	}
+	out.LeaderElectionLeaseName = in.LeaderElectionLeaseName
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EnableNodeStatusFromPods, &out.EnableNodeStatusFromPods, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EnableNodeAllocatableFromPods, &out.EnableNodeAllocatableFromPods, s); err != nil {
+		return err
+	}
+	out.AdminTokenFile = in.AdminTokenFile
+	out.PodLogsDir = in.PodLogsDir
+	out.PodLogsMaxSizeBytes = in.PodLogsMaxSizeBytes
+	out.PodLogsMaxFiles = in.PodLogsMaxFiles
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EnableStatsSummary, &out.EnableStatsSummary, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1655,6 +1965,7 @@ func autoConvert_v1alpha1_KwokConfigurationOptions_To_internalversion_KwokConfig
 	out.NodePlayStageParallelism = in.NodePlayStageParallelism
 	out.NodeLeaseDurationSeconds = in.NodeLeaseDurationSeconds
 	out.NodeLeaseParallelism = in.NodeLeaseParallelism
+	out.NodeLeaseShardGroup = in.NodeLeaseShardGroup
 	out.PodsOnNodeSyncParallelism = in.PodsOnNodeSyncParallelism
 	if err := v1.Convert_Pointer_bool_To_bool(&in.EnablePodsOnNodeSyncListPager, &out.EnablePodsOnNodeSyncListPager, s); err != nil {
 		return err
@@ -1662,6 +1973,27 @@ func autoConvert_v1alpha1_KwokConfigurationOptions_To_internalversion_KwokConfig
 	if err := v1.Convert_Pointer_bool_To_bool(&in.EnablePodsOnNodeSyncStreamWatch, &out.EnablePodsOnNodeSyncStreamWatch, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EnableNodePressureEviction, &out.EnableNodePressureEviction, s); err != nil {
+		return err
+	}
+	out.RandomSeed = in.RandomSeed
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EnableLeaderElection, &out.EnableLeaderElection, s); err != nil {
+		return err
+	}
+	out.LeaderElectionLeaseName = in.LeaderElectionLeaseName
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EnableNodeStatusFromPods, &out.EnableNodeStatusFromPods, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EnableNodeAllocatableFromPods, &out.EnableNodeAllocatableFromPods, s); err != nil {
+		return err
+	}
+	out.AdminTokenFile = in.AdminTokenFile
+	out.PodLogsDir = in.PodLogsDir
+	out.PodLogsMaxSizeBytes = in.PodLogsMaxSizeBytes
+	out.PodLogsMaxFiles = in.PodLogsMaxFiles
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EnableStatsSummary, &out.EnableStatsSummary, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -2073,6 +2405,7 @@ func autoConvert_internalversion_Log_To_v1alpha1_Log(in *Log, out *v1alpha1.Log,
 	if err := v1.Convert_bool_To_Pointer_bool(&in.Follow, &out.Follow, s); err != nil {
 		return err
 	}
+	out.Generator = (*v1alpha1.LogGenerator)(unsafe.Pointer(in.Generator))
 	return nil
 }
 
@@ -2092,6 +2425,7 @@ func autoConvert_v1alpha1_Log_To_internalversion_Log(in *v1alpha1.Log, out *Log,
 	if err := v1.Convert_Pointer_bool_To_bool(&in.Follow, &out.Follow, s); err != nil {
 		return err
 	}
+	out.Generator = (*LogGenerator)(unsafe.Pointer(in.Generator))
 	return nil
 }
 
@@ -2100,6 +2434,30 @@ func Convert_v1alpha1_Log_To_internalversion_Log(in *v1alpha1.Log, out *Log, s c
 	return autoConvert_v1alpha1_Log_To_internalversion_Log(in, out, s)
 }
 
+func autoConvert_internalversion_LogGenerator_To_v1alpha1_LogGenerator(in *LogGenerator, out *v1alpha1.LogGenerator, s conversion.Scope) error {
+	out.Template = in.Template
+	out.LinesPerSecond = in.LinesPerSecond
+	out.Seed = in.Seed
+	return nil
+}
+
+// Convert_internalversion_LogGenerator_To_v1alpha1_LogGenerator is an autogenerated conversion function.
+func Convert_internalversion_LogGenerator_To_v1alpha1_LogGenerator(in *LogGenerator, out *v1alpha1.LogGenerator, s conversion.Scope) error {
+	return autoConvert_internalversion_LogGenerator_To_v1alpha1_LogGenerator(in, out, s)
+}
+
+func autoConvert_v1alpha1_LogGenerator_To_internalversion_LogGenerator(in *v1alpha1.LogGenerator, out *LogGenerator, s conversion.Scope) error {
+	out.Template = in.Template
+	out.LinesPerSecond = in.LinesPerSecond
+	out.Seed = in.Seed
+	return nil
+}
+
+// Convert_v1alpha1_LogGenerator_To_internalversion_LogGenerator is an autogenerated conversion function.
+func Convert_v1alpha1_LogGenerator_To_internalversion_LogGenerator(in *v1alpha1.LogGenerator, out *LogGenerator, s conversion.Scope) error {
+	return autoConvert_v1alpha1_LogGenerator_To_internalversion_LogGenerator(in, out, s)
+}
+
 func autoConvert_internalversion_Logs_To_v1alpha1_Logs(in *Logs, out *v1alpha1.Logs, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_internalversion_LogsSpec_To_v1alpha1_LogsSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -2412,6 +2770,102 @@ func Convert_v1alpha1_PortForwardSpec_To_internalversion_PortForwardSpec(in *v1a
 	return autoConvert_v1alpha1_PortForwardSpec_To_internalversion_PortForwardSpec(in, out, s)
 }
 
+func autoConvert_internalversion_Probe_To_v1alpha1_Probe(in *Probe, out *v1alpha1.Probe, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_internalversion_ProbeSpec_To_v1alpha1_ProbeSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_internalversion_Probe_To_v1alpha1_Probe is an autogenerated conversion function.
+func Convert_internalversion_Probe_To_v1alpha1_Probe(in *Probe, out *v1alpha1.Probe, s conversion.Scope) error {
+	return autoConvert_internalversion_Probe_To_v1alpha1_Probe(in, out, s)
+}
+
+func autoConvert_v1alpha1_Probe_To_internalversion_Probe(in *v1alpha1.Probe, out *Probe, s conversion.Scope) error {
+	// INFO: in.TypeMeta opted out of conversion generation
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_ProbeSpec_To_internalversion_ProbeSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	// INFO: in.Status opted out of conversion generation
+	return nil
+}
+
+// Convert_v1alpha1_Probe_To_internalversion_Probe is an autogenerated conversion function.
+func Convert_v1alpha1_Probe_To_internalversion_Probe(in *v1alpha1.Probe, out *Probe, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Probe_To_internalversion_Probe(in, out, s)
+}
+
+func autoConvert_internalversion_ProbeContainer_To_v1alpha1_ProbeContainer(in *ProbeContainer, out *v1alpha1.ProbeContainer, s conversion.Scope) error {
+	out.Containers = *(*[]string)(unsafe.Pointer(&in.Containers))
+	out.Startup = *(*[]v1alpha1.ProbeResult)(unsafe.Pointer(&in.Startup))
+	out.Readiness = *(*[]v1alpha1.ProbeResult)(unsafe.Pointer(&in.Readiness))
+	out.Liveness = *(*[]v1alpha1.ProbeResult)(unsafe.Pointer(&in.Liveness))
+	return nil
+}
+
+// Convert_internalversion_ProbeContainer_To_v1alpha1_ProbeContainer is an autogenerated conversion function.
+func Convert_internalversion_ProbeContainer_To_v1alpha1_ProbeContainer(in *ProbeContainer, out *v1alpha1.ProbeContainer, s conversion.Scope) error {
+	return autoConvert_internalversion_ProbeContainer_To_v1alpha1_ProbeContainer(in, out, s)
+}
+
+func autoConvert_v1alpha1_ProbeContainer_To_internalversion_ProbeContainer(in *v1alpha1.ProbeContainer, out *ProbeContainer, s conversion.Scope) error {
+	out.Containers = *(*[]string)(unsafe.Pointer(&in.Containers))
+	out.Startup = *(*[]ProbeResult)(unsafe.Pointer(&in.Startup))
+	out.Readiness = *(*[]ProbeResult)(unsafe.Pointer(&in.Readiness))
+	out.Liveness = *(*[]ProbeResult)(unsafe.Pointer(&in.Liveness))
+	return nil
+}
+
+// Convert_v1alpha1_ProbeContainer_To_internalversion_ProbeContainer is an autogenerated conversion function.
+func Convert_v1alpha1_ProbeContainer_To_internalversion_ProbeContainer(in *v1alpha1.ProbeContainer, out *ProbeContainer, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ProbeContainer_To_internalversion_ProbeContainer(in, out, s)
+}
+
+func autoConvert_internalversion_ProbeResult_To_v1alpha1_ProbeResult(in *ProbeResult, out *v1alpha1.ProbeResult, s conversion.Scope) error {
+	out.AfterMilliseconds = in.AfterMilliseconds
+	out.Success = in.Success
+	return nil
+}
+
+// Convert_internalversion_ProbeResult_To_v1alpha1_ProbeResult is an autogenerated conversion function.
+func Convert_internalversion_ProbeResult_To_v1alpha1_ProbeResult(in *ProbeResult, out *v1alpha1.ProbeResult, s conversion.Scope) error {
+	return autoConvert_internalversion_ProbeResult_To_v1alpha1_ProbeResult(in, out, s)
+}
+
+func autoConvert_v1alpha1_ProbeResult_To_internalversion_ProbeResult(in *v1alpha1.ProbeResult, out *ProbeResult, s conversion.Scope) error {
+	out.AfterMilliseconds = in.AfterMilliseconds
+	out.Success = in.Success
+	return nil
+}
+
+// Convert_v1alpha1_ProbeResult_To_internalversion_ProbeResult is an autogenerated conversion function.
+func Convert_v1alpha1_ProbeResult_To_internalversion_ProbeResult(in *v1alpha1.ProbeResult, out *ProbeResult, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ProbeResult_To_internalversion_ProbeResult(in, out, s)
+}
+
+func autoConvert_internalversion_ProbeSpec_To_v1alpha1_ProbeSpec(in *ProbeSpec, out *v1alpha1.ProbeSpec, s conversion.Scope) error {
+	out.Probes = *(*[]v1alpha1.ProbeContainer)(unsafe.Pointer(&in.Probes))
+	return nil
+}
+
+// Convert_internalversion_ProbeSpec_To_v1alpha1_ProbeSpec is an autogenerated conversion function.
+func Convert_internalversion_ProbeSpec_To_v1alpha1_ProbeSpec(in *ProbeSpec, out *v1alpha1.ProbeSpec, s conversion.Scope) error {
+	return autoConvert_internalversion_ProbeSpec_To_v1alpha1_ProbeSpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_ProbeSpec_To_internalversion_ProbeSpec(in *v1alpha1.ProbeSpec, out *ProbeSpec, s conversion.Scope) error {
+	out.Probes = *(*[]ProbeContainer)(unsafe.Pointer(&in.Probes))
+	return nil
+}
+
+// Convert_v1alpha1_ProbeSpec_To_internalversion_ProbeSpec is an autogenerated conversion function.
+func Convert_v1alpha1_ProbeSpec_To_internalversion_ProbeSpec(in *v1alpha1.ProbeSpec, out *ProbeSpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ProbeSpec_To_internalversion_ProbeSpec(in, out, s)
+}
+
 func autoConvert_internalversion_ResourceUsage_To_v1alpha1_ResourceUsage(in *ResourceUsage, out *v1alpha1.ResourceUsage, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_internalversion_ResourceUsageSpec_To_v1alpha1_ResourceUsageSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -2738,6 +3192,30 @@ func Convert_v1alpha1_StagePatch_To_internalversion_StagePatch(in *v1alpha1.Stag
 	return autoConvert_v1alpha1_StagePatch_To_internalversion_StagePatch(in, out, s)
 }
 
+func autoConvert_internalversion_StageRateLimit_To_v1alpha1_StageRateLimit(in *StageRateLimit, out *v1alpha1.StageRateLimit, s conversion.Scope) error {
+	out.QPS = in.QPS
+	out.Burst = in.Burst
+	out.MaxInFlight = in.MaxInFlight
+	return nil
+}
+
+// Convert_internalversion_StageRateLimit_To_v1alpha1_StageRateLimit is an autogenerated conversion function.
+func Convert_internalversion_StageRateLimit_To_v1alpha1_StageRateLimit(in *StageRateLimit, out *v1alpha1.StageRateLimit, s conversion.Scope) error {
+	return autoConvert_internalversion_StageRateLimit_To_v1alpha1_StageRateLimit(in, out, s)
+}
+
+func autoConvert_v1alpha1_StageRateLimit_To_internalversion_StageRateLimit(in *v1alpha1.StageRateLimit, out *StageRateLimit, s conversion.Scope) error {
+	out.QPS = in.QPS
+	out.Burst = in.Burst
+	out.MaxInFlight = in.MaxInFlight
+	return nil
+}
+
+// Convert_v1alpha1_StageRateLimit_To_internalversion_StageRateLimit is an autogenerated conversion function.
+func Convert_v1alpha1_StageRateLimit_To_internalversion_StageRateLimit(in *v1alpha1.StageRateLimit, out *StageRateLimit, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StageRateLimit_To_internalversion_StageRateLimit(in, out, s)
+}
+
 func autoConvert_internalversion_StageResourceRef_To_v1alpha1_StageResourceRef(in *StageResourceRef, out *v1alpha1.StageResourceRef, s conversion.Scope) error {
 	out.APIGroup = in.APIGroup
 	out.Kind = in.Kind
@@ -2840,6 +3318,18 @@ func autoConvert_internalversion_StageSpec_To_v1alpha1_StageSpec(in *StageSpec,
 		return err
 	}
 	out.Steps = *(*[]v1alpha1.StageStep)(unsafe.Pointer(&in.Steps))
+	out.RateLimit = (*v1alpha1.StageRateLimit)(unsafe.Pointer(in.RateLimit))
+	out.FailureRate = in.FailureRate
+	if in.FailureRateFrom != nil {
+		in, out := &in.FailureRateFrom, &out.FailureRateFrom
+		*out = new(v1alpha1.ExpressionFrom)
+		if err := Convert_internalversion_ExpressionFrom_To_v1alpha1_ExpressionFrom(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.FailureRateFrom = nil
+	}
+	out.FailureStage = in.FailureStage
 	return nil
 }
 
@@ -2880,6 +3370,18 @@ func autoConvert_v1alpha1_StageSpec_To_internalversion_StageSpec(in *v1alpha1.St
 		return err
 	}
 	out.Steps = *(*[]StageStep)(unsafe.Pointer(&in.Steps))
+	out.RateLimit = (*StageRateLimit)(unsafe.Pointer(in.RateLimit))
+	out.FailureRate = in.FailureRate
+	if in.FailureRateFrom != nil {
+		in, out := &in.FailureRateFrom, &out.FailureRateFrom
+		*out = new(ExpressionFrom)
+		if err := Convert_v1alpha1_ExpressionFrom_To_internalversion_ExpressionFrom(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.FailureRateFrom = nil
+	}
+	out.FailureStage = in.FailureStage
 	return nil
 }
 
@@ -2889,6 +3391,7 @@ func autoConvert_internalversion_StageStep_To_v1alpha1_StageStep(in *StageStep,
 	out.Finalizers = (*v1alpha1.StageFinalizers)(unsafe.Pointer(in.Finalizers))
 	out.Delete = in.Delete
 	out.Apply = (*v1alpha1.StageApply)(unsafe.Pointer(in.Apply))
+	out.Wait = (*v1alpha1.StageWait)(unsafe.Pointer(in.Wait))
 	return nil
 }
 
@@ -2903,6 +3406,7 @@ func autoConvert_v1alpha1_StageStep_To_internalversion_StageStep(in *v1alpha1.St
 	out.Finalizers = (*StageFinalizers)(unsafe.Pointer(in.Finalizers))
 	out.Delete = in.Delete
 	out.Apply = (*StageApply)(unsafe.Pointer(in.Apply))
+	out.Wait = (*StageWait)(unsafe.Pointer(in.Wait))
 	return nil
 }
 
@@ -2911,6 +3415,34 @@ func Convert_v1alpha1_StageStep_To_internalversion_StageStep(in *v1alpha1.StageS
 	return autoConvert_v1alpha1_StageStep_To_internalversion_StageStep(in, out, s)
 }
 
+func autoConvert_internalversion_StageWait_To_v1alpha1_StageWait(in *StageWait, out *v1alpha1.StageWait, s conversion.Scope) error {
+	if err := Convert_internalversion_ExpressionCEL_To_v1alpha1_ExpressionCEL(&in.CEL, &out.CEL, s); err != nil {
+		return err
+	}
+	out.TimeoutMilliseconds = (*int64)(unsafe.Pointer(in.TimeoutMilliseconds))
+	out.TimeoutStage = in.TimeoutStage
+	return nil
+}
+
+// Convert_internalversion_StageWait_To_v1alpha1_StageWait is an autogenerated conversion function.
+func Convert_internalversion_StageWait_To_v1alpha1_StageWait(in *StageWait, out *v1alpha1.StageWait, s conversion.Scope) error {
+	return autoConvert_internalversion_StageWait_To_v1alpha1_StageWait(in, out, s)
+}
+
+func autoConvert_v1alpha1_StageWait_To_internalversion_StageWait(in *v1alpha1.StageWait, out *StageWait, s conversion.Scope) error {
+	if err := Convert_v1alpha1_ExpressionCEL_To_internalversion_ExpressionCEL(&in.CEL, &out.CEL, s); err != nil {
+		return err
+	}
+	out.TimeoutMilliseconds = (*int64)(unsafe.Pointer(in.TimeoutMilliseconds))
+	out.TimeoutStage = in.TimeoutStage
+	return nil
+}
+
+// Convert_v1alpha1_StageWait_To_internalversion_StageWait is an autogenerated conversion function.
+func Convert_v1alpha1_StageWait_To_internalversion_StageWait(in *v1alpha1.StageWait, out *StageWait, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StageWait_To_internalversion_StageWait(in, out, s)
+}
+
 func autoConvert_internalversion_TracingConfiguration_To_v1alpha1_TracingConfiguration(in *TracingConfiguration, out *configv1alpha1.TracingConfiguration, s conversion.Scope) error {
 	if err := v1.Convert_string_To_Pointer_string(&in.Endpoint, &out.Endpoint, s); err != nil {
 		return err
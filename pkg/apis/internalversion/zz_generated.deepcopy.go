@@ -271,6 +271,52 @@ func (in *ClusterPortForwardSpec) DeepCopy() *ClusterPortForwardSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProbe) DeepCopyInto(out *ClusterProbe) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProbe.
+func (in *ClusterProbe) DeepCopy() *ClusterProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProbeSpec) DeepCopyInto(out *ClusterProbeSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(ObjectSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]ProbeContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProbeSpec.
+func (in *ClusterProbeSpec) DeepCopy() *ClusterProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterResourceUsage) DeepCopyInto(out *ClusterResourceUsage) {
 	*out = *in
@@ -477,6 +523,39 @@ func (in *Exec) DeepCopy() *Exec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecCommandMatch) DeepCopyInto(out *ExecCommandMatch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecCommandMatch.
+func (in *ExecCommandMatch) DeepCopy() *ExecCommandMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecCommandMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecResponse) DeepCopyInto(out *ExecResponse) {
+	*out = *in
+	out.Match = in.Match
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecResponse.
+func (in *ExecResponse) DeepCopy() *ExecResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecSpec) DeepCopyInto(out *ExecSpec) {
 	*out = *in
@@ -513,6 +592,16 @@ func (in *ExecTarget) DeepCopyInto(out *ExecTarget) {
 		*out = new(ExecTargetLocal)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Scripted != nil {
+		in, out := &in.Scripted, &out.Scripted
+		*out = new(ExecTargetScripted)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(ExecTargetContainer)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -526,6 +615,32 @@ func (in *ExecTarget) DeepCopy() *ExecTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecTargetContainer) DeepCopyInto(out *ExecTargetContainer) {
+	*out = *in
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]EnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecTargetContainer.
+func (in *ExecTargetContainer) DeepCopy() *ExecTargetContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecTargetContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecTargetLocal) DeepCopyInto(out *ExecTargetLocal) {
 	*out = *in
@@ -552,6 +667,27 @@ func (in *ExecTargetLocal) DeepCopy() *ExecTargetLocal {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecTargetScripted) DeepCopyInto(out *ExecTargetScripted) {
+	*out = *in
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = make([]ExecResponse, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecTargetScripted.
+func (in *ExecTargetScripted) DeepCopy() *ExecTargetScripted {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecTargetScripted)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExpressionCEL) DeepCopyInto(out *ExpressionCEL) {
 	*out = *in
@@ -910,6 +1046,11 @@ func (in *Log) DeepCopyInto(out *Log) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Generator != nil {
+		in, out := &in.Generator, &out.Generator
+		*out = new(LogGenerator)
+		**out = **in
+	}
 	return
 }
 
@@ -923,6 +1064,22 @@ func (in *Log) DeepCopy() *Log {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogGenerator) DeepCopyInto(out *LogGenerator) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogGenerator.
+func (in *LogGenerator) DeepCopy() *LogGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(LogGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Logs) DeepCopyInto(out *Logs) {
 	*out = *in
@@ -1172,6 +1329,99 @@ func (in *PortForwardSpec) DeepCopy() *PortForwardSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Probe) DeepCopyInto(out *Probe) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Probe.
+func (in *Probe) DeepCopy() *Probe {
+	if in == nil {
+		return nil
+	}
+	out := new(Probe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeContainer) DeepCopyInto(out *ProbeContainer) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeContainer.
+func (in *ProbeContainer) DeepCopy() *ProbeContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeResult) DeepCopyInto(out *ProbeResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeResult.
+func (in *ProbeResult) DeepCopy() *ProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSpec) DeepCopyInto(out *ProbeSpec) {
+	*out = *in
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]ProbeContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSpec.
+func (in *ProbeSpec) DeepCopy() *ProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceUsage) DeepCopyInto(out *ResourceUsage) {
 	*out = *in
@@ -1462,6 +1712,22 @@ func (in *StagePatch) DeepCopy() *StagePatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageRateLimit) DeepCopyInto(out *StageRateLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageRateLimit.
+func (in *StageRateLimit) DeepCopy() *StageRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(StageRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StageResourceRef) DeepCopyInto(out *StageResourceRef) {
 	*out = *in
@@ -1541,6 +1807,16 @@ func (in *StageSpec) DeepCopyInto(out *StageSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(StageRateLimit)
+		**out = **in
+	}
+	if in.FailureRateFrom != nil {
+		in, out := &in.FailureRateFrom, &out.FailureRateFrom
+		*out = new(ExpressionFrom)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1577,6 +1853,11 @@ func (in *StageStep) DeepCopyInto(out *StageStep) {
 		*out = new(StageApply)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(StageWait)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1590,6 +1871,28 @@ func (in *StageStep) DeepCopy() *StageStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageWait) DeepCopyInto(out *StageWait) {
+	*out = *in
+	out.CEL = in.CEL
+	if in.TimeoutMilliseconds != nil {
+		in, out := &in.TimeoutMilliseconds, &out.TimeoutMilliseconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageWait.
+func (in *StageWait) DeepCopy() *StageWait {
+	if in == nil {
+		return nil
+	}
+	out := new(StageWait)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TracingConfiguration) DeepCopyInto(out *TracingConfiguration) {
 	*out = *in
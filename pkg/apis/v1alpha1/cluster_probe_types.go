@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterProbeKind is the kind for ClusterProbe.
+	ClusterProbeKind = "ClusterProbe"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:rbac:groups=kwok.x-k8s.io,resources=clusterprobes,verbs=create;delete;get;list;patch;update;watch
+// +kubebuilder:rbac:groups=kwok.x-k8s.io,resources=clusterprobes/status,verbs=update;patch
+
+// ClusterProbe provides cluster-wide simulated probe results.
+type ClusterProbe struct {
+	//+k8s:conversion-gen=false
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec holds spec for cluster probe.
+	Spec ClusterProbeSpec `json:"spec"`
+	// Status holds status for cluster probe
+	//+k8s:conversion-gen=false
+	Status ClusterProbeStatus `json:"status,omitempty"`
+}
+
+// ClusterProbeStatus holds status for cluster probe
+type ClusterProbeStatus struct {
+	// Conditions holds conditions for cluster probe
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ClusterProbeSpec holds spec for cluster probe.
+type ClusterProbeSpec struct {
+	// Selector is a selector to filter pods to configure.
+	Selector *ObjectSelector `json:"selector,omitempty"`
+	// Probes is a list of the simulated probes for the containers of the pod.
+	Probes []ProbeContainer `json:"probes,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// ClusterProbeList is a list of ClusterProbe.
+type ClusterProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []ClusterProbe `json:"items"`
+}
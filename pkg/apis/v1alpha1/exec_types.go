@@ -68,6 +68,11 @@ type ExecTarget struct {
 	Containers []string `json:"containers,omitempty"`
 	// Local holds information how to exec to a local target.
 	Local *ExecTargetLocal `json:"local,omitempty"`
+	// Scripted holds canned responses to exec with, it takes precedence over Local and Container,
+	// which are used only if no response matches the command.
+	Scripted *ExecTargetScripted `json:"scripted,omitempty"`
+	// Container holds information how to exec in a container started on demand for each container of the pod.
+	Container *ExecTargetContainer `json:"container,omitempty"`
 }
 
 // ExecTargetLocal holds information how to exec to a local target.
@@ -80,6 +85,54 @@ type ExecTargetLocal struct {
 	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
 }
 
+// ExecTargetContainer holds information how to exec in a container started on demand.
+type ExecTargetContainer struct {
+	// Runtime is the container runtime to start the container with, one of docker, podman or nerdctl.
+	// If not set, docker is used.
+	Runtime string `json:"runtime,omitempty"`
+	// Image is the image of the container, which is expected to provide the sleep command.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+	// WorkDir is the working directory to exec with.
+	WorkDir string `json:"workDir,omitempty"`
+	// Envs is a list of environment variables to exec with.
+	Envs []EnvVar `json:"envs,omitempty"`
+	// SecurityContext is the user context to exec.
+	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+}
+
+// ExecTargetScripted holds canned responses for the commands executed in the container.
+type ExecTargetScripted struct {
+	// Responses is a list of responses, the first one matching the command is used.
+	Responses []ExecResponse `json:"responses,omitempty"`
+}
+
+// ExecResponse holds a canned response for the matching commands.
+type ExecResponse struct {
+	// Match is how to match the command line, which is the command and its arguments joined by spaces.
+	Match ExecCommandMatch `json:"match,omitempty"`
+	// Stdout is the content written to the standard output.
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr is the content written to the standard error.
+	Stderr string `json:"stderr,omitempty"`
+	// ExitCode is the exit code of the command.
+	ExitCode int32 `json:"exitCode,omitempty"`
+	// DelayMilliseconds is the time to wait before responding.
+	DelayMilliseconds int64 `json:"delayMilliseconds,omitempty"`
+}
+
+// ExecCommandMatch holds how to match the command line,
+// only one of the fields is expected to be set, and any command line matches if none is set.
+type ExecCommandMatch struct {
+	// Exact matches the command line exactly.
+	Exact string `json:"exact,omitempty"`
+	// Prefix matches the command line starting with the prefix.
+	Prefix string `json:"prefix,omitempty"`
+	// Regex matches the command line with the regular expression.
+	Regex string `json:"regex,omitempty"`
+}
+
 // EnvVar represents an environment variable present in a Container.
 type EnvVar struct {
 	// Name of the environment variable.
@@ -51,6 +51,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ClusterPortForward{}, &ClusterPortForwardList{},
 		&ResourceUsage{}, &ResourceUsageList{},
 		&ClusterResourceUsage{}, &ClusterResourceUsageList{},
+		&Probe{}, &ProbeList{},
+		&ClusterProbe{}, &ClusterProbeList{},
 		&Metric{}, &MetricList{},
 		&Stage{}, &StageList{},
 	)
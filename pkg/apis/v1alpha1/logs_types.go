@@ -71,6 +71,25 @@ type Log struct {
 	PreviousLogsFile *string `json:"previousLogsFile,omitempty"`
 	// Follow up if true
 	Follow *bool `json:"follow,omitempty"`
+	// Generator holds information how to generate synthetic logs instead of reading the LogsFile.
+	Generator *LogGenerator `json:"generator,omitempty"`
+}
+
+// LogGenerator holds information how to generate synthetic logs.
+// The lines are generated at the rate since the container started,
+// and the content of each line only depends on its index and the seed.
+type LogGenerator struct {
+	// Template is the Go template to render each line of the logs,
+	// it is rendered with .Index, .Time, .PodName, .PodNamespace, .Container and .Rand of the line.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Template string `json:"template"`
+	// LinesPerSecond is the number of lines generated per second.
+	// If not set, one line is generated per second.
+	// +kubebuilder:validation:Minimum=0
+	LinesPerSecond int64 `json:"linesPerSecond,omitempty"`
+	// Seed is the seed of the random values of .Rand in the template.
+	Seed int64 `json:"seed,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
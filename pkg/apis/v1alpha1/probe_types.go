@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ProbeKind is the kind for probe.
+	ProbeKind = "Probe"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +kubebuilder:subresource:status
+// +kubebuilder:rbac:groups=kwok.x-k8s.io,resources=probes,verbs=create;delete;get;list;patch;update;watch
+// +kubebuilder:rbac:groups=kwok.x-k8s.io,resources=probes/status,verbs=update;patch
+
+// Probe provides the simulated probe results for a single pod.
+type Probe struct {
+	//+k8s:conversion-gen=false
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	metav1.ObjectMeta `json:"metadata"`
+	// Spec holds spec for probe.
+	Spec ProbeSpec `json:"spec"`
+	// Status holds status for probe
+	//+k8s:conversion-gen=false
+	Status ProbeStatus `json:"status,omitempty"`
+}
+
+// ProbeStatus holds status for probe
+type ProbeStatus struct {
+	// Conditions holds conditions for probe
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ProbeSpec holds spec for probe.
+type ProbeSpec struct {
+	// Probes is a list of the simulated probes for the containers of the pod.
+	Probes []ProbeContainer `json:"probes,omitempty"`
+}
+
+// ProbeContainer holds the simulated probes for containers.
+// Only the probes defined in the spec of the containers are simulated,
+// and a probe without any results always succeeds.
+type ProbeContainer struct {
+	// Containers is list of container names.
+	Containers []string `json:"containers,omitempty"`
+	// Startup is the results of the startup probe over time.
+	Startup []ProbeResult `json:"startup,omitempty"`
+	// Readiness is the results of the readiness probe over time.
+	Readiness []ProbeResult `json:"readiness,omitempty"`
+	// Liveness is the results of the liveness probe over time.
+	Liveness []ProbeResult `json:"liveness,omitempty"`
+}
+
+// ProbeResult holds a result of the probe since a time after the container started.
+type ProbeResult struct {
+	// AfterMilliseconds is the time since the container started when the result begins,
+	// the result lasts until the next one begins.
+	AfterMilliseconds int64 `json:"afterMilliseconds,omitempty"`
+	// Success indicates whether the probe succeeds.
+	Success bool `json:"success"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// ProbeList is a list of Probe.
+type ProbeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Probe `json:"items"`
+}
@@ -30,6 +30,12 @@ const (
 // +genclient:nonNamespaced
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.resourceRef.kind`
+// +kubebuilder:printcolumn:name="Compiled",type=string,JSONPath=`.status.conditions[?(@.type=="Compiled")].status`
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedCount`
+// +kubebuilder:printcolumn:name="Played",type=integer,JSONPath=`.status.playedCount`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failedCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:rbac:groups=kwok.x-k8s.io,resources=stages,verbs=create;delete;get;list;patch;update;watch
 // +kubebuilder:rbac:groups=kwok.x-k8s.io,resources=stages/status,verbs=update;patch
 
@@ -55,8 +61,40 @@ type StageStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// MatchedCount is the number of times the stage has been matched by resources.
+	MatchedCount int64 `json:"matchedCount,omitempty"`
+	// PlayedCount is the number of times the stage has been played successfully.
+	PlayedCount int64 `json:"playedCount,omitempty"`
+	// FailedCount is the number of times the stage has failed to be played.
+	FailedCount int64 `json:"failedCount,omitempty"`
+	// LastError is the message of the last error that occurred while playing the stage.
+	LastError string `json:"lastError,omitempty"`
+	// Replicas is the status reported by each replica of kwok playing the stage,
+	// the counts above are the sums of the counts of the replicas.
+	// +listType=map
+	// +listMapKey=identity
+	Replicas []StageReplicaStatus `json:"replicas,omitempty"`
+}
+
+// StageReplicaStatus holds the status of a stage reported by a replica of kwok.
+type StageReplicaStatus struct {
+	// Identity is the identity of the replica of kwok.
+	Identity string `json:"identity"`
+	// MatchedCount is the number of times the stage has been matched by resources in the replica.
+	MatchedCount int64 `json:"matchedCount,omitempty"`
+	// PlayedCount is the number of times the stage has been played successfully in the replica.
+	PlayedCount int64 `json:"playedCount,omitempty"`
+	// FailedCount is the number of times the stage has failed to be played in the replica.
+	FailedCount int64 `json:"failedCount,omitempty"`
+	// LastError is the message of the last error that occurred while playing the stage in the replica.
+	LastError string `json:"lastError,omitempty"`
 }
 
+const (
+	// StageConditionCompiled means whether the expressions of the stage are compiled successfully.
+	StageConditionCompiled = "Compiled"
+)
+
 // StageSpec defines the specification for Stage.
 type StageSpec struct {
 	// ResourceRef specifies the Kind and version of the resource.
@@ -86,6 +124,37 @@ type StageSpec struct {
 	// Each step can define an event, patch, finalizer modification, or deletion action.
 	// Steps are executed in order when the stage is applied.
 	Steps []StageStep `json:"steps,omitempty"`
+	// RateLimit limits the rate and the concurrency of playing this stage,
+	// so that a heavy stage does not starve the others.
+	RateLimit *StageRateLimit `json:"rateLimit,omitempty"`
+	// FailureRate is the percentage of the times this stage fails when it is matched,
+	// the FailureStage is played instead of this stage if it fails.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	FailureRate int `json:"failureRate,omitempty"`
+	// FailureRateFrom is the expression used to get the value of FailureRate.
+	// If it is a number type, convert to int.
+	// If it is a string type, the value get will be parsed by strconv.ParseInt.
+	FailureRateFrom *ExpressionFrom `json:"failureRateFrom,omitempty"`
+	// FailureStage is the name of the stage played when this stage fails,
+	// nothing is played if it is not set.
+	FailureStage string `json:"failureStage,omitempty"`
+}
+
+// StageRateLimit limits the rate and the concurrency of playing a stage.
+type StageRateLimit struct {
+	// QPS is the max number of times per second the stage is played.
+	// Zero means no limit.
+	// +kubebuilder:validation:Minimum=0
+	QPS int32 `json:"qps,omitempty"`
+	// Burst is the max number of times the stage is played at once within the QPS.
+	// If it is zero, the QPS is used.
+	// +kubebuilder:validation:Minimum=0
+	Burst int32 `json:"burst,omitempty"`
+	// MaxInFlight is the max number of resources playing the stage at the same time.
+	// Zero means no limit.
+	// +kubebuilder:validation:Minimum=0
+	MaxInFlight int32 `json:"maxInFlight,omitempty"`
 }
 
 // StageResourceRef specifies the kind and version of the resource.
@@ -165,6 +234,20 @@ type StageStep struct {
 	Delete bool `json:"delete,omitempty"`
 	// Apply means that a resource will be applied.
 	Apply *StageApply `json:"apply,omitempty"`
+	// Wait means that the next steps will wait until the condition is satisfied.
+	Wait *StageWait `json:"wait,omitempty"`
+}
+
+// StageWait describes a step that waits until the condition is satisfied.
+type StageWait struct {
+	// CEL is the expression which must evaluate to true to continue the next steps.
+	CEL ExpressionCEL `json:"cel"`
+	// TimeoutMilliseconds indicates the max time to wait, it waits forever if not set.
+	// +kubebuilder:validation:Minimum=0
+	TimeoutMilliseconds *int64 `json:"timeoutMilliseconds,omitempty"`
+	// TimeoutStage indicates the name of the stage played when the wait times out,
+	// the next steps are abandoned if not set.
+	TimeoutStage string `json:"timeoutStage,omitempty"`
 }
 
 // StageApply describes the application of a resource in the next stage.
@@ -606,6 +606,118 @@ func (in *ClusterPortForwardStatus) DeepCopy() *ClusterPortForwardStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProbe) DeepCopyInto(out *ClusterProbe) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProbe.
+func (in *ClusterProbe) DeepCopy() *ClusterProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProbe) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProbeList) DeepCopyInto(out *ClusterProbeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterProbe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProbeList.
+func (in *ClusterProbeList) DeepCopy() *ClusterProbeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProbeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterProbeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProbeSpec) DeepCopyInto(out *ClusterProbeSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(ObjectSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]ProbeContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProbeSpec.
+func (in *ClusterProbeSpec) DeepCopy() *ClusterProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterProbeStatus) DeepCopyInto(out *ClusterProbeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterProbeStatus.
+func (in *ClusterProbeStatus) DeepCopy() *ClusterProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterResourceUsage) DeepCopyInto(out *ClusterResourceUsage) {
 	*out = *in
@@ -779,6 +891,22 @@ func (in *Exec) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecCommandMatch) DeepCopyInto(out *ExecCommandMatch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecCommandMatch.
+func (in *ExecCommandMatch) DeepCopy() *ExecCommandMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecCommandMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecList) DeepCopyInto(out *ExecList) {
 	*out = *in
@@ -812,6 +940,23 @@ func (in *ExecList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecResponse) DeepCopyInto(out *ExecResponse) {
+	*out = *in
+	out.Match = in.Match
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecResponse.
+func (in *ExecResponse) DeepCopy() *ExecResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecSpec) DeepCopyInto(out *ExecSpec) {
 	*out = *in
@@ -871,6 +1016,16 @@ func (in *ExecTarget) DeepCopyInto(out *ExecTarget) {
 		*out = new(ExecTargetLocal)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Scripted != nil {
+		in, out := &in.Scripted, &out.Scripted
+		*out = new(ExecTargetScripted)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(ExecTargetContainer)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -884,6 +1039,32 @@ func (in *ExecTarget) DeepCopy() *ExecTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecTargetContainer) DeepCopyInto(out *ExecTargetContainer) {
+	*out = *in
+	if in.Envs != nil {
+		in, out := &in.Envs, &out.Envs
+		*out = make([]EnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecTargetContainer.
+func (in *ExecTargetContainer) DeepCopy() *ExecTargetContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecTargetContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecTargetLocal) DeepCopyInto(out *ExecTargetLocal) {
 	*out = *in
@@ -910,6 +1091,27 @@ func (in *ExecTargetLocal) DeepCopy() *ExecTargetLocal {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecTargetScripted) DeepCopyInto(out *ExecTargetScripted) {
+	*out = *in
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = make([]ExecResponse, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecTargetScripted.
+func (in *ExecTargetScripted) DeepCopy() *ExecTargetScripted {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecTargetScripted)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExpressionCEL) DeepCopyInto(out *ExpressionCEL) {
 	*out = *in
@@ -1070,6 +1272,11 @@ func (in *Log) DeepCopyInto(out *Log) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Generator != nil {
+		in, out := &in.Generator, &out.Generator
+		*out = new(LogGenerator)
+		**out = **in
+	}
 	return
 }
 
@@ -1083,6 +1290,22 @@ func (in *Log) DeepCopy() *Log {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogGenerator) DeepCopyInto(out *LogGenerator) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogGenerator.
+func (in *LogGenerator) DeepCopy() *LogGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(LogGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Logs) DeepCopyInto(out *Logs) {
 	*out = *in
@@ -1519,6 +1742,165 @@ func (in *PortForwardStatus) DeepCopy() *PortForwardStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Probe) DeepCopyInto(out *Probe) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Probe.
+func (in *Probe) DeepCopy() *Probe {
+	if in == nil {
+		return nil
+	}
+	out := new(Probe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Probe) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeContainer) DeepCopyInto(out *ProbeContainer) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = make([]ProbeResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeContainer.
+func (in *ProbeContainer) DeepCopy() *ProbeContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeList) DeepCopyInto(out *ProbeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Probe, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeList.
+func (in *ProbeList) DeepCopy() *ProbeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProbeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeResult) DeepCopyInto(out *ProbeResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeResult.
+func (in *ProbeResult) DeepCopy() *ProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSpec) DeepCopyInto(out *ProbeSpec) {
+	*out = *in
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]ProbeContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSpec.
+func (in *ProbeSpec) DeepCopy() *ProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeStatus) DeepCopyInto(out *ProbeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeStatus.
+func (in *ProbeStatus) DeepCopy() *ProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceUsage) DeepCopyInto(out *ResourceUsage) {
 	*out = *in
@@ -1961,6 +2343,38 @@ func (in *StagePatch) DeepCopy() *StagePatch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageRateLimit) DeepCopyInto(out *StageRateLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageRateLimit.
+func (in *StageRateLimit) DeepCopy() *StageRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(StageRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageReplicaStatus) DeepCopyInto(out *StageReplicaStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageReplicaStatus.
+func (in *StageReplicaStatus) DeepCopy() *StageReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StageReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StageResourceRef) DeepCopyInto(out *StageResourceRef) {
 	*out = *in
@@ -2050,6 +2464,16 @@ func (in *StageSpec) DeepCopyInto(out *StageSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(StageRateLimit)
+		**out = **in
+	}
+	if in.FailureRateFrom != nil {
+		in, out := &in.FailureRateFrom, &out.FailureRateFrom
+		*out = new(ExpressionFrom)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2073,6 +2497,11 @@ func (in *StageStatus) DeepCopyInto(out *StageStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]StageReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2109,6 +2538,11 @@ func (in *StageStep) DeepCopyInto(out *StageStep) {
 		*out = new(StageApply)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(StageWait)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2121,3 +2555,25 @@ func (in *StageStep) DeepCopy() *StageStep {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageWait) DeepCopyInto(out *StageWait) {
+	*out = *in
+	out.CEL = in.CEL
+	if in.TimeoutMilliseconds != nil {
+		in, out := &in.TimeoutMilliseconds, &out.TimeoutMilliseconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageWait.
+func (in *StageWait) DeepCopy() *StageWait {
+	if in == nil {
+		return nil
+	}
+	out := new(StageWait)
+	in.DeepCopyInto(out)
+	return out
+}
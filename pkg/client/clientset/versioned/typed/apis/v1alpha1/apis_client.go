@@ -33,11 +33,13 @@ type KwokV1alpha1Interface interface {
 	ClusterExecsGetter
 	ClusterLogsGetter
 	ClusterPortForwardsGetter
+	ClusterProbesGetter
 	ClusterResourceUsagesGetter
 	ExecsGetter
 	LogsGetter
 	MetricsGetter
 	PortForwardsGetter
+	ProbesGetter
 	ResourceUsagesGetter
 	StagesGetter
 }
@@ -67,6 +69,10 @@ func (c *KwokV1alpha1Client) ClusterPortForwards() ClusterPortForwardInterface {
 	return newClusterPortForwards(c)
 }
 
+func (c *KwokV1alpha1Client) ClusterProbes() ClusterProbeInterface {
+	return newClusterProbes(c)
+}
+
 func (c *KwokV1alpha1Client) ClusterResourceUsages() ClusterResourceUsageInterface {
 	return newClusterResourceUsages(c)
 }
@@ -87,6 +93,10 @@ func (c *KwokV1alpha1Client) PortForwards(namespace string) PortForwardInterface
 	return newPortForwards(c, namespace)
 }
 
+func (c *KwokV1alpha1Client) Probes(namespace string) ProbeInterface {
+	return newProbes(c, namespace)
+}
+
 func (c *KwokV1alpha1Client) ResourceUsages(namespace string) ResourceUsageInterface {
 	return newResourceUsages(c, namespace)
 }
@@ -48,6 +48,10 @@ func (c *FakeKwokV1alpha1) ClusterPortForwards() v1alpha1.ClusterPortForwardInte
 	return newFakeClusterPortForwards(c)
 }
 
+func (c *FakeKwokV1alpha1) ClusterProbes() v1alpha1.ClusterProbeInterface {
+	return newFakeClusterProbes(c)
+}
+
 func (c *FakeKwokV1alpha1) ClusterResourceUsages() v1alpha1.ClusterResourceUsageInterface {
 	return newFakeClusterResourceUsages(c)
 }
@@ -68,6 +72,10 @@ func (c *FakeKwokV1alpha1) PortForwards(namespace string) v1alpha1.PortForwardIn
 	return newFakePortForwards(c, namespace)
 }
 
+func (c *FakeKwokV1alpha1) Probes(namespace string) v1alpha1.ProbeInterface {
+	return newFakeProbes(c, namespace)
+}
+
 func (c *FakeKwokV1alpha1) ResourceUsages(namespace string) v1alpha1.ResourceUsageInterface {
 	return newFakeResourceUsages(c, namespace)
 }
@@ -28,6 +28,8 @@ type ClusterLogsExpansion interface{}
 
 type ClusterPortForwardExpansion interface{}
 
+type ClusterProbeExpansion interface{}
+
 type ClusterResourceUsageExpansion interface{}
 
 type ExecExpansion interface{}
@@ -38,6 +40,8 @@ type MetricExpansion interface{}
 
 type PortForwardExpansion interface{}
 
+type ProbeExpansion interface{}
+
 type ResourceUsageExpansion interface{}
 
 type StageExpansion interface{}
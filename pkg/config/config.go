@@ -176,6 +176,18 @@ var configHandlers = map[string]configHandler{
 		MutateToInternal: mutateToInternalConfig(internalversion.ConvertToInternalClusterResourceUsage),
 		MutateToVersiond: mutateToVersiondConfig(internalversion.ConvertToV1Alpha1ClusterResourceUsage),
 	},
+	v1alpha1.ProbeKind: {
+		Unmarshal:        unmarshalConfig[*v1alpha1.Probe],
+		Marshal:          marshalConfig,
+		MutateToInternal: mutateToInternalConfig(internalversion.ConvertToInternalProbe),
+		MutateToVersiond: mutateToVersiondConfig(internalversion.ConvertToV1Alpha1Probe),
+	},
+	v1alpha1.ClusterProbeKind: {
+		Unmarshal:        unmarshalConfig[*v1alpha1.ClusterProbe],
+		Marshal:          marshalConfig,
+		MutateToInternal: mutateToInternalConfig(internalversion.ConvertToInternalClusterProbe),
+		MutateToVersiond: mutateToVersiondConfig(internalversion.ConvertToV1Alpha1ClusterProbe),
+	},
 	v1alpha1.MetricKind: {
 		Unmarshal:        unmarshalConfig[*v1alpha1.Metric],
 		Marshal:          marshalConfig,
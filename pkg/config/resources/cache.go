@@ -25,6 +25,7 @@ type cacheGetter[O any] struct {
 
 	currentVer string
 	data       O
+	cached     bool
 
 	mut sync.RWMutex
 }
@@ -36,7 +37,7 @@ func withCache[O any](getter Getter[O]) Getter[O] {
 func (g *cacheGetter[O]) Get() O {
 	g.mut.RLock()
 	latestVer := g.getter.Version()
-	if g.currentVer == latestVer {
+	if g.cached && g.currentVer == latestVer {
 		data := g.data
 		g.mut.RUnlock()
 		return data
@@ -45,7 +46,7 @@ func (g *cacheGetter[O]) Get() O {
 
 	g.mut.Lock()
 	defer g.mut.Unlock()
-	if g.currentVer == latestVer {
+	if g.cached && g.currentVer == latestVer {
 		data := g.data
 		return data
 	}
@@ -53,6 +54,7 @@ func (g *cacheGetter[O]) Get() O {
 	data := g.getter.Get()
 	g.data = data
 	g.currentVer = latestVer
+	g.cached = true
 	return data
 }
 
@@ -0,0 +1,317 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+// The same as the defaults of the leader election of kube-controller-manager.
+const (
+	leaderElectionLeaseDuration = 15 * time.Second
+	leaderElectionRenewDeadline = 10 * time.Second
+	leaderElectionRetryPeriod   = 2 * time.Second
+)
+
+type leaderElectionConfig struct {
+	TypedClient kubernetes.Interface
+	ID          string
+	LeaseName   string
+	// HealthzAddress is the address to serve the health checks while on standby.
+	HealthzAddress string
+	// HealthzCertFile and HealthzPrivateKeyFile serve the health checks over TLS as well if set,
+	// the same as the server does once leading.
+	HealthzCertFile       string
+	HealthzPrivateKeyFile string
+
+	// LeaseDuration, RenewDeadline and RetryPeriod default to the ones of kube-controller-manager if zero.
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// runWithLeaderElection waits until this replica becomes the leader and then calls start,
+// it returns when the context is done or the leadership is lost.
+// The drain is called before the lease is released, and bounded by the renew deadline,
+// so that the stage jobs being played are finished before the next leader starts.
+// The abort is called instead if the leadership is lost,
+// as the next leader may start as soon as the lease expires.
+func runWithLeaderElection(ctx context.Context, conf leaderElectionConfig, start func(ctx context.Context) error, drain func(ctx context.Context), abort func()) error {
+	logger := log.FromContext(ctx)
+
+	if conf.LeaseDuration == 0 {
+		conf.LeaseDuration = leaderElectionLeaseDuration
+	}
+	if conf.RenewDeadline == 0 {
+		conf.RenewDeadline = leaderElectionRenewDeadline
+	}
+	if conf.RetryPeriod == 0 {
+		conf.RetryPeriod = leaderElectionRetryPeriod
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      conf.LeaseName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Client: conf.TypedClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: conf.ID,
+		},
+	}
+
+	leadingCh := make(chan context.Context, 1)
+	stoppedCh := make(chan struct{})
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   conf.LeaseDuration,
+		RenewDeadline:   conf.RenewDeadline,
+		RetryPeriod:     conf.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            conf.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				leadingCh <- ctx
+			},
+			OnStoppedLeading: func() {
+				close(stoppedCh)
+			},
+			OnNewLeader: func(identity string) {
+				logger.Info("Observed the leader",
+					"leader", identity,
+				)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create leader elector: %w", err)
+	}
+
+	// The election is not canceled with the context,
+	// the lease is released only after the controller is drained.
+	electCtx, electCancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer electCancel()
+	go elector.Run(electCtx)
+
+	stepDown := func() {
+		electCancel()
+		<-stoppedCh
+	}
+
+	// The lease is still renewed while draining, the drain is bounded by the renew deadline
+	// so that stepping down is not blocked for long by the stage jobs being played.
+	drainAndStepDown := func() {
+		drainCtx, drainCancel := context.WithTimeout(context.WithoutCancel(ctx), conf.RenewDeadline)
+		defer drainCancel()
+		drain(drainCtx)
+		stepDown()
+	}
+
+	var standby *http.Server
+	if conf.HealthzAddress != "" {
+		standby, err = startStandbyHealthz(ctx, conf.HealthzAddress, conf.HealthzCertFile, conf.HealthzPrivateKeyFile)
+		if err != nil {
+			stepDown()
+			return err
+		}
+	}
+
+	var leadingCtx context.Context
+	select {
+	case <-ctx.Done():
+		if standby != nil {
+			_ = standby.Close()
+		}
+		stepDown()
+		return nil
+	case leadingCtx = <-leadingCh:
+	}
+
+	logger.Info("Started leading",
+		"lease", conf.LeaseName,
+	)
+
+	if standby != nil {
+		err = standby.Shutdown(ctx)
+		if err != nil {
+			stepDown()
+			return fmt.Errorf("failed to shutdown standby healthz server: %w", err)
+		}
+	}
+
+	runCtx, runCancel := context.WithCancel(leadingCtx)
+	defer runCancel()
+	stop := context.AfterFunc(ctx, runCancel)
+	defer stop()
+
+	err = start(runCtx)
+	if err != nil {
+		runCancel()
+		drainAndStepDown()
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-leadingCtx.Done():
+	}
+	runCancel()
+
+	if leadingCtx.Err() != nil && ctx.Err() == nil {
+		// The lease is not renewed in time and may be taken over by the next leader,
+		// so the stage jobs being played are aborted instead of drained.
+		abort()
+		stepDown()
+		return fmt.Errorf("leader election lost")
+	}
+
+	drainAndStepDown()
+	logger.Info("Stopped leading",
+		"lease", conf.LeaseName,
+	)
+	return nil
+}
+
+// startStandbyHealthz serves the health checks while on standby,
+// so that the replica is not restarted by the probes.
+// Both HTTP and HTTPS are served on the address if the cert and key are set.
+func startStandbyHealthz(ctx context.Context, address string, certFile, privateKeyFile string) (*http.Server, error) {
+	logger := log.FromContext(ctx)
+
+	var tlsConfig *tls.Config
+	if certFile != "" && privateKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load standby healthz server certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen standby healthz server: %w", err)
+	}
+	if tlsConfig != nil {
+		listener = &sniffTLSListener{
+			Listener: listener,
+			config:   tlsConfig,
+		}
+	}
+
+	mux := http.NewServeMux()
+	healthz := func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("ok"))
+	}
+	mux.HandleFunc("/healthz", healthz)
+	mux.HandleFunc("/readyz", healthz)
+	mux.HandleFunc("/livez", healthz)
+
+	svc := &http.Server{
+		ReadHeaderTimeout: 5 * time.Second,
+		Handler:           mux,
+	}
+	go func() {
+		logger.Info("Starting standby healthz server",
+			"address", address,
+		)
+		err := svc.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Failed to run standby healthz server",
+				"err", err,
+			)
+		}
+	}()
+	return svc, nil
+}
+
+// sniffTLSListener serves the TLS and the plain connections on the same listener.
+type sniffTLSListener struct {
+	net.Listener
+	config *tls.Config
+}
+
+func (l *sniffTLSListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &sniffTLSConn{
+		Conn:   conn,
+		config: l.config,
+	}, nil
+}
+
+// sniffTLSConn tells a TLS connection by the record type of the handshake on the first read,
+// it is not done on accept so that a slow client does not block the others.
+type sniffTLSConn struct {
+	net.Conn
+	config *tls.Config
+	once   sync.Once
+	conn   net.Conn
+}
+
+func (c *sniffTLSConn) sniff() {
+	reader := bufio.NewReader(c.Conn)
+	conn := &bufferedConn{
+		Conn:   c.Conn,
+		reader: reader,
+	}
+	head, err := reader.Peek(1)
+	if err == nil && head[0] == 0x16 {
+		c.conn = tls.Server(conn, c.config)
+		return
+	}
+	c.conn = conn
+}
+
+func (c *sniffTLSConn) Read(p []byte) (int, error) {
+	c.once.Do(c.sniff)
+	return c.conn.Read(p)
+}
+
+func (c *sniffTLSConn) Write(p []byte) (int, error) {
+	c.once.Do(c.sniff)
+	return c.conn.Write(p)
+}
+
+// bufferedConn reads the bytes peeked at first.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/kwok/pkg/kwokctl/pki"
+)
+
+func TestRunWithLeaderElection(t *testing.T) {
+	tests := []struct {
+		name      string
+		lose      bool
+		wantErr   bool
+		wantDrain bool
+		wantAbort bool
+	}{
+		{
+			name:      "step down",
+			wantDrain: true,
+		},
+		{
+			name:      "leadership lost",
+			lose:      true,
+			wantErr:   true,
+			wantAbort: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			// The lease can no longer be renewed once unreachable, e.g. the apiserver is down.
+			var unreachable atomic.Bool
+			clientset.PrependReactor("update", "leases", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if !unreachable.Load() {
+					return false, nil, nil
+				}
+				return true, nil, errors.New("unreachable")
+			})
+			conf := leaderElectionConfig{
+				TypedClient:   clientset,
+				ID:            "kwok-0",
+				LeaseName:     "kwok-controller",
+				LeaseDuration: time.Second,
+				RenewDeadline: 500 * time.Millisecond,
+				RetryPeriod:   100 * time.Millisecond,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			started := make(chan struct{})
+			start := func(ctx context.Context) error {
+				close(started)
+				return nil
+			}
+			var drained, aborted atomic.Bool
+			drain := func(ctx context.Context) {
+				deadline, ok := ctx.Deadline()
+				if !ok || time.Until(deadline) > conf.RenewDeadline {
+					t.Errorf("expected the drain to be bounded by the renew deadline, got %v", deadline)
+				}
+				drained.Store(true)
+			}
+			abort := func() {
+				aborted.Store(true)
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- runWithLeaderElection(ctx, conf, start, drain, abort)
+			}()
+
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected to start leading")
+			}
+
+			if tt.lose {
+				unreachable.Store(true)
+			} else {
+				cancel()
+			}
+
+			var err error
+			select {
+			case err = <-errCh:
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected to stop leading")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if drained.Load() != tt.wantDrain {
+				t.Errorf("expected drained %v, got %v", tt.wantDrain, drained.Load())
+			}
+			if aborted.Load() != tt.wantAbort {
+				t.Errorf("expected aborted %v, got %v", tt.wantAbort, aborted.Load())
+			}
+
+			if !tt.lose {
+				lease, err := clientset.CoordinationV1().Leases(metav1.NamespaceSystem).Get(context.Background(), conf.LeaseName, metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" {
+					t.Errorf("expected the lease to be released after the drain, got holder %q", *lease.Spec.HolderIdentity)
+				}
+			}
+		})
+	}
+}
+
+func TestStartStandbyHealthzTLS(t *testing.T) {
+	pkiPath := t.TempDir()
+	err := pki.GeneratePki(pkiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := os.ReadFile(filepath.Join(pkiPath, "ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caCert) {
+		t.Fatal("failed to load the CA")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	ctx := context.Background()
+	svc, err := startStandbyHealthz(ctx, address, filepath.Join(pkiPath, "admin.crt"), filepath.Join(pkiPath, "admin.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    rootCAs,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+	for _, url := range []string{
+		"https://" + address + "/healthz",
+		"http://" + address + "/healthz",
+	} {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", url, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+			t.Errorf("expected ok from %s, got %d %q", url, resp.StatusCode, body)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = svc.Shutdown(shutdownCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The address is released for the server once leading.
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = listener.Close()
+}
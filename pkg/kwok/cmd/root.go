@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -47,6 +48,7 @@ import (
 	"sigs.k8s.io/kwok/pkg/utils/format"
 	"sigs.k8s.io/kwok/pkg/utils/kubeconfig"
 	utilspath "sigs.k8s.io/kwok/pkg/utils/path"
+	utilsrand "sigs.k8s.io/kwok/pkg/utils/rand"
 	utilsslices "sigs.k8s.io/kwok/pkg/utils/slices"
 	"sigs.k8s.io/kwok/pkg/utils/version"
 	"sigs.k8s.io/kwok/pkg/utils/wait"
@@ -79,7 +81,7 @@ func NewCommand(ctx context.Context) *cobra.Command {
 
 	flags.Kubeconfig = utilspath.RelFromHome(kubeconfig.GetRecommendedKubeconfigPath())
 
-	cmd.Flags().StringVar(&flags.Options.CIDR, "cidr", flags.Options.CIDR, "CIDR of the pod ip")
+	cmd.Flags().StringVar(&flags.Options.CIDR, "cidr", flags.Options.CIDR, "CIDR of the pod ip, a comma-separated pair of IPv4 and IPv6 CIDRs for dual-stack")
 	cmd.Flags().StringVar(&flags.Options.NodeIP, "node-ip", flags.Options.NodeIP, "IP of the node")
 	cmd.Flags().StringVar(&flags.Options.NodeName, "node-name", flags.Options.NodeName, "Name of the node")
 	cmd.Flags().IntVar(&flags.Options.NodePort, "node-port", flags.Options.NodePort, "Port of the node")
@@ -97,6 +99,18 @@ func NewCommand(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVar(&flags.Master, "master", flags.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig).")
 	cmd.Flags().StringVar(&flags.Options.ServerAddress, "server-address", flags.Options.ServerAddress, "Address to expose the server on")
 	cmd.Flags().UintVar(&flags.Options.NodeLeaseDurationSeconds, "node-lease-duration-seconds", flags.Options.NodeLeaseDurationSeconds, "Duration of node lease seconds")
+	cmd.Flags().StringVar(&flags.Options.NodeLeaseShardGroup, "node-lease-shard-group", flags.Options.NodeLeaseShardGroup, "Name of the group of kwok replicas that distribute the managed nodes among themselves by node leases, it requires the node lease")
+	cmd.Flags().BoolVar(&flags.Options.EnableNodePressureEviction, "enable-node-pressure-eviction", flags.Options.EnableNodePressureEviction, "Set the pressure conditions of nodes and evict pods when the usage from ResourceUsage crosses the allocatable, it requires the server")
+	cmd.Flags().Int64Var(&flags.Options.RandomSeed, "random-seed", flags.Options.RandomSeed, "Seed of the random source used by stages, zero means a seed based on the current time")
+	cmd.Flags().BoolVar(&flags.Options.EnableLeaderElection, "enable-leader-election", flags.Options.EnableLeaderElection, "Enable the leader election among the replicas of kwok, only the leader plays the stages and the others are on standby")
+	cmd.Flags().StringVar(&flags.Options.LeaderElectionLeaseName, "leader-election-lease-name", flags.Options.LeaderElectionLeaseName, "Name of the lease in the kube-system namespace used for the leader election")
+	cmd.Flags().BoolVar(&flags.Options.EnableNodeStatusFromPods, "enable-node-status-from-pods", flags.Options.EnableNodeStatusFromPods, "Simulate the images, volumes in use and allocatable of nodes from the pods on them for the stages of nodes")
+	cmd.Flags().BoolVar(&flags.Options.EnableNodeAllocatableFromPods, "enable-node-allocatable-from-pods", flags.Options.EnableNodeAllocatableFromPods, "Shrink the allocatable pods and ephemeral storage of nodes by the pods scheduled to them, it requires --enable-node-status-from-pods")
+	cmd.Flags().StringVar(&flags.Options.AdminTokenFile, "admin-token-file", flags.Options.AdminTokenFile, "File containing the bearer token of the admin API of the server, which introspects and pauses the controller")
+	cmd.Flags().StringVar(&flags.Options.PodLogsDir, "pod-logs-dir", flags.Options.PodLogsDir, "Directory to write the logs of the containers from the Logs and ClusterLogs in the same layout as /var/log/pods of kubelet, it requires the server")
+	cmd.Flags().Int64Var(&flags.Options.PodLogsMaxSizeBytes, "pod-logs-max-size-bytes", flags.Options.PodLogsMaxSizeBytes, "Maximum size of a log file in the pod logs dir before it is rotated")
+	cmd.Flags().UintVar(&flags.Options.PodLogsMaxFiles, "pod-logs-max-files", flags.Options.PodLogsMaxFiles, "Maximum number of log files of a container in the pod logs dir, including the current one")
+	cmd.Flags().BoolVar(&flags.Options.EnableStatsSummary, "enable-stats-summary", flags.Options.EnableStatsSummary, "Serve the kubelet Summary API and /metrics/resource of the nodes with the stats from ResourceUsage, it requires the server")
 	cmd.Flags().StringSliceVar(&flags.Options.EnableCRDs, "enable-crds", flags.Options.EnableCRDs, "List of CRDs to enable")
 	cmd.Flags().StringVar(&flags.Tracing.Endpoint, "tracing-endpoint", flags.Tracing.Endpoint, "Tracing endpoint")
 	cmd.Flags().Int32Var(&flags.Tracing.SamplingRatePerMillion, "tracing-sampling-rate-per-million", flags.Tracing.SamplingRatePerMillion, "Tracing sampling rate per million")
@@ -116,6 +130,8 @@ var crdDefines = map[string]struct{}{
 	v1alpha1.ClusterLogsKind:          {},
 	v1alpha1.ResourceUsageKind:        {},
 	v1alpha1.ClusterResourceUsageKind: {},
+	v1alpha1.ProbeKind:                {},
+	v1alpha1.ClusterProbeKind:         {},
 	v1alpha1.MetricKind:               {},
 }
 
@@ -181,6 +197,17 @@ func runE(ctx context.Context, flags *flagpole) error {
 		}
 	}
 
+	if flags.Options.EnableNodePressureEviction && flags.Options.ServerAddress == "" && flags.Options.NodePort == 0 {
+		return fmt.Errorf("node pressure eviction requires the server to evaluate the resource usage, please specify --server-address or --node-port")
+	}
+
+	if flags.Options.RandomSeed != 0 {
+		utilsrand.Seed(flags.Options.RandomSeed)
+		logger.Info("Using the random seed",
+			"seed", flags.Options.RandomSeed,
+		)
+	}
+
 	if flags.Kubeconfig == "" && flags.Master == "" {
 		logger.Warn("Neither --kubeconfig nor --master was specified")
 		logger.Info("Using the inClusterConfig")
@@ -265,6 +292,30 @@ func runE(ctx context.Context, flags *flagpole) error {
 
 	metrics := config.FilterWithTypeFromContext[*internalversion.Metric](ctx)
 	enableMetrics := len(metrics) != 0 || slices.Contains(flags.Options.EnableCRDs, v1alpha1.MetricKind)
+
+	probes := config.FilterWithTypeFromContext[*internalversion.Probe](ctx)
+	err = checkConfigOrCRD(flags.Options.EnableCRDs, v1alpha1.ProbeKind, probes)
+	if err != nil {
+		return err
+	}
+
+	clusterProbes := config.FilterWithTypeFromContext[*internalversion.ClusterProbe](ctx)
+	err = checkConfigOrCRD(flags.Options.EnableCRDs, v1alpha1.ClusterProbeKind, clusterProbes)
+	if err != nil {
+		return err
+	}
+
+	enableProbes := len(probes) != 0 || len(clusterProbes) != 0 ||
+		slices.Contains(flags.Options.EnableCRDs, v1alpha1.ProbeKind) ||
+		slices.Contains(flags.Options.EnableCRDs, v1alpha1.ClusterProbeKind)
+
+	// The generated logs start when the container started, which is looked up from the pod cache.
+	enableLogGenerators := hasLogGenerator(
+		config.FilterWithTypeFromContext[*internalversion.Logs](ctx),
+		config.FilterWithTypeFromContext[*internalversion.ClusterLogs](ctx),
+	) ||
+		slices.Contains(flags.Options.EnableCRDs, v1alpha1.LogsKind) ||
+		slices.Contains(flags.Options.EnableCRDs, v1alpha1.ClusterLogsKind)
 	ctr, err := controllers.NewController(controllers.Config{
 		Clock:                                 clock.RealClock{},
 		DynamicClient:                         dynamicClient,
@@ -275,7 +326,14 @@ func runE(ctx context.Context, flags *flagpole) error {
 		TypedClient:                           typedClient,
 		TypedKwokClient:                       typedKwokClient,
 		EnableMetrics:                         enableMetrics,
-		EnablePodCache:                        enableMetrics,
+		EnablePodCache:                        enableMetrics || flags.Options.EnableNodePressureEviction || flags.Options.EnableNodeStatusFromPods || enableProbes || enableLogGenerators || flags.Options.PodLogsDir != "" || flags.Options.EnableStatsSummary,
+		EnableNodePressureEviction:            flags.Options.EnableNodePressureEviction,
+		EnableNodeStatusFromPods:              flags.Options.EnableNodeStatusFromPods,
+		EnableNodeAllocatableFromPods:         flags.Options.EnableNodeAllocatableFromPods,
+		EnableProbes:                          enableProbes,
+		EnableCRDs:                            flags.Options.EnableCRDs,
+		Probes:                                probes,
+		ClusterProbes:                         clusterProbes,
 		ManageSingleNode:                      flags.Options.ManageSingleNode,
 		ManageAllNodes:                        flags.Options.ManageAllNodes,
 		ManageNodesWithAnnotationSelector:     flags.Options.ManageNodesWithAnnotationSelector,
@@ -290,6 +348,7 @@ func runE(ctx context.Context, flags *flagpole) error {
 		NodePlayStageParallelism:              flags.Options.NodePlayStageParallelism,
 		LocalStages:                           groupStages,
 		NodeLeaseParallelism:                  flags.Options.NodeLeaseParallelism,
+		NodeLeaseShardGroup:                   flags.Options.NodeLeaseShardGroup,
 		NodeLeaseDurationSeconds:              flags.Options.NodeLeaseDurationSeconds,
 		ID:                                    id,
 		PodsOnNodeSyncParallelism:             flags.Options.PodsOnNodeSyncParallelism,
@@ -300,28 +359,59 @@ func runE(ctx context.Context, flags *flagpole) error {
 		return err
 	}
 
-	err = ctr.Start(ctx)
-	if err != nil {
-		return err
+	start := func(ctx context.Context) error {
+		err := ctr.Start(ctx)
+		if err != nil {
+			return err
+		}
+		return startServer(ctx, flags, ctr, typedKwokClient, tracingProvider)
+	}
+
+	if flags.Options.EnableLeaderElection {
+		logger.Info("Waiting for the leader election",
+			"lease", flags.Options.LeaderElectionLeaseName,
+		)
+		return runWithLeaderElection(ctx, leaderElectionConfig{
+			TypedClient:           typedClient,
+			ID:                    id,
+			LeaseName:             flags.Options.LeaderElectionLeaseName,
+			HealthzAddress:        getServerAddress(flags),
+			HealthzCertFile:       flags.Options.TLSCertFile,
+			HealthzPrivateKeyFile: flags.Options.TLSPrivateKeyFile,
+		}, start, ctr.Drain, ctr.Abort)
 	}
 
-	err = startServer(ctx, flags, ctr, typedKwokClient, tracingProvider)
+	err = start(ctx)
 	if err != nil {
 		return err
 	}
 
 	<-ctx.Done()
+
+	// The stage jobs being played are aborted if they are not finished in time,
+	// such as when the apiserver is unreachable.
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer drainCancel()
+	ctr.Drain(drainCtx)
 	return nil
 }
 
-func startServer(ctx context.Context, flags *flagpole, ctr *controllers.Controller, typedKwokClient versioned.Interface, tracingProvider tracing.TracerProvider) (err error) {
-	logger := log.FromContext(ctx)
+// drainTimeout is the max time to wait for the stage jobs being played to finish on shutdown,
+// the same as the renew deadline bounding the drain of the leader.
+const drainTimeout = leaderElectionRenewDeadline
 
+func getServerAddress(flags *flagpole) string {
 	serverAddress := flags.Options.ServerAddress
 	if serverAddress == "" && flags.Options.NodePort != 0 {
 		serverAddress = "0.0.0.0:" + format.String(flags.Options.NodePort)
 	}
+	return serverAddress
+}
 
+func startServer(ctx context.Context, flags *flagpole, ctr *controllers.Controller, typedKwokClient versioned.Interface, tracingProvider tracing.TracerProvider) (err error) {
+	logger := log.FromContext(ctx)
+
+	serverAddress := getServerAddress(flags)
 	if serverAddress != "" {
 		clusterPortForwards := config.FilterWithTypeFromContext[*internalversion.ClusterPortForward](ctx)
 		err = checkConfigOrCRD(flags.Options.EnableCRDs, v1alpha1.ClusterPortForwardKind, clusterPortForwards)
@@ -406,6 +496,8 @@ func startServer(ctx context.Context, flags *flagpole, ctr *controllers.Controll
 			DataSource:            ctr,
 			NodeCacheGetter:       ctr.GetNodeCache(),
 			PodCacheGetter:        ctr.GetPodCache(),
+			NodePartitioner:       ctr,
+			Admin:                 adminAdapter{ctr},
 		}
 		svc, err := server.NewServer(conf)
 		if err != nil {
@@ -426,6 +518,14 @@ func startServer(ctx context.Context, flags *flagpole, ctr *controllers.Controll
 			svc.InstallDebuggingDisabledHandlers()
 		}
 
+		if flags.Options.AdminTokenFile != "" {
+			token, err := os.ReadFile(flags.Options.AdminTokenFile)
+			if err != nil {
+				return fmt.Errorf("failed to read admin token file: %w", err)
+			}
+			svc.InstallAdmin(strings.TrimSpace(string(token)))
+		}
+
 		err = svc.InstallCRD(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to install crd: %w", err)
@@ -436,9 +536,34 @@ func startServer(ctx context.Context, flags *flagpole, ctr *controllers.Controll
 			return fmt.Errorf("failed to install metrics: %w", err)
 		}
 
+		if flags.Options.EnableStatsSummary {
+			err = svc.InstallStatsSummary()
+			if err != nil {
+				return fmt.Errorf("failed to install stats summary: %w", err)
+			}
+		}
+
+		if flags.Options.EnableNodePressureEviction {
+			err = ctr.StartNodePressureEviction(ctx, svc.PodResourceUsage)
+			if err != nil {
+				return fmt.Errorf("failed to start node pressure eviction: %w", err)
+			}
+		}
+
+		if flags.Options.PodLogsDir != "" {
+			err = svc.StartPodLogs(ctx, server.PodLogsConfig{
+				Dir:          flags.Options.PodLogsDir,
+				MaxSizeBytes: flags.Options.PodLogsMaxSizeBytes,
+				MaxFiles:     int(flags.Options.PodLogsMaxFiles),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to start pod logs: %w", err)
+			}
+		}
+
 		go func() {
 			err := svc.Run(ctx, serverAddress, flags.Options.TLSCertFile, flags.Options.TLSPrivateKeyFile)
-			if err != nil {
+			if err != nil && ctx.Err() == nil {
 				// allow the server exit when work on host network
 				podIP := envs.GetEnv("POD_IP", "")
 				hostIP := envs.GetEnv("HOST_IP", "")
@@ -453,11 +578,47 @@ func startServer(ctx context.Context, flags *flagpole, ctr *controllers.Controll
 			}
 		}()
 	}
-
-	<-ctx.Done()
 	return nil
 }
 
+// adminAdapter adapts the controller to the admin API of the server.
+type adminAdapter struct {
+	*controllers.Controller
+}
+
+// PendingJobs returns the stage jobs waiting to be played.
+func (a adminAdapter) PendingJobs() []server.AdminJob {
+	jobs := a.Controller.PendingJobs()
+	adminJobs := make([]server.AdminJob, 0, len(jobs))
+	for _, job := range jobs {
+		adminJobs = append(adminJobs, server.AdminJob{
+			Kind:          job.Kind,
+			Key:           job.Key,
+			Stage:         job.Stage,
+			ScheduledTime: job.ScheduledTime,
+		})
+	}
+	return adminJobs
+}
+
+// hasLogGenerator returns true if any of the logs generates synthetic logs.
+func hasLogGenerator(logs []*internalversion.Logs, clusterLogs []*internalversion.ClusterLogs) bool {
+	isGenerator := func(l internalversion.Log) bool {
+		return l.Generator != nil
+	}
+	for _, l := range logs {
+		if slices.ContainsFunc(l.Spec.Logs, isGenerator) {
+			return true
+		}
+	}
+	for _, cl := range clusterLogs {
+		if slices.ContainsFunc(cl.Spec.Logs, isGenerator) {
+			return true
+		}
+	}
+	return false
+}
+
 func checkConfigOrCRD[T metav1.Object](crds []string, kind string, crs []T) error {
 	if slices.Contains(crds, kind) && len(crs) != 0 {
 		return fmt.Errorf("%s already exists in --config, so please remove it, or remove %s from --enable-crd", kind, kind)
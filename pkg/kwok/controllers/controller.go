@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	coordinationv1 "k8s.io/api/coordination/v1"
@@ -47,6 +48,7 @@ import (
 	"sigs.k8s.io/kwok/pkg/utils/gotpl"
 	"sigs.k8s.io/kwok/pkg/utils/informer"
 	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
 	"sigs.k8s.io/kwok/pkg/utils/patch"
 	"sigs.k8s.io/kwok/pkg/utils/queue"
 	utilsslices "sigs.k8s.io/kwok/pkg/utils/slices"
@@ -56,22 +58,39 @@ var (
 	nodeKind = corev1.SchemeGroupVersion.WithKind("Node")
 )
 
+// stageStatusSyncInterval is the interval to report the status of stages.
+const stageStatusSyncInterval = 10 * time.Second
+
+// nodePressureSyncInterval is the same as the housekeeping interval of kubelet.
+const nodePressureSyncInterval = 10 * time.Second
+
+// probeSyncInterval is the interval to schedule the probes of the new pods.
+const probeSyncInterval = 1 * time.Second
+
 // Controller is a fake kubelet implementation that can be used to test
 type Controller struct {
 	conf Config
 
 	stagesManager *StagesManager
-
-	nodes       *NodeController
-	pods        *PodController
-	nodeLeases  *NodeLeaseController
-	broadcaster record.EventBroadcaster
-	recorder    record.EventRecorder
+	stageStatus   *StageStatusController
+
+	nodes        *NodeController
+	pods         *PodController
+	nodeLeases   *NodeLeaseController
+	nodeShard    *NodeLeaseShardController
+	nodePressure *NodePressureController
+	nodeStatus   *NodeStatusController
+	partitions   *NodePartitionController
+	probes       *ProbeController
+	broadcaster  record.EventBroadcaster
+	recorder     record.EventRecorder
 
 	nodeCacheGetter      informer.Getter[*corev1.Node]
 	podCacheGetter       informer.Getter[*corev1.Pod]
 	nodeLeaseCacheGetter informer.Getter[*coordinationv1.Lease]
 
+	objectLookup *objectLookup
+
 	onNodeManagedFunc   func(nodeName string)
 	onNodeUnmanagedFunc func(nodeName string)
 	readOnlyFunc        func(nodeName string) bool
@@ -92,9 +111,13 @@ type Controller struct {
 	patchMeta *patch.PatchMetaFromOpenAPI3
 
 	stageGetter resources.DynamicGetter[[]*internalversion.Stage]
+	stages      utilsmaps.SyncMap[internalversion.StageResourceRef, *StageController]
 
 	podOnNodeManageQueue queue.Queue[string]
 	nodeManageQueue      queue.Queue[string]
+
+	playingJobs *JobGroup
+	queueClock  *queue.FreezableClock
 }
 
 // Config is the configuration for the controller
@@ -122,12 +145,20 @@ type Config struct {
 	NodePlayStageParallelism              uint
 	NodeLeaseDurationSeconds              uint
 	NodeLeaseParallelism                  uint
+	NodeLeaseShardGroup                   string
 	PodsOnNodeSyncParallelism             uint
 	EnablePodsOnNodeSyncListPager         bool
 	EnablePodsOnNodeSyncStreamWatch       bool
 	ID                                    string
 	EnableMetrics                         bool
 	EnablePodCache                        bool
+	EnableNodePressureEviction            bool
+	EnableNodeStatusFromPods              bool
+	EnableNodeAllocatableFromPods         bool
+	EnableProbes                          bool
+	EnableCRDs                            []string
+	Probes                                []*internalversion.Probe
+	ClusterProbes                         []*internalversion.ClusterProbe
 	FuncMap                               gotpl.FuncMap
 }
 
@@ -148,6 +179,21 @@ func (c Config) validate() error {
 	default:
 		return fmt.Errorf("no nodes are managed")
 	}
+	if c.EnableNodePressureEviction && !c.EnablePodCache {
+		return fmt.Errorf("node pressure eviction requires the pod cache")
+	}
+	if c.EnableNodeStatusFromPods && !c.EnablePodCache {
+		return fmt.Errorf("node status from pods requires the pod cache")
+	}
+	if c.EnableNodeAllocatableFromPods && !c.EnableNodeStatusFromPods {
+		return fmt.Errorf("node allocatable from pods requires the node status from pods")
+	}
+	if c.EnableProbes && !c.EnablePodCache {
+		return fmt.Errorf("probes simulation requires the pod cache")
+	}
+	if c.NodeLeaseShardGroup != "" && c.NodeLeaseDurationSeconds == 0 {
+		return fmt.Errorf("node lease shard group requires node lease duration")
+	}
 	return nil
 }
 
@@ -158,8 +204,14 @@ func NewController(conf Config) (*Controller, error) {
 		return nil, err
 	}
 
+	if conf.Clock == nil {
+		conf.Clock = clock.RealClock{}
+	}
+
 	c := &Controller{
-		conf: conf,
+		conf:        conf,
+		playingJobs: NewJobGroup(),
+		queueClock:  queue.NewFreezableClock(conf.Clock),
 	}
 
 	return c, nil
@@ -201,6 +253,9 @@ func (c *Controller) init(ctx context.Context) (err error) {
 		return fmt.Errorf("failed to watch nodes: %w", err)
 	}
 
+	c.objectLookup = newObjectLookup(c.conf.DynamicClient, c.conf.RESTMapper, c.nodeCacheGetter)
+	c.objectLookup.Start(ctx)
+
 	podsCli := c.conf.TypedClient.CoreV1().Pods(corev1.NamespaceAll)
 	c.podsInformer = informer.NewInformer[*corev1.Pod, *corev1.PodList](podsCli)
 
@@ -221,6 +276,52 @@ func (c *Controller) init(ctx context.Context) (err error) {
 		c.nodeLeasesInformer = informer.NewInformer[*coordinationv1.Lease, *coordinationv1.LeaseList](nodeLeasesCli)
 	}
 
+	if c.conf.EnableNodePressureEviction {
+		c.nodePressure, err = NewNodePressureController(NodePressureControllerConfig{
+			Clock:           c.conf.Clock,
+			TypedClient:     c.conf.TypedClient,
+			NodeCacheGetter: c.nodeCacheGetter,
+			PodCacheGetter:  c.podCacheGetter,
+			ListNodes:       c.ListNodes,
+			ListPods:        c.ListPods,
+			ReadOnlyFunc:    c.idle,
+			Recorder:        c.recorder,
+			SyncInterval:    nodePressureSyncInterval,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create node pressure controller: %w", err)
+		}
+	}
+
+	if c.conf.EnableNodeStatusFromPods {
+		c.nodeStatus, err = NewNodeStatusController(NodeStatusControllerConfig{
+			TypedClient:       c.conf.TypedClient,
+			NodeCacheGetter:   c.nodeCacheGetter,
+			PodCacheGetter:    c.podCacheGetter,
+			ListPods:          c.ListPods,
+			ShrinkAllocatable: c.conf.EnableNodeAllocatableFromPods,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create node status controller: %w", err)
+		}
+		err = c.nodeStatus.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start node status controller: %w", err)
+		}
+	}
+
+	c.partitions, err = NewNodePartitionController(NodePartitionControllerConfig{
+		Clock:            c.conf.Clock,
+		OnNodeHealedFunc: c.onNodeHealed,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create node partition controller: %w", err)
+	}
+	err = c.partitions.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start node partition controller: %w", err)
+	}
+
 	c.patchMeta = patch.NewPatchMetaFromOpenAPI3(c.conf.RESTClient)
 
 	c.podOnNodeManageQueue = queue.NewQueue[string]()
@@ -250,8 +351,31 @@ func (c *Controller) initNodeLeaseController(ctx context.Context) error {
 	renewInterval := leaseDuration / 4
 	// https://github.com/kubernetes/component-helpers/blob/d17b6f1e84500ee7062a26f5327dc73cb3e9374a/apimachinery/lease/controller.go#L100
 	renewIntervalJitter := 0.04
+
+	var isOwnerFunc func(nodeName string) bool
+	if c.conf.NodeLeaseShardGroup != "" {
+		c.nodeShard, err = NewNodeLeaseShardController(NodeLeaseShardControllerConfig{
+			Clock:                c.conf.Clock,
+			TypedClient:          c.conf.TypedClient,
+			Group:                c.conf.NodeLeaseShardGroup,
+			HolderIdentity:       c.conf.ID,
+			LeaseDurationSeconds: c.conf.NodeLeaseDurationSeconds,
+			RenewInterval:        renewInterval,
+			OnMembersChangedFunc: func(members []string) {
+				if c.nodeLeases != nil {
+					c.nodeLeases.Rebalance(ctx)
+				}
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create node lease shard controller: %w", err)
+		}
+		isOwnerFunc = c.nodeShard.IsOwner
+	}
+
 	c.nodeLeases, err = NewNodeLeaseController(NodeLeaseControllerConfig{
 		Clock:                c.conf.Clock,
+		QueueClock:           c.queueClock,
 		TypedClient:          c.conf.TypedClient,
 		LeaseDurationSeconds: c.conf.NodeLeaseDurationSeconds,
 		LeaseParallelism:     c.conf.NodeLeaseParallelism,
@@ -280,11 +404,21 @@ func (c *Controller) initNodeLeaseController(ctx context.Context) error {
 			c.nodeManageQueue.Add(nodeName)
 			c.podOnNodeManageQueue.Add(nodeName)
 		},
+		IsOwnerFunc:     isOwnerFunc,
+		PartitionedFunc: c.partitions.Partitioned,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create node leases controller: %w", err)
 	}
 
+	if c.nodeShard != nil {
+		// Join the shard group before holding any leases
+		err = c.nodeShard.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to start node lease shard controller: %w", err)
+		}
+	}
+
 	// Not holding the lease means the node is not managed
 	c.readOnlyFunc = func(nodeName string) bool {
 		return !c.nodeLeases.Held(nodeName)
@@ -399,9 +533,28 @@ func (c *Controller) initStagesManager(ctx context.Context) error {
 		return err
 	}
 
+	stageStatus, err := NewStageStatusController(StageStatusControllerConfig{
+		Clock:           c.conf.Clock,
+		TypedKwokClient: c.conf.TypedKwokClient,
+		SyncInterval:    stageStatusSyncInterval,
+		Stages:          c.stageGetter,
+		Identity:        c.conf.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = stageStatus.Start(ctx)
+	if err != nil {
+		return err
+	}
+	c.stageStatus = stageStatus
+
 	stagesManager := NewStagesManager(StagesManagerConfig{
 		StartFunc:   c.startStageController,
 		StageGetter: c.stageGetter,
+		StageStatus: c.stageStatus,
+		Lookup:      c.objectLookup.Lookup(),
 	})
 
 	err = stagesManager.Start(ctx)
@@ -420,6 +573,32 @@ func (c *Controller) onNodeManaged(nodeName string) {
 	c.onNodeManagedFunc(nodeName)
 }
 
+// readOnly returns true if the node is not writable by this replica,
+// such as the lease of the node is not held or the node is partitioned from the apiserver.
+func (c *Controller) readOnly(nodeName string) bool {
+	if c.partitions != nil && c.partitions.Partitioned(nodeName) {
+		return true
+	}
+	return c.readOnlyFunc != nil && c.readOnlyFunc(nodeName)
+}
+
+// idle returns true if the periodic syncs of the node are skipped,
+// such as the node is read-only or the controller is paused.
+func (c *Controller) idle(nodeName string) bool {
+	return c.Paused() || c.readOnly(nodeName)
+}
+
+// onNodeHealed manages the node and its pods again after the partition is healed,
+// the stages of them are skipped while the node is partitioned.
+func (c *Controller) onNodeHealed(nodeName string) {
+	if c.nodes != nil {
+		if node, ok := c.nodeCacheGetter.Get(nodeName); ok {
+			c.nodes.ManageNode(node)
+		}
+	}
+	c.podOnNodeManageQueue.Add(nodeName)
+}
+
 func (c *Controller) onNodeUnmanaged(nodeName string) {
 	if c.onNodeUnmanagedFunc == nil {
 		return
@@ -428,8 +607,19 @@ func (c *Controller) onNodeUnmanaged(nodeName string) {
 }
 
 func (c *Controller) initNodeController(ctx context.Context, lifecycle resources.Getter[lifecycle.Lifecycle]) (err error) {
+	var nodeConditionsFunc func(nodeName string) []corev1.NodeCondition
+	if c.nodePressure != nil {
+		nodeConditionsFunc = c.nodePressure.NodeConditions
+	}
+
+	funcMap := c.conf.FuncMap
+	if c.nodeStatus != nil {
+		funcMap = utilsmaps.Merge(c.nodeStatus.FuncMap(), funcMap)
+	}
+
 	c.nodes, err = NewNodeController(NodeControllerConfig{
 		Clock:                                 c.conf.Clock,
+		QueueClock:                            c.queueClock,
 		DynamicClient:                         c.conf.DynamicClient,
 		RESTMapper:                            c.conf.RESTMapper,
 		TypedClient:                           c.conf.TypedClient,
@@ -439,13 +629,17 @@ func (c *Controller) initNodeController(ctx context.Context, lifecycle resources
 		NodePort:                              c.conf.NodePort,
 		DisregardStatusWithAnnotationSelector: c.conf.DisregardStatusWithAnnotationSelector,
 		DisregardStatusWithLabelSelector:      c.conf.DisregardStatusWithLabelSelector,
+		NodeCacheGetter:                       c.nodeCacheGetter,
 		OnNodeManagedFunc:                     c.onNodeManaged,
 		OnNodeUnmanagedFunc:                   c.onNodeUnmanaged,
 		Lifecycle:                             lifecycle,
 		PlayStageParallelism:                  c.conf.NodePlayStageParallelism,
-		FuncMap:                               c.conf.FuncMap,
+		FuncMap:                               funcMap,
 		Recorder:                              c.recorder,
-		ReadOnlyFunc:                          c.readOnlyFunc,
+		StageStatus:                           c.stageStatus,
+		PlayingJobs:                           c.playingJobs,
+		ReadOnlyFunc:                          c.readOnly,
+		NodeConditionsFunc:                    nodeConditionsFunc,
 		EnableMetrics:                         c.conf.EnableMetrics,
 	})
 	if err != nil {
@@ -461,13 +655,16 @@ func (c *Controller) initNodeController(ctx context.Context, lifecycle resources
 func (c *Controller) initPodController(ctx context.Context, lifecycle resources.Getter[lifecycle.Lifecycle]) (err error) {
 	c.pods, err = NewPodController(PodControllerConfig{
 		Clock:                                 c.conf.Clock,
+		QueueClock:                            c.queueClock,
 		DynamicClient:                         c.conf.DynamicClient,
 		RESTMapper:                            c.conf.RESTMapper,
 		TypedClient:                           c.conf.TypedClient,
 		ImpersonatingTypedClient:              c.conf.ImpersonatingTypedClient,
 		NodeCacheGetter:                       c.nodeCacheGetter,
+		PodCacheGetter:                        c.podCacheGetter,
 		NodeIP:                                c.conf.NodeIP,
 		CIDR:                                  c.conf.CIDR,
+		ManagePodsWithFieldSelector:           c.managePodsWithFieldSelector,
 		DisregardStatusWithAnnotationSelector: c.conf.DisregardStatusWithAnnotationSelector,
 		DisregardStatusWithLabelSelector:      c.conf.DisregardStatusWithLabelSelector,
 		Lifecycle:                             lifecycle,
@@ -481,7 +678,9 @@ func (c *Controller) initPodController(ctx context.Context, lifecycle resources.
 		},
 		FuncMap:       c.conf.FuncMap,
 		Recorder:      c.recorder,
-		ReadOnlyFunc:  c.readOnlyFunc,
+		StageStatus:   c.stageStatus,
+		PlayingJobs:   c.playingJobs,
+		ReadOnlyFunc:  c.readOnly,
 		EnableMetrics: c.conf.EnableMetrics,
 	})
 	if err != nil {
@@ -519,13 +718,14 @@ func (c *Controller) initStageController(ctx context.Context, ref internalversio
 	)
 	stageInformer := informer.NewInformer[*unstructured.Unstructured, *unstructured.UnstructuredList](c.conf.DynamicClient.Resource(gvr))
 	stageChan := make(chan informer.Event[*unstructured.Unstructured], 1)
-	err = stageInformer.Watch(ctx, informer.Option{}, stageChan)
+	stageCacheGetter, err := stageInformer.WatchWithCache(ctx, informer.Option{}, stageChan)
 	if err != nil {
 		return fmt.Errorf("failed to watch stages: %w", err)
 	}
 
 	stage, err := NewStageController(StageControllerConfig{
 		Clock:                                 c.conf.Clock,
+		QueueClock:                            c.queueClock,
 		DynamicClient:                         c.conf.DynamicClient,
 		ImpersonatingDynamicClient:            c.conf.ImpersonatingDynamicClient,
 		RESTMapper:                            c.conf.RESTMapper,
@@ -534,9 +734,12 @@ func (c *Controller) initStageController(ctx context.Context, ref internalversio
 		DisregardStatusWithAnnotationSelector: c.conf.DisregardStatusWithAnnotationSelector,
 		DisregardStatusWithLabelSelector:      c.conf.DisregardStatusWithLabelSelector,
 		Lifecycle:                             lifecycle,
+		CacheGetter:                           stageCacheGetter,
 		PlayStageParallelism:                  1,
 		FuncMap:                               c.conf.FuncMap,
 		Recorder:                              c.recorder,
+		StageStatus:                           c.stageStatus,
+		PlayingJobs:                           c.playingJobs,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create stage controller: %w", err)
@@ -547,6 +750,10 @@ func (c *Controller) initStageController(ctx context.Context, ref internalversio
 		return fmt.Errorf("failed to start stage controller: %w", err)
 	}
 
+	c.stages.Store(ref, stage)
+	context.AfterFunc(ctx, func() {
+		c.stages.CompareAndDelete(ref, stage)
+	})
 	return nil
 }
 
@@ -559,7 +766,7 @@ func (c *Controller) Start(ctx context.Context) error {
 
 	if len(c.conf.LocalStages) != 0 {
 		for ref, stage := range c.conf.LocalStages {
-			lifecycle, err := lifecycle.NewLifecycle(stage)
+			lifecycle, err := lifecycle.NewLifecycleWithLookup(stage, c.objectLookup.Lookup())
 			if err != nil {
 				return err
 			}
@@ -574,6 +781,98 @@ func (c *Controller) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to init stages manager: %w", err)
 		}
 	}
+
+	if c.conf.EnableProbes {
+		err = c.initProbeController(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to init probe controller: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) initProbeController(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	var probes resources.Getter[[]*internalversion.Probe] = resources.NewStaticGetter(c.conf.Probes)
+	if slices.Contains(c.conf.EnableCRDs, v1alpha1.ProbeKind) {
+		getter := resources.NewDynamicGetter[
+			[]*internalversion.Probe,
+			*v1alpha1.Probe,
+			*v1alpha1.ProbeList,
+		](
+			c.conf.TypedKwokClient.KwokV1alpha1().Probes(""),
+			func(objs []*v1alpha1.Probe) []*internalversion.Probe {
+				return utilsslices.FilterAndMap(objs, func(obj *v1alpha1.Probe) (*internalversion.Probe, bool) {
+					r, err := internalversion.ConvertToInternalProbe(obj)
+					if err != nil {
+						logger.Error("failed to convert to internal probe",
+							"err", err,
+							"obj", obj,
+						)
+						return nil, false
+					}
+					return r, true
+				})
+			},
+		)
+		err := getter.Start(ctx)
+		if err != nil {
+			return err
+		}
+		probes = getter
+	}
+
+	var clusterProbes resources.Getter[[]*internalversion.ClusterProbe] = resources.NewStaticGetter(c.conf.ClusterProbes)
+	if slices.Contains(c.conf.EnableCRDs, v1alpha1.ClusterProbeKind) {
+		getter := resources.NewDynamicGetter[
+			[]*internalversion.ClusterProbe,
+			*v1alpha1.ClusterProbe,
+			*v1alpha1.ClusterProbeList,
+		](
+			c.conf.TypedKwokClient.KwokV1alpha1().ClusterProbes(),
+			func(objs []*v1alpha1.ClusterProbe) []*internalversion.ClusterProbe {
+				return utilsslices.FilterAndMap(objs, func(obj *v1alpha1.ClusterProbe) (*internalversion.ClusterProbe, bool) {
+					r, err := internalversion.ConvertToInternalClusterProbe(obj)
+					if err != nil {
+						logger.Error("failed to convert to internal cluster probe",
+							"err", err,
+							"obj", obj,
+						)
+						return nil, false
+					}
+					return r, true
+				})
+			},
+		)
+		err := getter.Start(ctx)
+		if err != nil {
+			return err
+		}
+		clusterProbes = getter
+	}
+
+	probeController, err := NewProbeController(ProbeControllerConfig{
+		Clock:          c.conf.Clock,
+		TypedClient:    c.conf.TypedClient,
+		PodCacheGetter: c.podCacheGetter,
+		ListNodes:      c.ListNodes,
+		ListPods:       c.ListPods,
+		ReadOnlyFunc:   c.idle,
+		Recorder:       c.recorder,
+		SyncInterval:   probeSyncInterval,
+		Probes:         probes,
+		ClusterProbes:  clusterProbes,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = probeController.Start(ctx)
+	if err != nil {
+		return err
+	}
+	c.probes = probeController
 	return nil
 }
 
@@ -601,6 +900,98 @@ func (c *Controller) podsOnNodeSyncWorker(ctx context.Context) {
 	}
 }
 
+// StartNodePressureEviction starts the simulation of node-pressure eviction,
+// the podResourceUsage returns the simulated usage of the resource by the pod.
+func (c *Controller) StartNodePressureEviction(ctx context.Context, podResourceUsage func(resourceName, podNamespace, podName string) float64) error {
+	if c.nodePressure == nil {
+		return fmt.Errorf("node pressure eviction is not enabled")
+	}
+	return c.nodePressure.Start(ctx, podResourceUsage)
+}
+
+// PartitionNodes partitions the nodes from the apiserver for the duration,
+// the nodes are partitioned until they are healed if the duration is zero.
+func (c *Controller) PartitionNodes(nodeNames []string, duration time.Duration) {
+	c.partitions.Partition(nodeNames, duration)
+}
+
+// HealNode heals the partition of the node, it returns false if the node is not partitioned.
+func (c *Controller) HealNode(nodeName string) bool {
+	return c.partitions.Heal(nodeName)
+}
+
+// ListNodePartitions returns the partitioned nodes and the expiration time of their partitions.
+func (c *Controller) ListNodePartitions() map[string]time.Time {
+	return c.partitions.List()
+}
+
+// PendingJobs returns the stage jobs waiting to be played, sorted by the scheduled time.
+func (c *Controller) PendingJobs() []StageJob {
+	jobs := []StageJob{}
+	if c.nodes != nil {
+		jobs = append(jobs, c.nodes.PendingJobs()...)
+	}
+	if c.pods != nil {
+		jobs = append(jobs, c.pods.PendingJobs()...)
+	}
+	c.stages.Range(func(_ internalversion.StageResourceRef, stage *StageController) bool {
+		jobs = append(jobs, stage.PendingJobs()...)
+		return true
+	})
+	for i := range jobs {
+		jobs[i].ScheduledTime = c.queueClock.WallTime(jobs[i].ScheduledTime)
+	}
+	slices.SortFunc(jobs, func(a, b StageJob) int {
+		return a.ScheduledTime.Compare(b.ScheduledTime)
+	})
+	return jobs
+}
+
+// ListStages returns the stages in use, which are the local stages or the ones from the apiserver.
+func (c *Controller) ListStages() []*internalversion.Stage {
+	if c.stageGetter == nil {
+		stages := []*internalversion.Stage{}
+		for _, s := range c.conf.LocalStages {
+			stages = append(stages, s...)
+		}
+		return stages
+	}
+	return c.stageGetter.Get()
+}
+
+// Pause freezes the simulation, the stage jobs are not played,
+// the delays of them and the renewal of the node leases are frozen until resumed.
+func (c *Controller) Pause() {
+	c.playingJobs.Pause()
+	c.queueClock.Freeze()
+}
+
+// Resume resumes the simulation,
+// if shiftDelays is true, the delays are shifted by the paused duration and continue from where they were paused,
+// otherwise the jobs keep their scheduled time and the ones that are due while paused are played immediately.
+func (c *Controller) Resume(shiftDelays bool) {
+	c.queueClock.Resume(shiftDelays)
+	c.playingJobs.Resume()
+}
+
+// Paused returns true if the playing of the stage jobs is paused.
+func (c *Controller) Paused() bool {
+	return c.playingJobs.Paused()
+}
+
+// Drain waits for the stage jobs being played to finish after the context of Start is canceled,
+// the jobs still being played are aborted when the ctx is done,
+// and no more jobs are played after it returns.
+func (c *Controller) Drain(ctx context.Context) {
+	c.playingJobs.Drain(ctx)
+}
+
+// Abort aborts the stage jobs being played after the context of Start is canceled,
+// and no more jobs are played after it returns.
+func (c *Controller) Abort() {
+	c.playingJobs.Abort()
+}
+
 // ListNodes returns all nodes
 func (c *Controller) ListNodes() []string {
 	if c.nodes == nil {
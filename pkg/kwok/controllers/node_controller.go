@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sync/atomic"
@@ -38,6 +39,7 @@ import (
 	"sigs.k8s.io/kwok/pkg/config/resources"
 	"sigs.k8s.io/kwok/pkg/log"
 	"sigs.k8s.io/kwok/pkg/utils/client"
+	"sigs.k8s.io/kwok/pkg/utils/expression"
 	"sigs.k8s.io/kwok/pkg/utils/gotpl"
 	"sigs.k8s.io/kwok/pkg/utils/informer"
 	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
@@ -49,6 +51,7 @@ import (
 // NodeController is a fake nodes implementation that can be used to test
 type NodeController struct {
 	clock                                 clock.Clock
+	queueClock                            queue.Clock
 	dynamicClient                         dynamic.Interface
 	restMapper                            meta.RESTMapper
 	typedClient                           kubernetes.Interface
@@ -65,17 +68,23 @@ type NodeController struct {
 	preprocessChan                        chan *corev1.Node
 	playStageParallelism                  uint
 	lifecycle                             resources.Getter[lifecycle.Lifecycle]
+	nodeCacheGetter                       informer.Getter[*corev1.Node]
+	limiters                              *lifecycle.Limiters
 	delayQueue                            queue.WeightDelayingQueue[resourceStageJob[*corev1.Node]]
 	delayQueueMapping                     utilsmaps.SyncMap[string, resourceStageJob[*corev1.Node]]
 	backoff                               wait.Backoff
 	recorder                              record.EventRecorder
+	stageStatus                           *StageStatusController
+	playingJobs                           *JobGroup
 	readOnlyFunc                          func(nodeName string) bool
+	nodeConditionsFunc                    func(nodeName string) []corev1.NodeCondition
 	enableMetrics                         bool
 }
 
 // NodeControllerConfig is the configuration for the NodeController
 type NodeControllerConfig struct {
 	Clock                                 clock.Clock
+	QueueClock                            queue.Clock
 	DynamicClient                         dynamic.Interface
 	RESTMapper                            meta.RESTMapper
 	TypedClient                           kubernetes.Interface
@@ -88,10 +97,14 @@ type NodeControllerConfig struct {
 	NodeName                              string
 	NodePort                              int
 	Lifecycle                             resources.Getter[lifecycle.Lifecycle]
+	NodeCacheGetter                       informer.Getter[*corev1.Node]
 	PlayStageParallelism                  uint
 	FuncMap                               gotpl.FuncMap
 	Recorder                              record.EventRecorder
+	StageStatus                           *StageStatusController
+	PlayingJobs                           *JobGroup
 	ReadOnlyFunc                          func(nodeName string) bool
+	NodeConditionsFunc                    func(nodeName string) []corev1.NodeCondition
 	EnableMetrics                         bool
 }
 
@@ -119,9 +132,13 @@ func NewNodeController(conf NodeControllerConfig) (*NodeController, error) {
 	if conf.Clock == nil {
 		conf.Clock = clock.RealClock{}
 	}
+	if conf.QueueClock == nil {
+		conf.QueueClock = conf.Clock
+	}
 
 	c := &NodeController{
 		clock:                                 conf.Clock,
+		queueClock:                            conf.QueueClock,
 		dynamicClient:                         conf.DynamicClient,
 		restMapper:                            conf.RESTMapper,
 		typedClient:                           conf.TypedClient,
@@ -133,21 +150,30 @@ func NewNodeController(conf NodeControllerConfig) (*NodeController, error) {
 		nodeIP:                                conf.NodeIP,
 		nodeName:                              conf.NodeName,
 		nodePort:                              conf.NodePort,
-		delayQueue:                            queue.NewWeightDelayingQueue[resourceStageJob[*corev1.Node]](conf.Clock),
+		limiters:                              lifecycle.NewLimiters(),
+		delayQueue:                            queue.NewWeightDelayingQueue[resourceStageJob[*corev1.Node]](conf.QueueClock),
 		backoff:                               defaultBackoff(),
 		lifecycle:                             conf.Lifecycle,
+		nodeCacheGetter:                       conf.NodeCacheGetter,
 		playStageParallelism:                  conf.PlayStageParallelism,
 		preprocessChan:                        make(chan *corev1.Node),
 		recorder:                              conf.Recorder,
+		stageStatus:                           conf.StageStatus,
+		playingJobs:                           conf.PlayingJobs,
 		readOnlyFunc:                          conf.ReadOnlyFunc,
+		nodeConditionsFunc:                    conf.NodeConditionsFunc,
 		enableMetrics:                         conf.EnableMetrics,
 	}
 
-	funcMap := utilsmaps.Merge(gotpl.FuncMap{
+	funcMap := gotpl.FuncMap{
 		"NodeIP":   c.funcNodeIP,
 		"NodeName": c.funcNodeName,
 		"NodePort": c.funcNodePort,
-	}, conf.FuncMap)
+	}
+	if c.nodeConditionsFunc != nil {
+		funcMap["NodeConditionsWith"] = c.funcNodeConditionsWith
+	}
+	funcMap = utilsmaps.Merge(funcMap, conf.FuncMap)
 	c.renderer = gotpl.NewRenderer(funcMap)
 	return c, nil
 }
@@ -221,7 +247,9 @@ loop:
 					key := node.Name
 					resourceJob, ok := c.delayQueueMapping.LoadAndDelete(key)
 					if ok {
-						c.delayQueue.Cancel(resourceJob)
+						if c.delayQueue.Cancel(resourceJob) {
+							observeStageDequeued(resourceJob.Stage.Name(), "Node")
+						}
 					}
 				}
 
@@ -293,13 +321,16 @@ func (c *NodeController) preprocess(ctx context.Context, node *corev1.Node) erro
 	}
 
 	lc := c.lifecycle.Get()
+	c.limiters.Prune(c.lifecycle.Version(), lc)
 
 	event := &lifecycle.Event{
 		Labels:      node.Labels,
 		Annotations: node.Annotations,
 		Data:        node,
 	}
+	start := c.clock.Now()
 	stage, err := lc.Match(ctx, event)
+	observeStageMatch(stage, "Node", c.clock.Since(start))
 	if err != nil {
 		return fmt.Errorf("stage match: %w", err)
 	}
@@ -310,6 +341,8 @@ func (c *NodeController) preprocess(ctx context.Context, node *corev1.Node) erro
 		return nil
 	}
 
+	c.stageStatus.Matched(stage.Name())
+
 	now := c.clock.Now()
 	delay, _, err := stage.Delay(ctx, event, now)
 	if err != nil {
@@ -333,6 +366,9 @@ func (c *NodeController) preprocess(ctx context.Context, node *corev1.Node) erro
 		RetryCount: new(uint64),
 		StepIndex:  new(uint64),
 	}
+	if ok {
+		item = continueStageJob(resourceJob, item)
+	}
 	// we add a normal(fresh) stage job with weight 0,
 	// resulting in that it will always be processed with high priority compared to those retry ones
 	c.addStageJob(ctx, item, delay, 0)
@@ -344,12 +380,67 @@ func (c *NodeController) playStageWorker(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
 	for ctx.Err() == nil {
+		if !c.playingJobs.WaitResumed(ctx.Done()) {
+			return
+		}
 		node, ok := c.delayQueue.GetOrWaitWithDone(ctx.Done())
 		if !ok {
 			return
 		}
+		if c.playingJobs.Paused() {
+			// The playing is paused while waiting for the job, put it back to the queue.
+			c.delayQueue.AddWeightAfter(node, node.Weight, 0)
+			continue
+		}
+		observeStageDequeued(node.Stage.Name(), "Node")
+
+		if c.readOnly(node.Resource.Name) {
+			// The node is not writable now, such as partitioned from the apiserver,
+			// the job is dropped and played again when the node is managed again.
+			c.delayQueueMapping.Delete(node.Key)
+			continue
+		}
+
+		now := c.clock.Now()
+		requeue := func(delay time.Duration) bool {
+			if !requeueStageJob(&c.delayQueueMapping, c.delayQueue, node, c.queueClock.Now(), delay) {
+				return false
+			}
+			observeStageEnqueued(node.Stage.Name(), "Node")
+			return true
+		}
+		if delay, ok := c.limiters.Acquire(node.Stage, now, func() bool { return requeue(0) }); !ok {
+			// The stage is limited by its rate limit, put it back to the queue
+			// instead of blocking the worker so that the other stages can be played,
+			// or when the stage is released if it is limited by the max in-flight.
+			if delay > 0 {
+				requeue(delay)
+			}
+			continue
+		}
+
+		if !c.playingJobs.Add() {
+			// The controller is being drained, the job is left to the next leader.
+			c.limiters.Release(node.Stage)
+			return
+		}
 		c.delayQueueMapping.Delete(node.Key)
-		remainIndex, err := c.playStage(ctx, node.Resource, node.Stage, int(*node.StepIndex))
+		node = refreshWaitJob(node, c.getFromCache)
+		// The stage is played to the end even if the context is canceled,
+		// so that the resource is not left half-played for the next leader,
+		// unless the playing is aborted as the resource may be taken over already.
+		playCtx, playCancel := c.playingJobs.Context(ctx)
+		remainIndex, err := c.playStage(playCtx, node.Resource, node.Stage, int(*node.StepIndex))
+		playCancel()
+		c.limiters.Release(node.Stage)
+		c.playingJobs.Done()
+		var waitErr *lifecycle.WaitError
+		if errors.As(err, &waitErr) {
+			c.waitStageJob(ctx, node, waitErr, remainIndex, now)
+			continue
+		}
+		observeStagePlay(node.Stage.Name(), "Node", c.clock.Since(now), err, remainIndex >= 0)
+		c.stageStatus.Played(node.Stage.Name(), err)
 		if err != nil {
 			logger.Error("failed to apply stage",
 				"err", err,
@@ -368,12 +459,53 @@ func (c *NodeController) playStageWorker(ctx context.Context) {
 			)
 			// for failed jobs, we re-push them into the queue with a lower weight
 			// and a backoff period to avoid blocking normal tasks
-			retryDelay := backoffDelayByStep(retryCount, c.backoff)
+			retryDelay := backoffDelayByStep(node.Key+"/"+node.Stage.Name(), retryCount, c.backoff)
 			c.addStageJob(ctx, node, retryDelay, 1)
 		}
 	}
 }
 
+// waitStageJob puts the job back to the queue to wait for the condition of the wait step,
+// or plays the timeout stage if the wait times out.
+func (c *NodeController) waitStageJob(ctx context.Context, job resourceStageJob[*corev1.Node], waitErr *lifecycle.WaitError, stepIndex int, now time.Time) {
+	changed := false
+	if queued, ok := c.delayQueueMapping.Load(job.Key); ok {
+		// The resource has been changed and matched again while playing the stage.
+		if queued.Stage.Name() != job.Stage.Name() {
+			return
+		}
+		// Continue waiting on the step with the latest resource.
+		job.Resource = queued.Resource
+		changed = true
+	}
+
+	// The wait is timed on the queue clock, which is frozen while the simulation is paused.
+	next, delay, err := nextWaitJob(job, waitErr, stepIndex, c.lifecycle.Get(), c.queueClock.Now())
+	if err != nil {
+		observeStagePlay(job.Stage.Name(), "Node", c.clock.Since(now), err, false)
+		c.stageStatus.Played(job.Stage.Name(), err)
+		logger := log.FromContext(ctx)
+		logger.Error("failed to wait for stage",
+			"err", err,
+			"node", job.Key,
+			"stage", job.Stage.Name(),
+		)
+		return
+	}
+	if changed {
+		delay = 0
+	}
+	c.addStageJob(ctx, next, delay, 0)
+}
+
+// getFromCache returns the latest node from the informer cache.
+func (c *NodeController) getFromCache(node *corev1.Node) (*corev1.Node, bool) {
+	if c.nodeCacheGetter == nil {
+		return nil, false
+	}
+	return c.nodeCacheGetter.Get(node.Name)
+}
+
 // playStage plays the stage.
 // The returned boolean indicates whether the applying action needs to be retried.
 func (c *NodeController) playStage(ctx context.Context, node *corev1.Node, stage *lifecycle.Stage, stepIndex int) (int, error) {
@@ -434,7 +566,8 @@ func (c *NodeController) playStage(ctx context.Context, node *corev1.Node, stage
 		},
 	)
 	if err != nil {
-		if shouldRetry(err) {
+		var waitErr *lifecycle.WaitError
+		if shouldRetry(err) || errors.As(err, &waitErr) {
 			return remainIndex, err
 		}
 		return -1, err
@@ -597,8 +730,21 @@ func (c *NodeController) funcNodePort() int {
 	return c.nodePort
 }
 
+func (c *NodeController) funcNodeConditionsWith(nodeName string) (any, error) {
+	return expression.ToJSONStandard(c.nodeConditionsFunc(nodeName))
+}
+
+// PendingJobs returns the stage jobs of the nodes waiting to be played.
+func (c *NodeController) PendingJobs() []StageJob {
+	return pendingJobs(&c.delayQueueMapping, func(*corev1.Node) string {
+		return "Node"
+	})
+}
+
 // addStageJob adds a stage to be applied into the underlying weight delay queue and the associated helper map
 func (c *NodeController) addStageJob(ctx context.Context, job resourceStageJob[*corev1.Node], delay time.Duration, weight int) {
+	job.ScheduledTime = c.queueClock.Now().Add(delay)
+	job.Weight = weight
 	old, loaded := c.delayQueueMapping.Swap(job.Key, job)
 	if loaded {
 		if !c.delayQueue.Cancel(old) {
@@ -606,7 +752,10 @@ func (c *NodeController) addStageJob(ctx context.Context, job resourceStageJob[*
 			logger.Debug("Failed to cancel stage",
 				"stage", job.Stage.Name(),
 			)
+		} else {
+			observeStageDequeued(old.Stage.Name(), "Node")
 		}
 	}
 	c.delayQueue.AddWeightAfter(job, weight, delay)
+	observeStageEnqueued(job.Stage.Name(), "Node")
 }
@@ -54,11 +54,21 @@ type NodeLeaseController struct {
 
 	holderIdentity    string
 	onNodeManagedFunc func(nodeName string)
+
+	// isOwnerFunc returns true if the lease of the node should be held by this replica,
+	// it distributes the nodes among the replicas of kwok.
+	isOwnerFunc    func(nodeName string) bool
+	manageNodesSet utilsmaps.SyncMap[string, struct{}]
+
+	// partitionedFunc returns true if the node is partitioned from the apiserver,
+	// the lease of the node is not renewed until the partition is healed.
+	partitionedFunc func(nodeName string) bool
 }
 
 // NodeLeaseControllerConfig is the configuration for NodeLeaseController
 type NodeLeaseControllerConfig struct {
 	Clock                clock.Clock
+	QueueClock           queue.Clock
 	HolderIdentity       string
 	TypedClient          clientset.Interface
 	LeaseDurationSeconds uint
@@ -68,6 +78,8 @@ type NodeLeaseControllerConfig struct {
 	RenewIntervalJitter  float64
 	MutateLeaseFunc      func(*coordinationv1.Lease) error
 	OnNodeManagedFunc    func(nodeName string)
+	IsOwnerFunc          func(nodeName string) bool
+	PartitionedFunc      func(nodeName string) bool
 }
 
 // NewNodeLeaseController constructs and returns a NodeLeaseController
@@ -79,6 +91,9 @@ func NewNodeLeaseController(conf NodeLeaseControllerConfig) (*NodeLeaseControlle
 	if conf.Clock == nil {
 		conf.Clock = clock.RealClock{}
 	}
+	if conf.QueueClock == nil {
+		conf.QueueClock = conf.Clock
+	}
 
 	c := &NodeLeaseController{
 		clock:                conf.Clock,
@@ -89,9 +104,11 @@ func NewNodeLeaseController(conf NodeLeaseControllerConfig) (*NodeLeaseControlle
 		renewInterval:        conf.RenewInterval,
 		renewIntervalJitter:  conf.RenewIntervalJitter,
 		mutateLeaseFunc:      conf.MutateLeaseFunc,
-		delayQueue:           queue.NewWeightDelayingQueue[string](conf.Clock),
+		delayQueue:           queue.NewWeightDelayingQueue[string](conf.QueueClock),
 		holderIdentity:       conf.HolderIdentity,
 		onNodeManagedFunc:    conf.OnNodeManagedFunc,
+		isOwnerFunc:          conf.IsOwnerFunc,
+		partitionedFunc:      conf.PartitionedFunc,
 	}
 
 	return c, nil
@@ -119,6 +136,15 @@ func (c *NodeLeaseController) syncWorker(ctx context.Context) {
 
 		dur := c.interval()
 
+		if c.partitionedFunc != nil && c.partitionedFunc(nodeName) {
+			logger.Debug("Skip lease",
+				"reason", "partitioned",
+				"node", nodeName,
+			)
+			c.delayQueue.AddWeightAfter(nodeName, 1, dur)
+			continue
+		}
+
 		lease, err := c.sync(ctx, nodeName, first)
 		if err != nil {
 			logger.Error("Failed to sync lease",
@@ -153,6 +179,13 @@ func (c *NodeLeaseController) interval() time.Duration {
 
 // TryHold tries to hold a lease for the NodeLeaseController
 func (c *NodeLeaseController) TryHold(name string) {
+	if c.isOwnerFunc != nil {
+		c.manageNodesSet.Store(name, struct{}{})
+		if !c.isOwnerFunc(name) {
+			return
+		}
+	}
+
 	_, loaded := c.holdLeaseSet.LoadOrStore(name, true)
 	if !loaded {
 		c.delayQueue.Add(name)
@@ -161,10 +194,65 @@ func (c *NodeLeaseController) TryHold(name string) {
 
 // ReleaseHold releases a lease for the NodeLeaseController
 func (c *NodeLeaseController) ReleaseHold(name string) {
+	c.manageNodesSet.Delete(name)
 	_ = c.delayQueue.Cancel(name)
 	c.holdLeaseSet.Delete(name)
 }
 
+// Rebalance tries to hold the leases of the nodes that this replica becomes the owner of,
+// and gives up the leases of the nodes that are owned by other replicas now,
+// so that they can be taken over without waiting for the leases to expire.
+func (c *NodeLeaseController) Rebalance(ctx context.Context) {
+	if c.isOwnerFunc == nil {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	c.manageNodesSet.Range(func(nodeName string, _ struct{}) bool {
+		if c.isOwnerFunc(nodeName) {
+			_, loaded := c.holdLeaseSet.LoadOrStore(nodeName, true)
+			if !loaded {
+				c.delayQueue.Add(nodeName)
+			}
+			return true
+		}
+
+		if _, ok := c.holdLeaseSet.LoadAndDelete(nodeName); !ok {
+			return true
+		}
+		_ = c.delayQueue.Cancel(nodeName)
+		err := c.giveUpLease(ctx, nodeName)
+		if err != nil {
+			logger.Error("Failed to give up lease",
+				"err", err,
+				"node", nodeName,
+			)
+		}
+		return true
+	})
+}
+
+// giveUpLease clears the holder of the lease if it is held by this replica
+func (c *NodeLeaseController) giveUpLease(ctx context.Context, nodeName string) error {
+	lease, ok := c.getLease(nodeName)
+	if !ok || lease == nil || format.ElemOrDefault(lease.Spec.HolderIdentity) != c.holderIdentity {
+		return nil
+	}
+
+	lease = lease.DeepCopy()
+	lease.Spec.HolderIdentity = nil
+	_, err := c.typedClient.CoordinationV1().Leases(lease.Namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("Gave up lease",
+		"node", nodeName,
+	)
+	return nil
+}
+
 // Held returns true if the NodeLeaseController holds the lease
 func (c *NodeLeaseController) Held(name string) bool {
 	lease, ok := c.getLease(name)
@@ -0,0 +1,307 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/format"
+)
+
+const (
+	// nodeLeaseShardGroupLabel is the label of the member leases with the name of the shard group.
+	nodeLeaseShardGroupLabel = "kwok.x-k8s.io/node-lease-shard-group"
+	// nodeLeaseShardNamespace is the namespace of the member leases.
+	nodeLeaseShardNamespace = metav1.NamespaceSystem
+)
+
+// NodeLeaseShardController keeps the member lease of this replica of kwok in the shard group,
+// and distributes the nodes among the live members of the group by rendezvous hashing,
+// so that each node is owned by only one member, and the nodes of a dead member are taken over by the others.
+type NodeLeaseShardController struct {
+	clock                clock.Clock
+	typedClient          clientset.Interface
+	group                string
+	holderIdentity       string
+	leaseName            string
+	leaseDurationSeconds uint
+	renewInterval        time.Duration
+
+	members atomic.Pointer[[]string]
+
+	onMembersChangedFunc func(members []string)
+}
+
+// NodeLeaseShardControllerConfig is the configuration for NodeLeaseShardController
+type NodeLeaseShardControllerConfig struct {
+	Clock                clock.Clock
+	TypedClient          clientset.Interface
+	Group                string
+	HolderIdentity       string
+	LeaseDurationSeconds uint
+	RenewInterval        time.Duration
+	OnMembersChangedFunc func(members []string)
+}
+
+// NewNodeLeaseShardController constructs and returns a NodeLeaseShardController
+func NewNodeLeaseShardController(conf NodeLeaseShardControllerConfig) (*NodeLeaseShardController, error) {
+	if conf.Group == "" {
+		return nil, fmt.Errorf("node lease shard group is required")
+	}
+	if conf.LeaseDurationSeconds == 0 {
+		return nil, fmt.Errorf("node lease shard requires node lease duration")
+	}
+
+	if conf.Clock == nil {
+		conf.Clock = clock.RealClock{}
+	}
+
+	c := &NodeLeaseShardController{
+		clock:                conf.Clock,
+		typedClient:          conf.TypedClient,
+		group:                conf.Group,
+		holderIdentity:       conf.HolderIdentity,
+		leaseName:            memberLeaseName(conf.Group, conf.HolderIdentity),
+		leaseDurationSeconds: conf.LeaseDurationSeconds,
+		renewInterval:        conf.RenewInterval,
+		onMembersChangedFunc: conf.OnMembersChangedFunc,
+	}
+	c.members.Store(&[]string{conf.HolderIdentity})
+	return c, nil
+}
+
+// Start starts the NodeLeaseShardController,
+// it joins the shard group before returning, so that the nodes are not held before the members are known.
+func (c *NodeLeaseShardController) Start(ctx context.Context) error {
+	err := c.sync(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to join node lease shard group %q: %w", c.group, err)
+	}
+
+	go c.syncWorker(ctx)
+	return nil
+}
+
+func (c *NodeLeaseShardController) syncWorker(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(c.renewInterval):
+			err := c.sync(ctx)
+			if err != nil {
+				logger.Error("Failed to sync node lease shard group",
+					"err", err,
+					"group", c.group,
+				)
+			}
+		}
+	}
+}
+
+// sync renews the member lease of this replica and updates the live members of the group
+func (c *NodeLeaseShardController) sync(ctx context.Context) error {
+	err := c.renewMemberLease(ctx)
+	if err != nil {
+		return err
+	}
+
+	list, err := c.typedClient.CoordinationV1().Leases(nodeLeaseShardNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{nodeLeaseShardGroupLabel: c.group}).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list member leases: %w", err)
+	}
+
+	now := c.clock.Now()
+	members := []string{c.holderIdentity}
+	for i := range list.Items {
+		lease := &list.Items[i]
+		holder := format.ElemOrDefault(lease.Spec.HolderIdentity)
+		if holder == "" || holder == c.holderIdentity {
+			continue
+		}
+		expire, ok := expireTime(lease)
+		if !ok {
+			continue
+		}
+		if expire.Before(now) {
+			c.deleteMemberLease(ctx, lease)
+			continue
+		}
+		members = append(members, holder)
+	}
+	slices.Sort(members)
+	members = slices.Compact(members)
+
+	if slices.Equal(*c.members.Load(), members) {
+		return nil
+	}
+	c.members.Store(&members)
+
+	logger := log.FromContext(ctx)
+	logger.Info("Node lease shard group members changed",
+		"group", c.group,
+		"members", members,
+	)
+	if c.onMembersChangedFunc != nil {
+		c.onMembersChangedFunc(members)
+	}
+	return nil
+}
+
+// deleteMemberLease deletes the expired member lease of the member that left the group,
+// the lease is kept if it is renewed or deleted by the others in the meantime.
+func (c *NodeLeaseShardController) deleteMemberLease(ctx context.Context, lease *coordinationv1.Lease) {
+	err := c.typedClient.CoordinationV1().Leases(lease.Namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			UID:             &lease.UID,
+			ResourceVersion: &lease.ResourceVersion,
+		},
+	})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		logger := log.FromContext(ctx)
+		logger.Error("Failed to delete expired member lease",
+			"err", err,
+			"lease", log.KObj(lease),
+		)
+	}
+}
+
+// renewMemberLease creates or renews the member lease of this replica
+func (c *NodeLeaseShardController) renewMemberLease(ctx context.Context) error {
+	leasesCli := c.typedClient.CoordinationV1().Leases(nodeLeaseShardNamespace)
+	now := metav1.NewMicroTime(c.clock.Now())
+	lease, err := leasesCli.Get(ctx, c.leaseName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get member lease: %w", err)
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.leaseName,
+				Namespace: nodeLeaseShardNamespace,
+				Labels: map[string]string{
+					nodeLeaseShardGroupLabel: c.group,
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &c.holderIdentity,
+				LeaseDurationSeconds: new(int32(c.leaseDurationSeconds)),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		_, err = leasesCli.Create(ctx, lease, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create member lease: %w", err)
+		}
+		return nil
+	}
+
+	lease.Spec.HolderIdentity = &c.holderIdentity
+	lease.Spec.LeaseDurationSeconds = new(int32(c.leaseDurationSeconds))
+	lease.Spec.RenewTime = &now
+	_, err = leasesCli.Update(ctx, lease, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to renew member lease: %w", err)
+	}
+	return nil
+}
+
+// Members returns the live members of the group
+func (c *NodeLeaseShardController) Members() []string {
+	return *c.members.Load()
+}
+
+// IsOwner returns true if this replica is the owner of the node
+func (c *NodeLeaseShardController) IsOwner(nodeName string) bool {
+	return nodeOwner(nodeName, c.Members()) == c.holderIdentity
+}
+
+// nodeOwner returns the member with the highest score for the node,
+// only the nodes of the member that leaves or joins are moved.
+func nodeOwner(nodeName string, members []string) string {
+	var (
+		owner     string
+		bestScore uint64
+	)
+	for _, member := range members {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(member))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(nodeName))
+		score := mix64(h.Sum64())
+		if owner == "" || score > bestScore {
+			owner = member
+			bestScore = score
+		}
+	}
+	return owner
+}
+
+// mix64 scrambles the bits of the hash, so that the scores of the similar names are not correlated
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// memberLeaseName returns a valid name of the member lease of the holder,
+// it ends with the hash of the group and the holder identity,
+// so that the identities differing only in the invalid characters do not share the same lease.
+func memberLeaseName(group, holderIdentity string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(group))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(holderIdentity))
+	suffix := fmt.Sprintf("-%016x", h.Sum64())
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, group+"-"+holderIdentity)
+	name = strings.Trim(name, "-.")
+	if maxLen := 253 - len(suffix); len(name) > maxLen {
+		name = strings.Trim(name[:maxLen], "-.")
+	}
+	return name + suffix
+}
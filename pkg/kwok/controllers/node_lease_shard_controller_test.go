@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+func Test_nodeOwner(t *testing.T) {
+	members := []string{"kwok-a", "kwok-b", "kwok-c"}
+	nodes := 3000
+
+	owners := map[string]string{}
+	counts := map[string]int{}
+	for i := 0; i < nodes; i++ {
+		nodeName := fmt.Sprintf("node-%d", i)
+		owner := nodeOwner(nodeName, members)
+		owners[nodeName] = owner
+		counts[owner]++
+	}
+	for _, member := range members {
+		if counts[member] < nodes/len(members)*8/10 || counts[member] > nodes/len(members)*12/10 {
+			t.Errorf("member %s owns %d nodes, want about %d", member, counts[member], nodes/len(members))
+		}
+	}
+
+	// Only the nodes of the member that leaves are moved
+	rest := []string{"kwok-a", "kwok-c"}
+	for nodeName, owner := range owners {
+		got := nodeOwner(nodeName, rest)
+		if owner != "kwok-b" && got != owner {
+			t.Errorf("node %s is moved from %s to %s", nodeName, owner, got)
+		}
+	}
+}
+
+func Test_memberLeaseName(t *testing.T) {
+	got := memberLeaseName("kwok", "Host_Name_5d1f")
+	if want := "kwok-host-name-5d1f-"; !strings.HasPrefix(got, want) || len(got) != len(want)+16 {
+		t.Errorf("memberLeaseName() = %q, want %q with the hash suffix", got, want)
+	}
+	if other := memberLeaseName("kwok", "host.name-5d1f"); got == other {
+		t.Errorf("memberLeaseName() = %q for the different identities", got)
+	}
+	if long := memberLeaseName("kwok", strings.Repeat("a", 300)); len(long) > 253 {
+		t.Errorf("memberLeaseName() = %q, want no longer than 253", long)
+	}
+}
+
+func TestNodeLeaseShardController(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	clientset := fake.NewClientset()
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	t.Cleanup(cancel)
+
+	var changed [][]string
+	newShard := func(id string) *NodeLeaseShardController {
+		c, err := NewNodeLeaseShardController(NodeLeaseShardControllerConfig{
+			Clock:                clock,
+			TypedClient:          clientset,
+			Group:                "kwok",
+			HolderIdentity:       id,
+			LeaseDurationSeconds: 40,
+			RenewInterval:        10 * time.Second,
+			OnMembersChangedFunc: func(members []string) {
+				if id == "kwok-a" {
+					changed = append(changed, members)
+				}
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	a := newShard("kwok-a")
+	b := newShard("kwok-b")
+	if err := a.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"kwok-a", "kwok-b"}
+	if !slices.Equal(a.Members(), want) || !slices.Equal(b.Members(), want) {
+		t.Fatalf("want members %v, got %v and %v", want, a.Members(), b.Members())
+	}
+	for i := 0; i < 100; i++ {
+		nodeName := fmt.Sprintf("node-%d", i)
+		if a.IsOwner(nodeName) == b.IsOwner(nodeName) {
+			t.Errorf("want node %s to be owned by one member", nodeName)
+		}
+	}
+
+	// kwok-b dies and its member lease expires
+	clock.Step(41 * time.Second)
+	if err := a.sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"kwok-a"}; !slices.Equal(a.Members(), want) {
+		t.Fatalf("want members %v, got %v", want, a.Members())
+	}
+	for i := 0; i < 100; i++ {
+		nodeName := fmt.Sprintf("node-%d", i)
+		if !a.IsOwner(nodeName) {
+			t.Errorf("want node %s to be taken over", nodeName)
+		}
+	}
+	if len(changed) != 2 {
+		t.Errorf("want the members to be changed twice, got %v", changed)
+	}
+
+	// The expired member lease of kwok-b is deleted
+	_, err := clientset.CoordinationV1().Leases(nodeLeaseShardNamespace).Get(ctx, b.leaseName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("want the member lease of kwok-b to be deleted, got %v", err)
+	}
+	_, err = clientset.CoordinationV1().Leases(nodeLeaseShardNamespace).Get(ctx, a.leaseName, metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("want the member lease of kwok-a to be kept, got %v", err)
+	}
+}
+
+func TestNodeLeaseControllerRebalance(t *testing.T) {
+	now := time.Now()
+	clientset := fake.NewClientset(
+		&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "node0",
+				Namespace: corev1.NamespaceNodeLease,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       new("kwok-a"),
+				RenewTime:            new(metav1.NewMicroTime(now)),
+				LeaseDurationSeconds: new(int32(40)),
+			},
+		},
+	)
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+
+	owned := true
+	nodeLeases, err := NewNodeLeaseController(NodeLeaseControllerConfig{
+		TypedClient: clientset,
+		GetLease: func(nodeName string) (*coordinationv1.Lease, bool) {
+			lease, err := clientset.CoordinationV1().Leases(corev1.NamespaceNodeLease).Get(ctx, nodeName, metav1.GetOptions{})
+			if err != nil {
+				return nil, false
+			}
+			return lease, true
+		},
+		HolderIdentity:       "kwok-a",
+		LeaseDurationSeconds: 40,
+		LeaseParallelism:     1,
+		RenewInterval:        10 * time.Second,
+		IsOwnerFunc: func(nodeName string) bool {
+			return owned
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodeLeases.TryHold("node0")
+	if _, ok := nodeLeases.holdLeaseSet.Load("node0"); !ok {
+		t.Fatal("want node0 to be held")
+	}
+
+	owned = false
+	nodeLeases.Rebalance(ctx)
+	if _, ok := nodeLeases.holdLeaseSet.Load("node0"); ok {
+		t.Fatal("want node0 not to be held")
+	}
+	if nodeLeases.Held("node0") {
+		t.Error("want the lease of node0 to be given up")
+	}
+
+	owned = true
+	nodeLeases.Rebalance(ctx)
+	if _, ok := nodeLeases.holdLeaseSet.Load("node0"); !ok {
+		t.Fatal("want node0 to be held again")
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
+	"sigs.k8s.io/kwok/pkg/utils/queue"
+)
+
+// NodePartitionController simulates the network partitions between nodes and the apiserver,
+// the partitioned nodes stop renewing their leases and updating the status of themselves and their pods,
+// so that the node lifecycle controller sees them as the nodes with stale heartbeats.
+type NodePartitionController struct {
+	clock clock.Clock
+
+	// partitions is the expiration time of the partitioned nodes,
+	// the zero time means the node is partitioned until it is healed.
+	partitions utilsmaps.SyncMap[string, time.Time]
+	delayQueue queue.DelayingQueue[string]
+
+	onNodeHealedFunc func(nodeName string)
+}
+
+// NodePartitionControllerConfig is the configuration for the NodePartitionController
+type NodePartitionControllerConfig struct {
+	Clock            clock.Clock
+	OnNodeHealedFunc func(nodeName string)
+}
+
+// NewNodePartitionController creates a new NodePartitionController
+func NewNodePartitionController(conf NodePartitionControllerConfig) (*NodePartitionController, error) {
+	if conf.Clock == nil {
+		conf.Clock = clock.RealClock{}
+	}
+
+	c := &NodePartitionController{
+		clock:            conf.Clock,
+		delayQueue:       queue.NewDelayingQueue[string](conf.Clock),
+		onNodeHealedFunc: conf.OnNodeHealedFunc,
+	}
+	return c, nil
+}
+
+// Start starts the NodePartitionController,
+// it heals the partitioned nodes when their partitions expire.
+func (c *NodePartitionController) Start(ctx context.Context) error {
+	go c.expireWorker(ctx)
+	return nil
+}
+
+func (c *NodePartitionController) expireWorker(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	for ctx.Err() == nil {
+		nodeName, ok := c.delayQueue.GetOrWaitWithDone(ctx.Done())
+		if !ok {
+			return
+		}
+
+		expireTime, ok := c.partitions.Load(nodeName)
+		if !ok || expireTime.IsZero() {
+			continue
+		}
+		if now := c.clock.Now(); now.Before(expireTime) {
+			c.delayQueue.AddAfter(nodeName, expireTime.Sub(now))
+			continue
+		}
+
+		if c.heal(nodeName, expireTime) {
+			logger.Info("Node partition expired",
+				"node", nodeName,
+			)
+		}
+	}
+}
+
+// Partition partitions the nodes from the apiserver for the duration,
+// the nodes are partitioned until they are healed if the duration is zero.
+// Partitioning a partitioned node again resets its duration.
+func (c *NodePartitionController) Partition(nodeNames []string, duration time.Duration) {
+	var expireTime time.Time
+	if duration > 0 {
+		expireTime = c.clock.Now().Add(duration)
+	}
+	for _, nodeName := range nodeNames {
+		c.partitions.Store(nodeName, expireTime)
+		_ = c.delayQueue.Cancel(nodeName)
+		if duration > 0 {
+			c.delayQueue.AddAfter(nodeName, duration)
+		}
+	}
+}
+
+// Heal heals the partition of the node, it returns false if the node is not partitioned.
+func (c *NodePartitionController) Heal(nodeName string) bool {
+	expireTime, ok := c.partitions.Load(nodeName)
+	if !ok {
+		return false
+	}
+	_ = c.delayQueue.Cancel(nodeName)
+	return c.heal(nodeName, expireTime)
+}
+
+func (c *NodePartitionController) heal(nodeName string, expireTime time.Time) bool {
+	// The node may be partitioned again in the meantime
+	if !c.partitions.CompareAndDelete(nodeName, expireTime) {
+		return false
+	}
+	if c.onNodeHealedFunc != nil {
+		c.onNodeHealedFunc(nodeName)
+	}
+	return true
+}
+
+// Partitioned returns true if the node is partitioned from the apiserver.
+func (c *NodePartitionController) Partitioned(nodeName string) bool {
+	_, ok := c.partitions.Load(nodeName)
+	return ok
+}
+
+// List returns the partitioned nodes and the expiration time of their partitions.
+func (c *NodePartitionController) List() map[string]time.Time {
+	partitions := map[string]time.Time{}
+	c.partitions.Range(func(nodeName string, expireTime time.Time) bool {
+		partitions[nodeName] = expireTime
+		return true
+	})
+	return partitions
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+func TestNodePartitionController(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	t.Cleanup(cancel)
+
+	var mut sync.Mutex
+	var healed []string
+	c, err := NewNodePartitionController(NodePartitionControllerConfig{
+		Clock: clock,
+		OnNodeHealedFunc: func(nodeName string) {
+			mut.Lock()
+			defer mut.Unlock()
+			healed = append(healed, nodeName)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Partition([]string{"node0", "node1"}, time.Minute)
+	c.Partition([]string{"node2"}, 0)
+	for _, nodeName := range []string{"node0", "node1", "node2"} {
+		if !c.Partitioned(nodeName) {
+			t.Errorf("want %s to be partitioned", nodeName)
+		}
+	}
+	if c.Partitioned("node3") {
+		t.Error("want node3 not to be partitioned")
+	}
+	if list := c.List(); len(list) != 3 || !list["node2"].IsZero() {
+		t.Errorf("want 3 partitions and node2 until healed, got %v", list)
+	}
+
+	if !c.Heal("node1") {
+		t.Error("want node1 to be healed")
+	}
+	if c.Heal("node1") {
+		t.Error("want node1 not to be healed twice")
+	}
+
+	// The partition of node0 expires, and node2 is kept until it is healed
+	for ctx.Err() == nil && c.Partitioned("node0") {
+		clock.Step(time.Minute)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !c.Partitioned("node2") {
+		t.Error("want node2 to be partitioned until healed")
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+	if want := []string{"node1", "node0"}; !slices.Equal(healed, want) {
+		t.Errorf("want healed %v, got %v", want, healed)
+	}
+}
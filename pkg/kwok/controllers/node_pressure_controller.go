@@ -0,0 +1,494 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
+)
+
+// evictedExitCode is the exit code of the containers killed by the eviction,
+// which are killed with SIGKILL as the grace period is zero for the node pressure.
+const evictedExitCode = 137
+
+// nodePressureSignal is a resource that puts the node under pressure when its usage crosses the allocatable.
+type nodePressureSignal struct {
+	Resource  corev1.ResourceName
+	Condition corev1.NodeConditionType
+	Reason    string
+	Message   string
+
+	// PressureEvent and NoPressureEvent are the reasons of the events recorded by kubelet
+	// when the condition changes.
+	PressureEvent   string
+	NoPressureEvent string
+}
+
+// https://kubernetes.io/docs/concepts/scheduling-eviction/node-pressure-eviction/#node-conditions
+var nodePressureSignals = []nodePressureSignal{
+	{
+		Resource:        corev1.ResourceMemory,
+		Condition:       corev1.NodeMemoryPressure,
+		Reason:          "KubeletHasInsufficientMemory",
+		Message:         "kubelet has insufficient memory available",
+		PressureEvent:   "NodeHasInsufficientMemory",
+		NoPressureEvent: "NodeHasSufficientMemory",
+	},
+	{
+		Resource:        corev1.ResourceEphemeralStorage,
+		Condition:       corev1.NodeDiskPressure,
+		Reason:          "KubeletHasDiskPressure",
+		Message:         "kubelet has disk pressure",
+		PressureEvent:   "NodeHasDiskPressure",
+		NoPressureEvent: "NodeHasNoDiskPressure",
+	},
+}
+
+// NodePressureController simulates the node-pressure eviction of kubelet.
+// It sets the pressure conditions of nodes when the usage of the pods on them crosses the allocatable,
+// and evicts the pods one at a time until the pressure is relieved.
+type NodePressureController struct {
+	clock           clock.Clock
+	typedClient     kubernetes.Interface
+	nodeCacheGetter informer.Getter[*corev1.Node]
+	podCacheGetter  informer.Getter[*corev1.Pod]
+	listNodes       func() []string
+	listPods        func(nodeName string) ([]log.ObjectRef, bool)
+	readOnlyFunc    func(nodeName string) bool
+	recorder        record.EventRecorder
+	syncInterval    time.Duration
+
+	podResourceUsage func(resourceName, podNamespace, podName string) float64
+
+	// pressures is the conditions under pressure of each node.
+	pressures utilsmaps.SyncMap[string, []corev1.NodeConditionType]
+}
+
+// NodePressureControllerConfig is the configuration for the NodePressureController
+type NodePressureControllerConfig struct {
+	Clock           clock.Clock
+	TypedClient     kubernetes.Interface
+	NodeCacheGetter informer.Getter[*corev1.Node]
+	PodCacheGetter  informer.Getter[*corev1.Pod]
+	ListNodes       func() []string
+	ListPods        func(nodeName string) ([]log.ObjectRef, bool)
+	ReadOnlyFunc    func(nodeName string) bool
+	Recorder        record.EventRecorder
+	SyncInterval    time.Duration
+}
+
+// NewNodePressureController creates a new NodePressureController
+func NewNodePressureController(conf NodePressureControllerConfig) (*NodePressureController, error) {
+	if conf.SyncInterval <= 0 {
+		return nil, fmt.Errorf("sync interval must be greater than 0")
+	}
+
+	if conf.NodeCacheGetter == nil || conf.PodCacheGetter == nil {
+		return nil, fmt.Errorf("node and pod cache are required")
+	}
+
+	if conf.Clock == nil {
+		conf.Clock = clock.RealClock{}
+	}
+
+	c := &NodePressureController{
+		clock:           conf.Clock,
+		typedClient:     conf.TypedClient,
+		nodeCacheGetter: conf.NodeCacheGetter,
+		podCacheGetter:  conf.PodCacheGetter,
+		listNodes:       conf.ListNodes,
+		listPods:        conf.ListPods,
+		readOnlyFunc:    conf.ReadOnlyFunc,
+		recorder:        conf.Recorder,
+		syncInterval:    conf.SyncInterval,
+	}
+	return c, nil
+}
+
+// Start starts the NodePressureController,
+// the podResourceUsage returns the simulated usage of the resource by the pod.
+func (c *NodePressureController) Start(ctx context.Context, podResourceUsage func(resourceName, podNamespace, podName string) float64) error {
+	if podResourceUsage == nil {
+		return fmt.Errorf("pod resource usage is required")
+	}
+	c.podResourceUsage = podResourceUsage
+	go c.syncWorker(ctx)
+	return nil
+}
+
+// NodeConditions returns the conditions of the node with the simulated pressure.
+func (c *NodePressureController) NodeConditions(nodeName string) []corev1.NodeCondition {
+	pressures, _ := c.pressures.Load(nodeName)
+	return nodePressureConditions(pressures)
+}
+
+func (c *NodePressureController) syncWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(c.syncInterval):
+			c.syncAll(ctx)
+		}
+	}
+}
+
+func (c *NodePressureController) syncAll(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	for _, nodeName := range c.listNodes() {
+		if ctx.Err() != nil {
+			return
+		}
+		if c.readOnlyFunc != nil && c.readOnlyFunc(nodeName) {
+			continue
+		}
+
+		err := c.sync(ctx, nodeName)
+		if err != nil {
+			logger.Error("Failed to sync node pressure",
+				"err", err,
+				"node", nodeName,
+			)
+		}
+	}
+}
+
+// podUsage is the usage and the request of a resource by the pod.
+type podUsage struct {
+	Pod     *corev1.Pod
+	Usage   float64
+	Request float64
+}
+
+func (c *NodePressureController) sync(ctx context.Context, nodeName string) error {
+	node, ok := c.nodeCacheGetter.Get(nodeName)
+	if !ok {
+		return nil
+	}
+
+	pods := c.activePods(nodeName)
+
+	var pressures []corev1.NodeConditionType
+	var evictSignal *nodePressureSignal
+	var evictUsages []podUsage
+	for i, signal := range nodePressureSignals {
+		allocatable, ok := node.Status.Allocatable[signal.Resource]
+		if !ok || allocatable.IsZero() {
+			continue
+		}
+
+		usages, total := c.usages(signal.Resource, pods)
+		if total <= allocatable.AsApproximateFloat64() {
+			continue
+		}
+
+		pressures = append(pressures, signal.Condition)
+		if evictSignal == nil {
+			evictSignal = &nodePressureSignals[i]
+			evictUsages = usages
+		}
+	}
+
+	old, _ := c.pressures.Load(nodeName)
+	if !slices.Equal(old, pressures) {
+		err := c.updateConditions(ctx, node, old, pressures)
+		if err != nil {
+			return err
+		}
+		if len(pressures) == 0 {
+			c.pressures.Delete(nodeName)
+		} else {
+			c.pressures.Store(nodeName, pressures)
+		}
+	}
+
+	if evictSignal == nil {
+		return nil
+	}
+
+	// Like kubelet, only one pod is evicted in each sync
+	// so that the pressure is reevaluated before evicting the next one.
+	pod, ok := rankPodsForEviction(evictUsages)
+	if !ok {
+		return nil
+	}
+	return c.evictPod(ctx, pod, evictSignal.Resource)
+}
+
+// activePods returns the pods on the node which are not terminated.
+func (c *NodePressureController) activePods(nodeName string) []*corev1.Pod {
+	refs, ok := c.listPods(nodeName)
+	if !ok {
+		return nil
+	}
+
+	pods := make([]*corev1.Pod, 0, len(refs))
+	for _, ref := range refs {
+		pod, ok := c.podCacheGetter.GetWithNamespace(ref.Name, ref.Namespace)
+		if !ok {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+// usages returns the usages of the resource by each pod and the total of them.
+func (c *NodePressureController) usages(resourceName corev1.ResourceName, pods []*corev1.Pod) ([]podUsage, float64) {
+	usages := make([]podUsage, 0, len(pods))
+	total := 0.0
+	for _, pod := range pods {
+		usage := c.podResourceUsage(string(resourceName), pod.Namespace, pod.Name)
+		total += usage
+		usages = append(usages, podUsage{
+			Pod:     pod,
+			Usage:   usage,
+			Request: podRequest(pod, resourceName),
+		})
+	}
+	return usages, total
+}
+
+func (c *NodePressureController) updateConditions(ctx context.Context, node *corev1.Node, old, pressures []corev1.NodeConditionType) error {
+	now := metav1.NewTime(c.clock.Now())
+
+	var changed []corev1.NodeCondition
+	for _, cond := range nodePressureConditions(pressures) {
+		signal, ok := nodePressureSignalFor(cond.Type)
+		if !ok || slices.Contains(old, cond.Type) == slices.Contains(pressures, cond.Type) {
+			continue
+		}
+		cond.LastHeartbeatTime = now
+		cond.LastTransitionTime = now
+		changed = append(changed, cond)
+
+		if c.recorder != nil {
+			reason := signal.NoPressureEvent
+			if cond.Status == corev1.ConditionTrue {
+				reason = signal.PressureEvent
+			}
+			c.recorder.Event(&corev1.ObjectReference{
+				Kind: "Node",
+				UID:  node.UID,
+				Name: node.Name,
+			}, corev1.EventTypeNormal, reason, fmt.Sprintf("Node %s status is now: %s", node.Name, reason))
+		}
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"conditions": changed,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.typedClient.CoreV1().Nodes().Patch(ctx, node.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to patch conditions of node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// evictedContainerStatuses returns the statuses of the containers killed by the eviction.
+func evictedContainerStatuses(statuses []corev1.ContainerStatus, now metav1.Time) []corev1.ContainerStatus {
+	evicted := make([]corev1.ContainerStatus, 0, len(statuses))
+	for _, status := range statuses {
+		status := *status.DeepCopy()
+		if status.State.Terminated == nil {
+			terminated := &corev1.ContainerStateTerminated{
+				ExitCode:    evictedExitCode,
+				Reason:      "Evicted",
+				FinishedAt:  now,
+				ContainerID: status.ContainerID,
+			}
+			if status.State.Running != nil {
+				terminated.StartedAt = status.State.Running.StartedAt
+			}
+			status.State = corev1.ContainerState{
+				Terminated: terminated,
+			}
+		}
+		status.Ready = false
+		status.Started = new(false)
+		evicted = append(evicted, status)
+	}
+	return evicted
+}
+
+func (c *NodePressureController) evictPod(ctx context.Context, usage podUsage, resourceName corev1.ResourceName) error {
+	pod := usage.Pod
+	message := fmt.Sprintf("The node was low on resource: %s. Pod was using %s, request is %s.",
+		resourceName,
+		resource.NewQuantity(int64(usage.Usage), resource.BinarySI),
+		resource.NewQuantity(int64(usage.Request), resource.BinarySI),
+	)
+
+	// The same as kubelet, the pod is marked as failed instead of being deleted,
+	// so the PodDisruptionBudgets are not respected.
+	// https://kubernetes.io/docs/concepts/scheduling-eviction/node-pressure-eviction/
+	now := metav1.NewTime(c.clock.Now())
+	status := map[string]any{
+		"phase":   corev1.PodFailed,
+		"reason":  "Evicted",
+		"message": message,
+		"conditions": []corev1.PodCondition{
+			{
+				Type:               corev1.DisruptionTarget,
+				Status:             corev1.ConditionTrue,
+				Reason:             corev1.PodReasonTerminationByKubelet,
+				Message:            message,
+				LastTransitionTime: now,
+			},
+			{
+				Type:               corev1.PodReady,
+				Status:             corev1.ConditionFalse,
+				Reason:             "PodFailed",
+				LastTransitionTime: now,
+			},
+			{
+				Type:               corev1.ContainersReady,
+				Status:             corev1.ConditionFalse,
+				Reason:             "PodFailed",
+				LastTransitionTime: now,
+			},
+		},
+	}
+	if len(pod.Status.ContainerStatuses) != 0 {
+		status["containerStatuses"] = evictedContainerStatuses(pod.Status.ContainerStatuses, now)
+	}
+	data, err := json.Marshal(map[string]any{
+		"status": status,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.typedClient.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to evict pod %s: %w", log.KObj(pod), err)
+	}
+
+	if c.recorder != nil {
+		c.recorder.Event(&corev1.ObjectReference{
+			Kind:      "Pod",
+			UID:       pod.UID,
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		}, corev1.EventTypeWarning, "Evicted", message)
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("Evicted pod",
+		"pod", log.KObj(pod),
+		"node", pod.Spec.NodeName,
+		"resource", resourceName,
+	)
+	return nil
+}
+
+// rankPodsForEviction returns the pod to be evicted first by the ranking of kubelet,
+// the pods whose usage exceeds the request first, then the pods with lower priority,
+// then the pods with larger usage relative to the request.
+// https://kubernetes.io/docs/concepts/scheduling-eviction/node-pressure-eviction/#pod-selection-for-kubelet-eviction
+func rankPodsForEviction(usages []podUsage) (podUsage, bool) {
+	usages = slices.DeleteFunc(slices.Clone(usages), func(u podUsage) bool {
+		return u.Pod.DeletionTimestamp != nil
+	})
+	if len(usages) == 0 {
+		return podUsage{}, false
+	}
+
+	slices.SortStableFunc(usages, func(a, b podUsage) int {
+		aExceeds := a.Usage > a.Request
+		bExceeds := b.Usage > b.Request
+		if aExceeds != bExceeds {
+			if aExceeds {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(podPriority(a.Pod), podPriority(b.Pod)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Usage-b.Request, a.Usage-a.Request)
+	})
+	return usages[0], true
+}
+
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+func podRequest(pod *corev1.Pod, resourceName corev1.ResourceName) float64 {
+	sum := 0.0
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[resourceName]; ok {
+			sum += q.AsApproximateFloat64()
+		}
+	}
+	return sum
+}
+
+func nodePressureSignalFor(condition corev1.NodeConditionType) (nodePressureSignal, bool) {
+	for _, signal := range nodePressureSignals {
+		if signal.Condition == condition {
+			return signal, true
+		}
+	}
+	return nodePressureSignal{}, false
+}
+
+// nodePressureConditions returns the default conditions of nodes with the conditions under pressure set.
+func nodePressureConditions(pressures []corev1.NodeConditionType) []corev1.NodeCondition {
+	conditions := gotpl.NodeConditions()
+	for i, cond := range conditions {
+		if !slices.Contains(pressures, cond.Type) {
+			continue
+		}
+		signal, ok := nodePressureSignalFor(cond.Type)
+		if !ok {
+			continue
+		}
+		conditions[i].Status = corev1.ConditionTrue
+		conditions[i].Reason = signal.Reason
+		conditions[i].Message = signal.Message
+	}
+	return conditions
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+)
+
+func TestNodePressureController(t *testing.T) {
+	newPod := func(name string, request string, priority int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node0",
+				Priority: &priority,
+				Containers: []corev1.Container{
+					{
+						Name: "container0",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse(request),
+							},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+					{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  "container0",
+						Ready: true,
+						State: corev1.ContainerState{
+							Running: &corev1.ContainerStateRunning{},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	clientset := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node0",
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		newPod("pod0", "500Mi", 0),
+		newPod("pod1", "100Mi", 0),
+		newPod("pod2", "0", 100),
+	)
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	t.Cleanup(cancel)
+
+	nodeCacheGetter, err := informer.NewInformer[*corev1.Node, *corev1.NodeList](clientset.CoreV1().Nodes()).
+		WatchWithSyncedCache(ctx, informer.Option{}, nil, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	podCacheGetter, err := informer.NewInformer[*corev1.Pod, *corev1.PodList](clientset.CoreV1().Pods(corev1.NamespaceAll)).
+		WatchWithSyncedCache(ctx, informer.Option{}, nil, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewNodePressureController(NodePressureControllerConfig{
+		TypedClient:     clientset,
+		NodeCacheGetter: nodeCacheGetter,
+		PodCacheGetter:  podCacheGetter,
+		ListNodes: func() []string {
+			return []string{"node0"}
+		},
+		ListPods: func(nodeName string) ([]log.ObjectRef, bool) {
+			return []log.ObjectRef{
+				{Name: "pod0", Namespace: "default"},
+				{Name: "pod1", Namespace: "default"},
+				{Name: "pod2", Namespace: "default"},
+			}, true
+		},
+		SyncInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usages := map[string]string{
+		"pod0": "400Mi",
+		"pod1": "500Mi",
+		"pod2": "300Mi",
+	}
+	err = c.Start(ctx, func(resourceName, podNamespace, podName string) float64 {
+		if resourceName != string(corev1.ResourceMemory) {
+			return 0
+		}
+		q := resource.MustParse(usages[podName])
+		return q.AsApproximateFloat64()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conditionStatus := func(conditions []corev1.NodeCondition, typ corev1.NodeConditionType) corev1.ConditionStatus {
+		for _, cond := range conditions {
+			if cond.Type == typ {
+				return cond.Status
+			}
+		}
+		return ""
+	}
+
+	c.syncAll(ctx)
+
+	if got := conditionStatus(c.NodeConditions("node0"), corev1.NodeMemoryPressure); got != corev1.ConditionTrue {
+		t.Errorf("expected memory pressure, got %q", got)
+	}
+	if got := conditionStatus(c.NodeConditions("node0"), corev1.NodeDiskPressure); got != corev1.ConditionFalse {
+		t.Errorf("expected no disk pressure, got %q", got)
+	}
+
+	node, err := clientset.CoreV1().Nodes().Get(ctx, "node0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := conditionStatus(node.Status.Conditions, corev1.NodeMemoryPressure); got != corev1.ConditionTrue {
+		t.Errorf("expected memory pressure condition on the node, got %q", got)
+	}
+
+	// The pod1 exceeds its request and has the lowest priority among those exceeding.
+	for _, name := range []string{"pod0", "pod1", "pod2"} {
+		pod, err := clientset.CoreV1().Pods("default").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		evicted := pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted"
+		if evicted != (name == "pod1") {
+			t.Errorf("unexpected eviction of %s: phase %q, reason %q", name, pod.Status.Phase, pod.Status.Reason)
+		}
+		if !evicted {
+			continue
+		}
+		status := pod.Status.ContainerStatuses[0]
+		if status.Ready || status.State.Terminated == nil ||
+			status.State.Terminated.ExitCode != evictedExitCode || status.State.Terminated.Reason != "Evicted" {
+			t.Errorf("expected the container of %s to be terminated by the eviction, got %+v", name, status)
+		}
+		if _, ok := probeKilledRestartTime(status); ok {
+			t.Errorf("expected the evicted container of %s not to be restarted by the probes", name)
+		}
+		for _, cond := range pod.Status.Conditions {
+			if (cond.Type == corev1.PodReady || cond.Type == corev1.ContainersReady) && cond.Status != corev1.ConditionFalse {
+				t.Errorf("expected the condition %s of %s to be false, got %s", cond.Type, name, cond.Status)
+			}
+		}
+	}
+
+	usages["pod1"] = "0"
+	c.syncAll(ctx)
+
+	if got := conditionStatus(c.NodeConditions("node0"), corev1.NodeMemoryPressure); got != corev1.ConditionFalse {
+		t.Errorf("expected memory pressure to be relieved, got %q", got)
+	}
+	node, err = clientset.CoreV1().Nodes().Get(ctx, "node0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := conditionStatus(node.Status.Conditions, corev1.NodeMemoryPressure); got != corev1.ConditionFalse {
+		t.Errorf("expected no memory pressure condition on the node, got %q", got)
+	}
+}
+
+func TestEvictedContainerStatuses(t *testing.T) {
+	now := metav1.Now()
+	started := metav1.NewTime(now.Add(-time.Minute))
+	statuses := []corev1.ContainerStatus{
+		{
+			Name: "running",
+			State: corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{StartedAt: started},
+			},
+			Ready: true,
+		},
+		{
+			// The container backing off from a probe kill is evicted too.
+			Name: "backing-off",
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason},
+			},
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   probeKilledExitCode,
+					Reason:     "Error",
+					FinishedAt: started,
+				},
+			},
+			RestartCount: 1,
+		},
+	}
+	if _, ok := probeKilledRestartTime(statuses[1]); !ok {
+		t.Fatalf("expected the container to be backing off from a probe kill")
+	}
+
+	for _, status := range evictedContainerStatuses(statuses, now) {
+		terminated := status.State.Terminated
+		if status.Ready || terminated == nil || terminated.ExitCode != evictedExitCode || terminated.Reason != "Evicted" {
+			t.Errorf("expected the container %s to be terminated by the eviction, got %+v", status.Name, status)
+		}
+		if _, ok := probeKilledRestartTime(status); ok {
+			t.Errorf("expected the evicted container %s not to be restarted by the probes", status.Name)
+		}
+	}
+	if got := statuses[0].State.Running; got == nil {
+		t.Errorf("expected the statuses not to be modified")
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/expression"
+	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+)
+
+const (
+	// nodeStatusMaxImages is the same as the default of the max number of images reported by kubelet.
+	nodeStatusMaxImages = 50
+
+	// The range of the simulated size of images.
+	nodeStatusMinImageSize = 10 * 1024 * 1024
+	nodeStatusMaxImageSize = 1024 * 1024 * 1024
+)
+
+// NodeStatusController simulates the fields of the status of nodes that kubelet reports from the pods on them,
+// such as the images, the volumes in use and the allocatable,
+// they are available in the stages of nodes by the template functions.
+type NodeStatusController struct {
+	typedClient     kubernetes.Interface
+	nodeCacheGetter informer.Getter[*corev1.Node]
+	podCacheGetter  informer.Getter[*corev1.Pod]
+	listPods        func(nodeName string) ([]log.ObjectRef, bool)
+
+	shrinkAllocatable bool
+
+	pvcCacheGetter informer.Getter[*corev1.PersistentVolumeClaim]
+	pvCacheGetter  informer.Getter[*corev1.PersistentVolume]
+}
+
+// NodeStatusControllerConfig is the configuration for the NodeStatusController
+type NodeStatusControllerConfig struct {
+	TypedClient     kubernetes.Interface
+	NodeCacheGetter informer.Getter[*corev1.Node]
+	PodCacheGetter  informer.Getter[*corev1.Pod]
+	ListPods        func(nodeName string) ([]log.ObjectRef, bool)
+	// ShrinkAllocatable shrinks the allocatable pods and ephemeral storage of the node by the pods scheduled to it.
+	ShrinkAllocatable bool
+}
+
+// NewNodeStatusController creates a new NodeStatusController
+func NewNodeStatusController(conf NodeStatusControllerConfig) (*NodeStatusController, error) {
+	if conf.NodeCacheGetter == nil || conf.PodCacheGetter == nil {
+		return nil, fmt.Errorf("node and pod cache are required")
+	}
+
+	c := &NodeStatusController{
+		typedClient:       conf.TypedClient,
+		nodeCacheGetter:   conf.NodeCacheGetter,
+		podCacheGetter:    conf.PodCacheGetter,
+		listPods:          conf.ListPods,
+		shrinkAllocatable: conf.ShrinkAllocatable,
+	}
+	return c, nil
+}
+
+// Start starts the NodeStatusController,
+// it watches the persistent volumes and claims to resolve the volumes of pods.
+func (c *NodeStatusController) Start(ctx context.Context) error {
+	var err error
+	pvcsCli := c.typedClient.CoreV1().PersistentVolumeClaims(corev1.NamespaceAll)
+	c.pvcCacheGetter, err = informer.NewInformer[*corev1.PersistentVolumeClaim, *corev1.PersistentVolumeClaimList](pvcsCli).
+		WatchWithCache(ctx, informer.Option{}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch persistent volume claims: %w", err)
+	}
+
+	pvsCli := c.typedClient.CoreV1().PersistentVolumes()
+	c.pvCacheGetter, err = informer.NewInformer[*corev1.PersistentVolume, *corev1.PersistentVolumeList](pvsCli).
+		WatchWithCache(ctx, informer.Option{}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch persistent volumes: %w", err)
+	}
+	return nil
+}
+
+// FuncMap returns the template functions of the simulated status of nodes.
+func (c *NodeStatusController) FuncMap() gotpl.FuncMap {
+	return gotpl.FuncMap{
+		"NodeImagesWith": func(nodeName string) (any, error) {
+			return expression.ToJSONStandard(c.NodeImages(nodeName))
+		},
+		"NodeVolumesInUseWith": func(nodeName string) (any, error) {
+			return expression.ToJSONStandard(c.NodeVolumesInUse(nodeName))
+		},
+		"NodeVolumesAttachedWith": func(nodeName string) (any, error) {
+			return expression.ToJSONStandard(c.NodeVolumesAttached(nodeName))
+		},
+		"NodeAllocatableWith": func(nodeName string) (any, error) {
+			return expression.ToJSONStandard(c.NodeAllocatable(nodeName))
+		},
+	}
+}
+
+// NodeImages returns the images of the containers that have been run on the node,
+// the images reported before are kept, the same as kubelet keeps them until the image garbage collection.
+func (c *NodeStatusController) NodeImages(nodeName string) []corev1.ContainerImage {
+	images := map[string]corev1.ContainerImage{}
+	if node, ok := c.nodeCacheGetter.Get(nodeName); ok {
+		for _, image := range node.Status.Images {
+			if len(image.Names) == 0 {
+				continue
+			}
+			images[image.Names[0]] = image
+		}
+	}
+
+	for _, pod := range c.activePods(nodeName) {
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			if container.Image == "" {
+				continue
+			}
+			if _, ok := images[container.Image]; ok {
+				continue
+			}
+			images[container.Image] = corev1.ContainerImage{
+				Names:     []string{container.Image},
+				SizeBytes: imageSize(container.Image),
+			}
+		}
+	}
+
+	list := make([]corev1.ContainerImage, 0, len(images))
+	for _, image := range images {
+		list = append(list, image)
+	}
+	// The same as kubelet, the largest images are reported first
+	slices.SortFunc(list, func(a, b corev1.ContainerImage) int {
+		if n := cmp.Compare(b.SizeBytes, a.SizeBytes); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Names[0], b.Names[0])
+	})
+	if len(list) > nodeStatusMaxImages {
+		list = list[:nodeStatusMaxImages]
+	}
+	return list
+}
+
+// NodeVolumesInUse returns the unique names of the attachable volumes used by the pods on the node.
+func (c *NodeStatusController) NodeVolumesInUse(nodeName string) []corev1.UniqueVolumeName {
+	volumes := []corev1.UniqueVolumeName{}
+	for _, pod := range c.activePods(nodeName) {
+		for _, volume := range pod.Spec.Volumes {
+			name, ok := c.uniqueVolumeName(pod, volume)
+			if !ok {
+				continue
+			}
+			volumes = append(volumes, name)
+		}
+	}
+	slices.Sort(volumes)
+	return slices.Compact(volumes)
+}
+
+// NodeVolumesAttached returns the volumes attached to the node, which are the volumes in use.
+func (c *NodeStatusController) NodeVolumesAttached(nodeName string) []corev1.AttachedVolume {
+	volumes := c.NodeVolumesInUse(nodeName)
+	attached := make([]corev1.AttachedVolume, 0, len(volumes))
+	for _, volume := range volumes {
+		attached = append(attached, corev1.AttachedVolume{
+			Name: volume,
+		})
+	}
+	return attached
+}
+
+// NodeAllocatable returns the allocatable of the node, the pods and the ephemeral storage shrink as the pods are scheduled to the node,
+// they are derived from the capacity so that they do not shrink again on each heartbeat.
+// It returns nil unless the allocatable is shrunk, so the allocatable of the node is left as it is.
+func (c *NodeStatusController) NodeAllocatable(nodeName string) corev1.ResourceList {
+	if !c.shrinkAllocatable {
+		return nil
+	}
+	node, ok := c.nodeCacheGetter.Get(nodeName)
+	if !ok {
+		return nil
+	}
+
+	allocatable := node.Status.Allocatable.DeepCopy()
+	if allocatable == nil {
+		allocatable = corev1.ResourceList{}
+	}
+
+	pods := c.activePods(nodeName)
+	if capacity, ok := node.Status.Capacity[corev1.ResourcePods]; ok {
+		remain := capacity.Value() - int64(len(pods))
+		allocatable[corev1.ResourcePods] = *resource.NewQuantity(max(remain, 0), resource.DecimalSI)
+	}
+	if capacity, ok := node.Status.Capacity[corev1.ResourceEphemeralStorage]; ok {
+		remain := capacity.DeepCopy()
+		for _, pod := range pods {
+			for _, container := range pod.Spec.Containers {
+				if q, ok := container.Resources.Requests[corev1.ResourceEphemeralStorage]; ok {
+					remain.Sub(q)
+				}
+			}
+		}
+		if remain.Sign() < 0 {
+			remain = *resource.NewQuantity(0, resource.BinarySI)
+		}
+		allocatable[corev1.ResourceEphemeralStorage] = remain
+	}
+	return allocatable
+}
+
+func (c *NodeStatusController) activePods(nodeName string) []*corev1.Pod {
+	refs, ok := c.listPods(nodeName)
+	if !ok {
+		return nil
+	}
+
+	pods := make([]*corev1.Pod, 0, len(refs))
+	for _, ref := range refs {
+		pod, ok := c.podCacheGetter.GetWithNamespace(ref.Name, ref.Namespace)
+		if !ok {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+// uniqueVolumeName returns the unique name of the volume if it is attachable, in the same format as kubelet.
+func (c *NodeStatusController) uniqueVolumeName(pod *corev1.Pod, volume corev1.Volume) (corev1.UniqueVolumeName, bool) {
+	if volume.PersistentVolumeClaim == nil || c.pvcCacheGetter == nil || c.pvCacheGetter == nil {
+		return "", false
+	}
+
+	pvc, ok := c.pvcCacheGetter.GetWithNamespace(volume.PersistentVolumeClaim.ClaimName, pod.Namespace)
+	if !ok || pvc.Spec.VolumeName == "" {
+		return "", false
+	}
+	pv, ok := c.pvCacheGetter.Get(pvc.Spec.VolumeName)
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case pv.Spec.CSI != nil:
+		return corev1.UniqueVolumeName("kubernetes.io/csi/" + pv.Spec.CSI.Driver + "^" + pv.Spec.CSI.VolumeHandle), true
+	case pv.Spec.AWSElasticBlockStore != nil:
+		return corev1.UniqueVolumeName("kubernetes.io/aws-ebs/" + pv.Spec.AWSElasticBlockStore.VolumeID), true
+	case pv.Spec.GCEPersistentDisk != nil:
+		return corev1.UniqueVolumeName("kubernetes.io/gce-pd/" + pv.Spec.GCEPersistentDisk.PDName), true
+	case pv.Spec.AzureDisk != nil:
+		return corev1.UniqueVolumeName("kubernetes.io/azure-disk/" + pv.Spec.AzureDisk.DataDiskURI), true
+	}
+	return "", false
+}
+
+// imageSize returns the simulated size of the image, which is stable for the same image.
+func imageSize(image string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(image))
+	return nodeStatusMinImageSize + int64(h.Sum64()%(nodeStatusMaxImageSize-nodeStatusMinImageSize))
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+)
+
+func TestNodeStatusController(t *testing.T) {
+	newPod := func(name string, image string, request string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node0",
+				Containers: []corev1.Container{
+					{
+						Name:  "container0",
+						Image: image,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceEphemeralStorage: resource.MustParse(request),
+							},
+						},
+					},
+				},
+				Volumes: []corev1.Volume{
+					{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+								ClaimName: "pvc0",
+							},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	clientset := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node0",
+			},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:              resource.MustParse("1"),
+					corev1.ResourcePods:             resource.MustParse("110"),
+					corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("900m"),
+				},
+				Images: []corev1.ContainerImage{
+					{
+						Names:     []string{"busybox"},
+						SizeBytes: 1,
+					},
+				},
+			},
+		},
+		newPod("pod0", "nginx", "1Gi", corev1.PodRunning),
+		newPod("pod1", "redis", "2Gi", corev1.PodRunning),
+		newPod("pod2", "nginx", "4Gi", corev1.PodSucceeded),
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pvc0",
+				Namespace: "default",
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				VolumeName: "pv0",
+			},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pv0",
+			},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{
+						Driver:       "csi.example.com",
+						VolumeHandle: "vol-0",
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	t.Cleanup(cancel)
+
+	nodeCacheGetter, err := informer.NewInformer[*corev1.Node, *corev1.NodeList](clientset.CoreV1().Nodes()).
+		WatchWithSyncedCache(ctx, informer.Option{}, nil, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	podCacheGetter, err := informer.NewInformer[*corev1.Pod, *corev1.PodList](clientset.CoreV1().Pods(corev1.NamespaceAll)).
+		WatchWithSyncedCache(ctx, informer.Option{}, nil, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewNodeStatusController(NodeStatusControllerConfig{
+		TypedClient:     clientset,
+		NodeCacheGetter: nodeCacheGetter,
+		PodCacheGetter:  podCacheGetter,
+		ListPods: func(nodeName string) ([]log.ObjectRef, bool) {
+			return []log.ObjectRef{
+				{Name: "pod0", Namespace: "default"},
+				{Name: "pod1", Namespace: "default"},
+				{Name: "pod2", Namespace: "default"},
+			}, true
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	images := c.NodeImages("node0")
+	names := []string{}
+	for _, image := range images {
+		names = append(names, image.Names[0])
+		if image.Names[0] != "busybox" && image.SizeBytes != imageSize(image.Names[0]) {
+			t.Errorf("want image %s to be the stable size, got %d", image.Names[0], image.SizeBytes)
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"busybox", "nginx", "redis"}; !slices.Equal(names, want) {
+		t.Errorf("want images %v, got %v", want, names)
+	}
+	if !slices.IsSortedFunc(images, func(a, b corev1.ContainerImage) int {
+		return int(b.SizeBytes - a.SizeBytes)
+	}) {
+		t.Errorf("want images sorted by size, got %v", images)
+	}
+
+	var volumes []corev1.UniqueVolumeName
+	for ctx.Err() == nil {
+		volumes = c.NodeVolumesInUse("node0")
+		if len(volumes) != 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if want := []corev1.UniqueVolumeName{"kubernetes.io/csi/csi.example.com^vol-0"}; !slices.Equal(volumes, want) {
+		t.Errorf("want volumes in use %v, got %v", want, volumes)
+	}
+	if attached := c.NodeVolumesAttached("node0"); len(attached) != 1 || attached[0].Name != volumes[0] {
+		t.Errorf("want volumes attached %v, got %v", volumes, attached)
+	}
+
+	if allocatable := c.NodeAllocatable("node0"); allocatable != nil {
+		t.Errorf("want no allocatable unless it is shrunk, got %v", allocatable)
+	}
+
+	// The pods and the ephemeral storage shrink by the active pods scheduled to the node.
+	c.shrinkAllocatable = true
+	for range 2 {
+		allocatable := c.NodeAllocatable("node0")
+		if got := allocatable[corev1.ResourcePods]; got.Value() != 108 {
+			t.Errorf("want allocatable pods 108, got %s", got.String())
+		}
+		if got, want := allocatable[corev1.ResourceEphemeralStorage], resource.MustParse("7Gi"); got.Cmp(want) != 0 {
+			t.Errorf("want allocatable ephemeral storage %s, got %s", want.String(), got.String())
+		}
+		if got := allocatable[corev1.ResourceCPU]; got.String() != "900m" {
+			t.Errorf("want allocatable cpu kept, got %s", got.String())
+		}
+	}
+}
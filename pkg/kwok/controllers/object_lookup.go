@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
+)
+
+// objectLookupRetryInterval is the interval to retry watching the kinds failed to watch.
+const objectLookupRetryInterval = 30 * time.Second
+
+// objectLookup looks up the objects related to the resources for the CEL expressions of stages,
+// the objects other than nodes are cached by informers started in the background
+// only for the kinds referenced by the stages or looked up,
+// an object of a kind not cached yet is not found.
+type objectLookup struct {
+	dynamicClient   dynamic.Interface
+	restMapper      meta.RESTMapper
+	nodeCacheGetter informer.Getter[*corev1.Node]
+
+	caches    utilsmaps.SyncMap[schema.GroupVersionKind, *objectCache]
+	requested utilsmaps.SyncMap[schema.GroupVersionKind, struct{}]
+	requests  chan schema.GroupVersionKind
+}
+
+type objectCache struct {
+	namespaced bool
+	getter     informer.Getter[*unstructured.Unstructured]
+}
+
+func newObjectLookup(dynamicClient dynamic.Interface, restMapper meta.RESTMapper, nodeCacheGetter informer.Getter[*corev1.Node]) *objectLookup {
+	return &objectLookup{
+		dynamicClient:   dynamicClient,
+		restMapper:      restMapper,
+		nodeCacheGetter: nodeCacheGetter,
+		requests:        make(chan schema.GroupVersionKind, 64),
+	}
+}
+
+// Start starts watching the kinds requested by the stages and the lookups in the background.
+func (l *objectLookup) Start(ctx context.Context) {
+	if l.dynamicClient == nil || l.restMapper == nil {
+		return
+	}
+	go l.run(ctx)
+}
+
+// Lookup returns the lookup used by stages.
+func (l *objectLookup) Lookup() *lifecycle.Lookup {
+	return &lifecycle.Lookup{
+		Node:   l.node,
+		Object: l.object,
+		Watch:  l.request,
+	}
+}
+
+func (l *objectLookup) node(name string) (*corev1.Node, bool) {
+	if l.nodeCacheGetter == nil {
+		return nil, false
+	}
+	return l.nodeCacheGetter.Get(name)
+}
+
+func (l *objectLookup) object(apiVersion, kind, namespace, name string) (*unstructured.Unstructured, bool) {
+	gvk := schema.FromAPIVersionAndKind(apiVersion, kind)
+	c, ok := l.caches.Load(gvk)
+	if !ok {
+		l.request(gvk)
+		return nil, false
+	}
+
+	if !c.namespaced {
+		return c.getter.Get(name)
+	}
+	return c.getter.GetWithNamespace(name, namespace)
+}
+
+// request requests to watch the kind in the background without blocking the lookup.
+func (l *objectLookup) request(gvk schema.GroupVersionKind) {
+	if _, loaded := l.requested.LoadOrStore(gvk, struct{}{}); loaded {
+		return
+	}
+	select {
+	case l.requests <- gvk:
+	default:
+		// The requests are full, request again on the next lookup.
+		l.requested.Delete(gvk)
+	}
+}
+
+func (l *objectLookup) run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	ticker := time.NewTicker(objectLookupRetryInterval)
+	defer ticker.Stop()
+
+	failed := map[schema.GroupVersionKind]struct{}{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case gvk := <-l.requests:
+			err := l.watch(ctx, gvk)
+			if err != nil {
+				logger.Error("Failed to watch objects for lookup, retrying later",
+					"err", err,
+					"kind", gvk,
+				)
+				failed[gvk] = struct{}{}
+			}
+		case <-ticker.C:
+			for gvk := range failed {
+				err := l.watch(ctx, gvk)
+				if err != nil {
+					logger.Debug("Failed to watch objects for lookup",
+						"err", err,
+						"kind", gvk,
+					)
+					continue
+				}
+				delete(failed, gvk)
+			}
+		}
+	}
+}
+
+func (l *objectLookup) watch(ctx context.Context, gvk schema.GroupVersionKind) error {
+	mapping, err := l.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get mapping for %s: %w", gvk, err)
+	}
+
+	cli := l.dynamicClient.Resource(mapping.Resource)
+	getter, err := informer.NewInformer[*unstructured.Unstructured, *unstructured.UnstructuredList](cli).
+		WatchWithCache(ctx, informer.Option{}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", mapping.Resource, err)
+	}
+
+	l.caches.Store(gvk, &objectCache{
+		namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+		getter:     getter,
+	})
+	return nil
+}
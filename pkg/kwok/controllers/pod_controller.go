@@ -18,8 +18,11 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -27,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -37,12 +41,14 @@ import (
 	"sigs.k8s.io/kwok/pkg/config/resources"
 	"sigs.k8s.io/kwok/pkg/log"
 	"sigs.k8s.io/kwok/pkg/utils/client"
+	"sigs.k8s.io/kwok/pkg/utils/format"
 	"sigs.k8s.io/kwok/pkg/utils/gotpl"
 	"sigs.k8s.io/kwok/pkg/utils/informer"
 	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
 	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
 	utilsnet "sigs.k8s.io/kwok/pkg/utils/net"
 	"sigs.k8s.io/kwok/pkg/utils/queue"
+	utilsrand "sigs.k8s.io/kwok/pkg/utils/rand"
 	utilsslices "sigs.k8s.io/kwok/pkg/utils/slices"
 	"sigs.k8s.io/kwok/pkg/utils/wait"
 )
@@ -54,27 +60,34 @@ var (
 // PodController is a fake pods implementation that can be used to test
 type PodController struct {
 	clock                                 clock.Clock
+	queueClock                            queue.Clock
 	dynamicClient                         dynamic.Interface
 	restMapper                            meta.RESTMapper
 	typedClient                           kubernetes.Interface
 	impersonatingTypedClient              client.TypedClientImpersonator
 	nodeCacheGetter                       informer.Getter[*corev1.Node]
+	podCacheGetter                        informer.Getter[*corev1.Pod]
 	disregardStatusWithAnnotationSelector labels.Selector
 	disregardStatusWithLabelSelector      labels.Selector
 	nodeIP                                string
-	defaultCIDR                           string
+	defaultCIDRs                          []string
 	nodeGetFunc                           func(nodeName string) (*NodeInfo, bool)
 	ipPools                               utilsmaps.SyncMap[string, *ipPool]
+	reservedIPs                           utilsmaps.SyncMap[string, string]
+	managePodsWithFieldSelector           string
 	renderer                              gotpl.Renderer
 	podsSets                              utilsmaps.SyncMap[log.ObjectRef, *PodInfo]
 	podsOnNode                            utilsmaps.SyncMap[string, *utilsmaps.SyncMap[log.ObjectRef, *PodInfo]]
 	preprocessChan                        chan *corev1.Pod
 	playStageParallelism                  uint
 	lifecycle                             resources.Getter[lifecycle.Lifecycle]
+	limiters                              *lifecycle.Limiters
 	delayQueue                            queue.WeightDelayingQueue[resourceStageJob[*corev1.Pod]]
 	backoff                               wait.Backoff
 	delayQueueMapping                     utilsmaps.SyncMap[string, resourceStageJob[*corev1.Pod]]
 	recorder                              record.EventRecorder
+	stageStatus                           *StageStatusController
+	playingJobs                           *JobGroup
 	readOnlyFunc                          func(nodeName string) bool
 	enableMetrics                         bool
 }
@@ -86,21 +99,26 @@ type PodInfo struct {
 // PodControllerConfig is the configuration for the PodController
 type PodControllerConfig struct {
 	Clock                                 clock.Clock
+	QueueClock                            queue.Clock
 	DynamicClient                         dynamic.Interface
 	RESTMapper                            meta.RESTMapper
 	TypedClient                           kubernetes.Interface
 	ImpersonatingTypedClient              client.TypedClientImpersonator
 	NodeCacheGetter                       informer.Getter[*corev1.Node]
+	PodCacheGetter                        informer.Getter[*corev1.Pod]
 	DisregardStatusWithAnnotationSelector string
 	DisregardStatusWithLabelSelector      string
 	NodeIP                                string
 	CIDR                                  string
+	ManagePodsWithFieldSelector           string
 	NodeGetFunc                           func(nodeName string) (*NodeInfo, bool)
 	NodeHasMetric                         func(nodeName string) bool
 	Lifecycle                             resources.Getter[lifecycle.Lifecycle]
 	PlayStageParallelism                  uint
 	FuncMap                               gotpl.FuncMap
 	Recorder                              record.EventRecorder
+	StageStatus                           *StageStatusController
+	PlayingJobs                           *JobGroup
 	ReadOnlyFunc                          func(nodeName string) bool
 	EnableMetrics                         bool
 }
@@ -124,25 +142,34 @@ func NewPodController(conf PodControllerConfig) (*PodController, error) {
 	if conf.Clock == nil {
 		conf.Clock = clock.RealClock{}
 	}
+	if conf.QueueClock == nil {
+		conf.QueueClock = conf.Clock
+	}
 
 	c := &PodController{
 		clock:                                 conf.Clock,
+		queueClock:                            conf.QueueClock,
 		dynamicClient:                         conf.DynamicClient,
 		restMapper:                            conf.RESTMapper,
 		typedClient:                           conf.TypedClient,
 		impersonatingTypedClient:              conf.ImpersonatingTypedClient,
 		nodeCacheGetter:                       conf.NodeCacheGetter,
+		podCacheGetter:                        conf.PodCacheGetter,
 		disregardStatusWithAnnotationSelector: disregardStatusWithAnnotationSelector,
 		disregardStatusWithLabelSelector:      disregardStatusWithLabelSelector,
 		nodeIP:                                conf.NodeIP,
-		defaultCIDR:                           conf.CIDR,
+		defaultCIDRs:                          utilsslices.Map(strings.Split(conf.CIDR, ","), strings.TrimSpace),
+		managePodsWithFieldSelector:           conf.ManagePodsWithFieldSelector,
 		nodeGetFunc:                           conf.NodeGetFunc,
-		delayQueue:                            queue.NewWeightDelayingQueue[resourceStageJob[*corev1.Pod]](conf.Clock),
+		limiters:                              lifecycle.NewLimiters(),
+		delayQueue:                            queue.NewWeightDelayingQueue[resourceStageJob[*corev1.Pod]](conf.QueueClock),
 		backoff:                               defaultBackoff(),
 		lifecycle:                             conf.Lifecycle,
 		playStageParallelism:                  conf.PlayStageParallelism,
 		preprocessChan:                        make(chan *corev1.Pod),
 		recorder:                              conf.Recorder,
+		stageStatus:                           conf.StageStatus,
+		playingJobs:                           conf.PlayingJobs,
 		readOnlyFunc:                          conf.ReadOnlyFunc,
 		enableMetrics:                         conf.EnableMetrics,
 	}
@@ -161,6 +188,11 @@ func NewPodController(conf PodControllerConfig) (*PodController, error) {
 // Start starts the fake pod controller
 // It will modify the pods status to we want
 func (c *PodController) Start(ctx context.Context, events <-chan informer.Event[*corev1.Pod]) error {
+	err := c.reservePodIPs(ctx)
+	if err != nil {
+		return err
+	}
+
 	go c.preprocessWorker(ctx)
 	for i := uint(0); i < c.playStageParallelism; i++ {
 		go c.playStageWorker(ctx)
@@ -235,7 +267,10 @@ func (c *PodController) preprocess(ctx context.Context, pod *corev1.Pod) error {
 	}
 
 	lc := c.lifecycle.Get()
+	c.limiters.Prune(c.lifecycle.Version(), lc)
+	start := c.clock.Now()
 	stage, err := lc.Match(ctx, event)
+	observeStageMatch(stage, "Pod", c.clock.Since(start))
 	if err != nil {
 		return fmt.Errorf("stage match: %w", err)
 	}
@@ -246,6 +281,8 @@ func (c *PodController) preprocess(ctx context.Context, pod *corev1.Pod) error {
 		return nil
 	}
 
+	c.stageStatus.Matched(stage.Name())
+
 	now := c.clock.Now()
 	delay, _, err := stage.Delay(ctx, event, now)
 	if err != nil {
@@ -269,6 +306,9 @@ func (c *PodController) preprocess(ctx context.Context, pod *corev1.Pod) error {
 		RetryCount: new(uint64),
 		StepIndex:  new(uint64),
 	}
+	if ok {
+		item = continueStageJob(resourceJob, item)
+	}
 	// we add a normal(fresh) stage job with weight 0,
 	// resulting in that it will always be processed with high priority compared to those retry ones
 	c.addStageJob(ctx, item, delay, 0)
@@ -280,12 +320,67 @@ func (c *PodController) playStageWorker(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
 	for ctx.Err() == nil {
+		if !c.playingJobs.WaitResumed(ctx.Done()) {
+			return
+		}
 		pod, ok := c.delayQueue.GetOrWaitWithDone(ctx.Done())
 		if !ok {
 			return
 		}
+		if c.playingJobs.Paused() {
+			// The playing is paused while waiting for the job, put it back to the queue.
+			c.delayQueue.AddWeightAfter(pod, pod.Weight, 0)
+			continue
+		}
+		observeStageDequeued(pod.Stage.Name(), "Pod")
+
+		if c.readOnly(pod.Resource.Spec.NodeName) {
+			// The node is not writable now, such as partitioned from the apiserver,
+			// the job is dropped and played again when the node is managed again.
+			c.delayQueueMapping.Delete(pod.Key)
+			continue
+		}
+
+		now := c.clock.Now()
+		requeue := func(delay time.Duration) bool {
+			if !requeueStageJob(&c.delayQueueMapping, c.delayQueue, pod, c.queueClock.Now(), delay) {
+				return false
+			}
+			observeStageEnqueued(pod.Stage.Name(), "Pod")
+			return true
+		}
+		if delay, ok := c.limiters.Acquire(pod.Stage, now, func() bool { return requeue(0) }); !ok {
+			// The stage is limited by its rate limit, put it back to the queue
+			// instead of blocking the worker so that the other stages can be played,
+			// or when the stage is released if it is limited by the max in-flight.
+			if delay > 0 {
+				requeue(delay)
+			}
+			continue
+		}
+
+		if !c.playingJobs.Add() {
+			// The controller is being drained, the job is left to the next leader.
+			c.limiters.Release(pod.Stage)
+			return
+		}
 		c.delayQueueMapping.Delete(pod.Key)
-		remainIndex, err := c.playStage(ctx, pod.Resource, pod.Stage, int(*pod.StepIndex))
+		pod = refreshWaitJob(pod, c.getFromCache)
+		// The stage is played to the end even if the context is canceled,
+		// so that the resource is not left half-played for the next leader,
+		// unless the playing is aborted as the resource may be taken over already.
+		playCtx, playCancel := c.playingJobs.Context(ctx)
+		remainIndex, err := c.playStage(playCtx, pod.Resource, pod.Stage, int(*pod.StepIndex))
+		playCancel()
+		c.limiters.Release(pod.Stage)
+		c.playingJobs.Done()
+		var waitErr *lifecycle.WaitError
+		if errors.As(err, &waitErr) {
+			c.waitStageJob(ctx, pod, waitErr, remainIndex, now)
+			continue
+		}
+		observeStagePlay(pod.Stage.Name(), "Pod", c.clock.Since(now), err, remainIndex >= 0)
+		c.stageStatus.Played(pod.Stage.Name(), err)
 		if err != nil {
 			logger.Error("failed to apply stage",
 				"err", err,
@@ -304,12 +399,57 @@ func (c *PodController) playStageWorker(ctx context.Context) {
 			)
 			// for failed jobs, we re-push them into the queue with a lower weight
 			// and a backoff period to avoid blocking normal tasks
-			retryDelay := backoffDelayByStep(retryCount, c.backoff)
+			retryDelay := backoffDelayByStep(pod.Key+"/"+pod.Stage.Name(), retryCount, c.backoff)
+			var allocErr *podIPAllocationError
+			if errors.As(err, &allocErr) {
+				retryDelay = utilsrand.Jitter(pod.Key+"/sandbox/"+format.String(retryCount), podSandboxRetryPeriod, 0.5)
+			}
 			c.addStageJob(ctx, pod, retryDelay, 1)
 		}
 	}
 }
 
+// waitStageJob puts the job back to the queue to wait for the condition of the wait step,
+// or plays the timeout stage if the wait times out.
+func (c *PodController) waitStageJob(ctx context.Context, job resourceStageJob[*corev1.Pod], waitErr *lifecycle.WaitError, stepIndex int, now time.Time) {
+	changed := false
+	if queued, ok := c.delayQueueMapping.Load(job.Key); ok {
+		// The resource has been changed and matched again while playing the stage.
+		if queued.Stage.Name() != job.Stage.Name() {
+			return
+		}
+		// Continue waiting on the step with the latest resource.
+		job.Resource = queued.Resource
+		changed = true
+	}
+
+	// The wait is timed on the queue clock, which is frozen while the simulation is paused.
+	next, delay, err := nextWaitJob(job, waitErr, stepIndex, c.lifecycle.Get(), c.queueClock.Now())
+	if err != nil {
+		observeStagePlay(job.Stage.Name(), "Pod", c.clock.Since(now), err, false)
+		c.stageStatus.Played(job.Stage.Name(), err)
+		logger := log.FromContext(ctx)
+		logger.Error("failed to wait for stage",
+			"err", err,
+			"pod", job.Key,
+			"stage", job.Stage.Name(),
+		)
+		return
+	}
+	if changed {
+		delay = 0
+	}
+	c.addStageJob(ctx, next, delay, 0)
+}
+
+// getFromCache returns the latest pod from the informer cache.
+func (c *PodController) getFromCache(pod *corev1.Pod) (*corev1.Pod, bool) {
+	if c.podCacheGetter == nil {
+		return nil, false
+	}
+	return c.podCacheGetter.GetWithNamespace(pod.Name, pod.Namespace)
+}
+
 // playStage plays the stage.
 // The returned boolean indicates whether the applying action needs to be retried.
 func (c *PodController) playStage(ctx context.Context, pod *corev1.Pod, stage *lifecycle.Stage, stepIndex int) (int, error) {
@@ -346,7 +486,6 @@ func (c *PodController) playStage(ctx context.Context, pod *corev1.Pod, stage *l
 			return nil
 		},
 		func(patch *lifecycle.Patch) error {
-			c.markPodIP(ctx, pod)
 			changed, err := checkNeedPatchWithTyped(pod, patch.Data, patch.Type)
 			if err != nil {
 				return fmt.Errorf("failed to check need patch for pod %s: %w", pod.Name, err)
@@ -372,7 +511,18 @@ func (c *PodController) playStage(ctx context.Context, pod *corev1.Pod, stage *l
 		},
 	)
 	if err != nil {
-		if shouldRetry(err) {
+		var allocErr *podIPAllocationError
+		if errors.As(err, &allocErr) {
+			patchErr := c.failedCreatePodSandbox(ctx, pod, allocErr)
+			if patchErr != nil {
+				logger.Error("Failed to patch pod",
+					"err", patchErr,
+				)
+			}
+			return remainIndex, err
+		}
+		var waitErr *lifecycle.WaitError
+		if shouldRetry(err) || errors.As(err, &waitErr) {
 			return remainIndex, err
 		}
 		return -1, err
@@ -496,6 +646,10 @@ loop:
 					c.putPodInfo(pod)
 				}
 				if c.need(pod) {
+					// Reserve the IPs of the pod before any stage is played,
+					// so that they are not allocated to other pods.
+					c.markPodIP(ctx, pod)
+
 					if c.readOnly(pod.Spec.NodeName) {
 						logger.Debug("Skip pod",
 							"reason", "read only",
@@ -536,7 +690,9 @@ loop:
 					key := log.KObj(pod).String()
 					resourceJob, ok := c.delayQueueMapping.LoadAndDelete(key)
 					if ok {
-						c.delayQueue.Cancel(resourceJob)
+						if c.delayQueue.Cancel(resourceJob) {
+							observeStageDequeued(resourceJob.Stage.Name(), "Pod")
+						}
 					}
 				}
 			}
@@ -559,52 +715,106 @@ func (c *PodController) ipPool(cidr string) (*ipPool, error) {
 	}
 
 	pool = newIPPool(ipnet)
-	c.ipPools.Store(cidr, pool)
+	c.reservedIPs.Range(func(ip, owner string) bool {
+		pool.Use(ip, owner)
+		return true
+	})
+	pool, _ = c.ipPools.LoadOrStore(cidr, pool)
 	return pool, nil
 }
 
-// recyclingPodIP recycling pod ip
-func (c *PodController) recyclingPodIP(ctx context.Context, pod *corev1.Pod) {
-	// Skip host network
-	if pod.Spec.HostNetwork {
-		return
+// reservePodIPs reserves the IPs of all the existing pods before any stage is played,
+// so that a new IP is not allocated to a pod while another pod listed later already holds it.
+// The IPs are reserved in the pools created afterward until the pods are deleted.
+func (c *PodController) reservePodIPs(ctx context.Context) error {
+	list, err := c.typedClient.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector:   c.managePodsWithFieldSelector,
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods to reserve their IPs: %w", err)
+	}
+	for _, pod := range list.Items {
+		if pod.Spec.HostNetwork {
+			continue
+		}
+		owner := podIPOwner(string(pod.UID), pod.Name, pod.Namespace)
+		for _, ip := range podStatusIPs(&pod) {
+			c.reservedIPs.Store(ip, owner)
+		}
 	}
+	return nil
+}
 
+// podNode returns the managed node to allocate the pod IPs
+func (c *PodController) podNode(nodeName string) (*corev1.Node, bool) {
 	if c.nodeCacheGetter == nil {
-		return
+		return nil, false
 	}
 
-	_, has := c.nodeGetFunc(pod.Spec.NodeName)
+	_, has := c.nodeGetFunc(nodeName)
 	if !has {
-		return
+		return nil, false
 	}
 
-	node, ok := c.nodeCacheGetter.Get(pod.Spec.NodeName)
-	if !ok {
-		return
-	}
+	return c.nodeCacheGetter.Get(nodeName)
+}
 
-	cidrs := []string{c.defaultCIDR}
+// podCIDRs returns the CIDRs of the node to allocate the pod IPs,
+// there is one CIDR for each IP family on dual-stack nodes.
+func (c *PodController) podCIDRs(node *corev1.Node) []string {
 	if len(node.Spec.PodCIDRs) != 0 {
-		cidrs = node.Spec.PodCIDRs
-	} else if node.Spec.PodCIDR != "" {
-		cidrs = []string{node.Spec.PodCIDR}
+		return node.Spec.PodCIDRs
 	}
+	if node.Spec.PodCIDR != "" {
+		return []string{node.Spec.PodCIDR}
+	}
+	return c.defaultCIDRs
+}
+
+// podIPOwner returns the owner of the IPs reserved for the pod
+func podIPOwner(uid, name, namespace string) string {
+	if uid != "" {
+		return uid
+	}
+	return namespace + "/" + name
+}
 
-	ips := make([]string, 0, min(len(pod.Status.PodIPs), 1))
+// podStatusIPs returns the IPs in the status of the pod
+func podStatusIPs(pod *corev1.Pod) []string {
 	if len(pod.Status.PodIPs) != 0 {
+		ips := make([]string, 0, len(pod.Status.PodIPs))
 		for _, podIP := range pod.Status.PodIPs {
 			ips = append(ips, podIP.IP)
 		}
-	} else if pod.Status.PodIP != "" {
-		ips = []string{pod.Status.PodIP}
+		return ips
+	}
+	if pod.Status.PodIP != "" {
+		return []string{pod.Status.PodIP}
 	}
+	return nil
+}
 
-	for i, ip := range ips {
-		if i >= len(cidrs) {
-			break
-		}
-		pool, err := c.ipPool(cidrs[i])
+// recyclingPodIP recycling pod ip
+func (c *PodController) recyclingPodIP(ctx context.Context, pod *corev1.Pod) {
+	// Skip host network
+	if pod.Spec.HostNetwork {
+		return
+	}
+
+	owner := podIPOwner(string(pod.UID), pod.Name, pod.Namespace)
+	ips := podStatusIPs(pod)
+	for _, ip := range ips {
+		c.reservedIPs.CompareAndDelete(ip, owner)
+	}
+
+	node, ok := c.podNode(pod.Spec.NodeName)
+	if !ok {
+		return
+	}
+
+	for _, cidr := range c.podCIDRs(node) {
+		pool, err := c.ipPool(cidr)
 		if err != nil {
 			logger := log.FromContext(ctx)
 			logger.Error("Failed to get ip pool",
@@ -612,55 +822,36 @@ func (c *PodController) recyclingPodIP(ctx context.Context, pod *corev1.Pod) {
 				"pod", log.KObj(pod),
 				"node", pod.Spec.NodeName,
 			)
-		} else {
+			continue
+		}
+		for _, ip := range ips {
 			pool.Put(ip)
 		}
+		pool.Release(owner)
 	}
 }
 
+// markPodIP reserves the IPs in the status of the pod,
+// so that the IPs allocated before the controller was started are not allocated again.
 func (c *PodController) markPodIP(ctx context.Context, pod *corev1.Pod) {
 	// Skip host network
 	if pod.Spec.HostNetwork {
 		return
 	}
 
-	if c.nodeCacheGetter == nil {
-		return
-	}
-
-	_, has := c.nodeGetFunc(pod.Spec.NodeName)
-	if !has {
+	ips := podStatusIPs(pod)
+	if len(ips) == 0 {
 		return
 	}
 
-	node, ok := c.nodeCacheGetter.Get(pod.Spec.NodeName)
+	node, ok := c.podNode(pod.Spec.NodeName)
 	if !ok {
 		return
 	}
 
-	cidrs := []string{c.defaultCIDR}
-	if len(node.Spec.PodCIDRs) != 0 {
-		cidrs = node.Spec.PodCIDRs
-	} else if node.Spec.PodCIDR != "" {
-		cidrs = []string{node.Spec.PodCIDR}
-	}
-
-	ips := make([]string, 0, min(len(pod.Status.PodIPs), 1))
-	if len(pod.Status.PodIPs) != 0 {
-		for _, podIP := range pod.Status.PodIPs {
-			ips = append(ips, podIP.IP)
-		}
-	} else if pod.Status.PodIP != "" {
-		ips = []string{pod.Status.PodIP}
-	}
-
-	// Mark the pod IP that existed before the kubelet was started
-
-	for i, ip := range ips {
-		if i >= len(cidrs) {
-			break
-		}
-		pool, err := c.ipPool(cidrs[i])
+	owner := podIPOwner(string(pod.UID), pod.Name, pod.Namespace)
+	for _, cidr := range c.podCIDRs(node) {
+		pool, err := c.ipPool(cidr)
 		if err != nil {
 			logger := log.FromContext(ctx)
 			logger.Error("Failed to get ip pool",
@@ -668,8 +859,17 @@ func (c *PodController) markPodIP(ctx context.Context, pod *corev1.Pod) {
 				"pod", log.KObj(pod),
 				"node", pod.Spec.NodeName,
 			)
-		} else {
-			pool.Use(ip)
+			continue
+		}
+		for _, ip := range ips {
+			if !pool.Use(ip, owner) {
+				logger := log.FromContext(ctx)
+				logger.Warn("Pod IP is held by another pod",
+					"ip", ip,
+					"pod", log.KObj(pod),
+					"node", pod.Spec.NodeName,
+				)
+			}
 		}
 	}
 }
@@ -734,12 +934,15 @@ func (c *PodController) funcNodeIPsWith(nodeName string) []string {
 }
 
 func (c *PodController) funcPodIP() string {
-	podCIDR := c.defaultCIDR
-	pool, err := c.ipPool(podCIDR)
-	if err == nil {
-		return pool.Get()
+	pool, err := c.ipPool(c.defaultCIDRs[0])
+	if err != nil {
+		return c.nodeIP
 	}
-	return c.nodeIP
+	ip, err := pool.Get("")
+	if err != nil {
+		return c.nodeIP
+	}
+	return ip
 }
 
 func (c *PodController) funcPodIPWith(nodeName string, hostNetwork bool, uid, name, namespace string) (string, error) {
@@ -747,32 +950,20 @@ func (c *PodController) funcPodIPWith(nodeName string, hostNetwork bool, uid, na
 		return c.funcNodeIPWith(nodeName), nil
 	}
 
-	if c.nodeCacheGetter == nil {
-		return c.nodeIP, nil
-	}
-
-	_, has := c.nodeGetFunc(nodeName)
-	if !has {
-		return c.nodeIP, nil
-	}
-
-	node, ok := c.nodeCacheGetter.Get(nodeName)
+	node, ok := c.podNode(nodeName)
 	if !ok {
 		return c.nodeIP, nil
 	}
 
-	podCIDRs := []string{c.defaultCIDR}
-	if len(node.Spec.PodCIDRs) != 0 {
-		podCIDRs = node.Spec.PodCIDRs
-	} else if node.Spec.PodCIDR != "" {
-		podCIDRs = []string{node.Spec.PodCIDR}
+	pool, err := c.ipPool(c.podCIDRs(node)[0])
+	if err != nil {
+		return c.nodeIP, nil
 	}
-
-	pool, err := c.ipPool(podCIDRs[0])
-	if err == nil {
-		return pool.Get(), nil
+	ip, err := pool.Get(podIPOwner(uid, name, namespace))
+	if err != nil {
+		return "", &podIPAllocationError{Range: 0, Err: err}
 	}
-	return c.nodeIP, nil
+	return ip, nil
 }
 
 func (c *PodController) funcPodIPsWith(nodeName string, hostNetwork bool, uid, name, namespace string) ([]string, error) {
@@ -780,34 +971,24 @@ func (c *PodController) funcPodIPsWith(nodeName string, hostNetwork bool, uid, n
 		return c.funcNodeIPsWith(nodeName), nil
 	}
 
-	if c.nodeCacheGetter == nil {
-		return c.nodeIPs(), nil
-	}
-
-	_, has := c.nodeGetFunc(nodeName)
-	if !has {
-		return c.nodeIPs(), nil
-	}
-
-	node, ok := c.nodeCacheGetter.Get(nodeName)
+	node, ok := c.podNode(nodeName)
 	if !ok {
 		return c.nodeIPs(), nil
 	}
 
-	podCIDRs := []string{c.defaultCIDR}
-	if len(node.Spec.PodCIDRs) != 0 {
-		podCIDRs = node.Spec.PodCIDRs
-	} else if node.Spec.PodCIDR != "" {
-		podCIDRs = []string{node.Spec.PodCIDR}
-	}
-
+	owner := podIPOwner(uid, name, namespace)
+	podCIDRs := c.podCIDRs(node)
 	ips := make([]string, 0, len(podCIDRs))
-	for _, podCIDR := range podCIDRs {
+	for i, podCIDR := range podCIDRs {
 		pool, err := c.ipPool(podCIDR)
 		if err != nil {
 			continue
 		}
-		ips = append(ips, pool.Get())
+		ip, err := pool.Get(owner)
+		if err != nil {
+			return nil, &podIPAllocationError{Range: i, Err: err}
+		}
+		ips = append(ips, ip)
 	}
 	if len(ips) == 0 {
 		return c.nodeIPs(), nil
@@ -815,6 +996,83 @@ func (c *PodController) funcPodIPsWith(nodeName string, hostNetwork bool, uid, n
 	return ips, nil
 }
 
+// podIPAllocationError is returned when the IPs of the pod can't be allocated
+type podIPAllocationError struct {
+	Range int
+	Err   error
+}
+
+func (e *podIPAllocationError) Error() string {
+	return fmt.Sprintf("failed to allocate for range %d: %v", e.Range, e.Err)
+}
+
+func (e *podIPAllocationError) Unwrap() error {
+	return e.Err
+}
+
+// podSandboxRetryPeriod is the period to create the sandbox again after it failed, the same as kubelet.
+const podSandboxRetryPeriod = 10 * time.Second
+
+// failedCreatePodSandbox simulates that kubelet failed to create the sandbox of the pod
+// because the CNI plugin can't allocate the IPs,
+// the pod stays ContainerCreating until the sandbox is created.
+func (c *PodController) failedCreatePodSandbox(ctx context.Context, pod *corev1.Pod, allocErr *podIPAllocationError) error {
+	if c.recorder != nil {
+		c.recorder.Eventf(&corev1.ObjectReference{
+			Kind:      "Pod",
+			UID:       pod.UID,
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		}, corev1.EventTypeWarning, "FailedCreatePodSandBox",
+			"Failed to create pod sandbox: rpc error: code = Unknown desc = failed to setup network for sandbox: plugin type=%q failed (add): %s",
+			"kwok", allocErr.Error())
+	}
+
+	if len(pod.Status.ContainerStatuses) != 0 {
+		return nil
+	}
+
+	reason := "ContainerCreating"
+	initContainerStatuses := make([]corev1.ContainerStatus, 0, len(pod.Spec.InitContainers))
+	for _, container := range pod.Spec.InitContainers {
+		reason = "PodInitializing"
+		initContainerStatuses = append(initContainerStatuses, waitingContainerStatus(container, reason))
+	}
+	containerStatuses := make([]corev1.ContainerStatus, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		containerStatuses = append(containerStatuses, waitingContainerStatus(container, reason))
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"phase":                 corev1.PodPending,
+			"initContainerStatuses": initContainerStatuses,
+			"containerStatuses":     containerStatuses,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.typedClient.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func waitingContainerStatus(container corev1.Container, reason string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:    container.Name,
+		Image:   container.Image,
+		Started: new(false),
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{
+				Reason: reason,
+			},
+		},
+	}
+}
+
 // putPodInfo puts pod info
 func (c *PodController) putPodInfo(pod *corev1.Pod) {
 	podInfo := &PodInfo{}
@@ -857,8 +1115,17 @@ func (c *PodController) List(nodeName string) ([]log.ObjectRef, bool) {
 	return m.Keys(), true
 }
 
+// PendingJobs returns the stage jobs of the pods waiting to be played.
+func (c *PodController) PendingJobs() []StageJob {
+	return pendingJobs(&c.delayQueueMapping, func(*corev1.Pod) string {
+		return "Pod"
+	})
+}
+
 // addStageJob adds a stage to be applied into the underlying weight delay queue and the associated helper map
 func (c *PodController) addStageJob(ctx context.Context, job resourceStageJob[*corev1.Pod], delay time.Duration, weight int) {
+	job.ScheduledTime = c.queueClock.Now().Add(delay)
+	job.Weight = weight
 	old, loaded := c.delayQueueMapping.Swap(job.Key, job)
 	if loaded {
 		if !c.delayQueue.Cancel(old) {
@@ -866,7 +1133,10 @@ func (c *PodController) addStageJob(ctx context.Context, job resourceStageJob[*c
 			logger.Debug("Failed to cancel stage",
 				"stage", job.Stage.Name(),
 			)
+		} else {
+			observeStageDequeued(old.Stage.Name(), "Pod")
 		}
 	}
 	c.delayQueue.AddWeightAfter(job, weight, delay)
+	observeStageEnqueued(job.Stage.Name(), "Pod")
 }
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -402,3 +403,141 @@ func TestPodController(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPodControllerIPAllocation(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node0",
+			},
+			Spec: corev1.NodeSpec{
+				PodCIDR:  "10.0.0.0/30",
+				PodCIDRs: []string{"10.0.0.0/30", "fd00::/126"},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod0",
+				Namespace: "default",
+				UID:       "pod0",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node0",
+			},
+			Status: corev1.PodStatus{
+				PodIP:  "10.0.0.1",
+				PodIPs: []corev1.PodIP{{IP: "10.0.0.1"}, {IP: "fd00::1"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod2",
+				Namespace: "default",
+				UID:       "pod2",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node0",
+				Containers: []corev1.Container{
+					{Name: "container0", Image: "image0"},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	t.Cleanup(cancel)
+
+	nodeCache, err := informer.NewInformer[*corev1.Node, *corev1.NodeList](clientset.CoreV1().Nodes()).
+		WatchWithSyncedCache(ctx, informer.Option{}, nil, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lc, _ := lifecycle.NewLifecycle(nil)
+	c, err := NewPodController(PodControllerConfig{
+		TypedClient:     clientset,
+		NodeCacheGetter: nodeCache,
+		NodeIP:          defaultNodeIP,
+		CIDR:            defaultPodCIDR,
+		Lifecycle:       resources.NewStaticGetter(lc),
+		NodeGetFunc: func(nodeName string) (*NodeInfo, bool) {
+			return &NodeInfo{}, nodeName == "node0"
+		},
+		PlayStageParallelism: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The IPs of the pod that existed before the controller was started are reserved
+	// before its event is received.
+	err = c.reservePodIPs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ip, err := c.funcPodIPWith("node0", false, "pod1", "pod1", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip != "10.0.0.2" {
+		t.Errorf("funcPodIPWith() = %v, want %v", ip, "10.0.0.2")
+	}
+
+	ips, err := c.funcPodIPsWith("node0", false, "pod1", "pod1", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.2", "fd00::2"}; !slices.Equal(ips, want) {
+		t.Errorf("funcPodIPsWith() = %v, want %v", ips, want)
+	}
+
+	_, err = c.funcPodIPsWith("node0", false, "pod2", "pod2", "default")
+	var allocErr *podIPAllocationError
+	if !errors.As(err, &allocErr) || !errors.Is(err, errIPPoolExhausted) {
+		t.Fatalf("funcPodIPsWith() error = %v, want the exhausted error", err)
+	}
+	want := "failed to allocate for range 0: no IP addresses available in range set: 10.0.0.1-10.0.0.2"
+	if allocErr.Error() != want {
+		t.Errorf("funcPodIPsWith() error = %q, want %q", allocErr.Error(), want)
+	}
+
+	pod, err := clientset.CoreV1().Pods("default").Get(ctx, "pod2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.failedCreatePodSandbox(ctx, pod, allocErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod, err = clientset.CoreV1().Pods("default").Get(ctx, "pod2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pod.Status.ContainerStatuses) != 1 ||
+		pod.Status.ContainerStatuses[0].State.Waiting == nil ||
+		pod.Status.ContainerStatuses[0].State.Waiting.Reason != "ContainerCreating" {
+		t.Errorf("want the container to be ContainerCreating, got %+v", pod.Status.ContainerStatuses)
+	}
+
+	c.recyclingPodIP(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "default",
+			UID:       "pod1",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node0",
+		},
+	})
+
+	ips, err = c.funcPodIPsWith("node0", false, "pod2", "pod2", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"10.0.0.2", "fd00::2"}; !slices.Equal(ips, want) {
+		t.Errorf("funcPodIPsWith() = %v, want %v", ips, want)
+	}
+}
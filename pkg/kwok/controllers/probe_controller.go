@@ -0,0 +1,685 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/config/resources"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
+	"sigs.k8s.io/kwok/pkg/utils/queue"
+	utilsslices "sigs.k8s.io/kwok/pkg/utils/slices"
+)
+
+const (
+	probeTypeStartup  = "Startup"
+	probeTypeLiveness = "Liveness"
+
+	// probeKilledExitCode is the exit code of the container killed by kubelet
+	// when the process does not handle the SIGTERM.
+	probeKilledExitCode = 137
+	// crashLoopBackOffReason is the reason of the container waiting to be restarted after a failure.
+	crashLoopBackOffReason = "CrashLoopBackOff"
+)
+
+// ProbeController simulates the probes of kubelet.
+// It evaluates the startup, readiness and liveness probes of the containers by the results in Probe and ClusterProbe,
+// then updates the ready and started status of the containers, and restarts the containers whose probes failed.
+// The probes of each pod are evaluated again after the shortest periodSeconds of its probes.
+type ProbeController struct {
+	clock          clock.Clock
+	typedClient    kubernetes.Interface
+	podCacheGetter informer.Getter[*corev1.Pod]
+	listNodes      func() []string
+	listPods       func(nodeName string) ([]log.ObjectRef, bool)
+	readOnlyFunc   func(nodeName string) bool
+	recorder       record.EventRecorder
+	syncInterval   time.Duration
+
+	probes        resources.Getter[map[log.ObjectRef]*internalversion.Probe]
+	clusterProbes resources.Getter[[]*internalversion.ClusterProbe]
+
+	// queue is the pods waiting for the next round of the probes.
+	queue queue.DelayingQueue[log.ObjectRef]
+	// scheduled is the pods in the queue.
+	scheduled utilsmaps.SyncMap[log.ObjectRef, struct{}]
+}
+
+// ProbeControllerConfig is the configuration for the ProbeController
+type ProbeControllerConfig struct {
+	Clock          clock.Clock
+	TypedClient    kubernetes.Interface
+	PodCacheGetter informer.Getter[*corev1.Pod]
+	ListNodes      func() []string
+	ListPods       func(nodeName string) ([]log.ObjectRef, bool)
+	ReadOnlyFunc   func(nodeName string) bool
+	Recorder       record.EventRecorder
+	SyncInterval   time.Duration
+	Probes         resources.Getter[[]*internalversion.Probe]
+	ClusterProbes  resources.Getter[[]*internalversion.ClusterProbe]
+}
+
+// NewProbeController creates a new ProbeController
+func NewProbeController(conf ProbeControllerConfig) (*ProbeController, error) {
+	if conf.SyncInterval <= 0 {
+		return nil, fmt.Errorf("sync interval must be greater than 0")
+	}
+
+	if conf.PodCacheGetter == nil {
+		return nil, fmt.Errorf("pod cache is required")
+	}
+
+	if conf.Probes == nil {
+		conf.Probes = resources.NewStaticGetter[[]*internalversion.Probe](nil)
+	}
+	if conf.ClusterProbes == nil {
+		conf.ClusterProbes = resources.NewStaticGetter[[]*internalversion.ClusterProbe](nil)
+	}
+
+	if conf.Clock == nil {
+		conf.Clock = clock.RealClock{}
+	}
+
+	c := &ProbeController{
+		clock:          conf.Clock,
+		typedClient:    conf.TypedClient,
+		podCacheGetter: conf.PodCacheGetter,
+		listNodes:      conf.ListNodes,
+		listPods:       conf.ListPods,
+		readOnlyFunc:   conf.ReadOnlyFunc,
+		recorder:       conf.Recorder,
+		syncInterval:   conf.SyncInterval,
+		probes:         resources.NewFilter(conf.Probes, indexProbes),
+		clusterProbes:  conf.ClusterProbes,
+		queue:          queue.NewDelayingQueue[log.ObjectRef](conf.Clock),
+	}
+	return c, nil
+}
+
+// indexProbes indexes the probes by the namespace and name of the pod.
+func indexProbes(probes []*internalversion.Probe) map[log.ObjectRef]*internalversion.Probe {
+	index := make(map[log.ObjectRef]*internalversion.Probe, len(probes))
+	for _, p := range probes {
+		index[log.ObjectRef{Name: p.Name, Namespace: p.Namespace}] = p
+	}
+	return index
+}
+
+// Start starts the ProbeController
+func (c *ProbeController) Start(ctx context.Context) error {
+	go c.syncWorker(ctx)
+	go c.probeWorker(ctx)
+	return nil
+}
+
+// syncWorker schedules the probes of the pods not scheduled yet.
+func (c *ProbeController) syncWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(c.syncInterval):
+			c.syncAll(ctx)
+		}
+	}
+}
+
+// probeWorker evaluates the probes of the pods when they are due.
+func (c *ProbeController) probeWorker(ctx context.Context) {
+	for {
+		ref, ok := c.queue.GetOrWaitWithDone(ctx.Done())
+		if !ok {
+			return
+		}
+		c.syncPod(ctx, ref)
+	}
+}
+
+func (c *ProbeController) syncAll(ctx context.Context) {
+	for _, nodeName := range c.listNodes() {
+		if ctx.Err() != nil {
+			return
+		}
+		if c.readOnlyFunc != nil && c.readOnlyFunc(nodeName) {
+			continue
+		}
+
+		refs, ok := c.listPods(nodeName)
+		if !ok {
+			continue
+		}
+		for _, ref := range refs {
+			_, loaded := c.scheduled.LoadOrStore(ref, struct{}{})
+			if !loaded {
+				c.queue.Add(ref)
+			}
+		}
+	}
+}
+
+// syncPod evaluates the probes of the pod and schedules the next round of them,
+// the pod is dropped from the queue until the next sync once it has no probes to evaluate.
+func (c *ProbeController) syncPod(ctx context.Context, ref log.ObjectRef) {
+	pod, ok := c.podCacheGetter.GetWithNamespace(ref.Name, ref.Namespace)
+	if !ok ||
+		pod.DeletionTimestamp != nil ||
+		pod.Status.Phase != corev1.PodRunning ||
+		(c.readOnlyFunc != nil && c.readOnlyFunc(pod.Spec.NodeName)) {
+		c.scheduled.Delete(ref)
+		return
+	}
+
+	probes, ok := c.getProbes(pod.Name, pod.Namespace)
+	if !ok {
+		c.scheduled.Delete(ref)
+		return
+	}
+
+	period := probePeriod(pod)
+	restartAfter, err := c.sync(ctx, pod, probes)
+	if err != nil {
+		logger := log.FromContext(ctx)
+		logger.Error("Failed to sync probes of pod",
+			"err", err,
+			"pod", log.KObj(pod),
+		)
+	}
+	if restartAfter > 0 && restartAfter < period {
+		period = restartAfter
+	}
+	c.queue.AddAfter(ref, period)
+}
+
+// probePeriod returns the shortest period of the probes in the spec of the pod.
+func probePeriod(pod *corev1.Pod) time.Duration {
+	var period time.Duration
+	for _, container := range pod.Spec.Containers {
+		for _, probe := range []*corev1.Probe{container.StartupProbe, container.LivenessProbe, container.ReadinessProbe} {
+			if probe == nil {
+				continue
+			}
+			p := newProbeTiming(probe).Period
+			if period == 0 || p < period {
+				period = p
+			}
+		}
+	}
+	if period == 0 {
+		// The same as the default period of the probe.
+		period = 10 * time.Second
+	}
+	return period
+}
+
+// sync evaluates the probes of the pod and patches the changed container statuses,
+// it returns how long until the next container backing off from a probe kill is restarted.
+func (c *ProbeController) sync(ctx context.Context, pod *corev1.Pod, probes []internalversion.ProbeContainer) (time.Duration, error) {
+	now := c.clock.Now()
+	changed := false
+	var restartAfter time.Duration
+	var killed []containerKill
+	var restarted []string
+	var unready []string
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		container, ok := utilsslices.Find(pod.Spec.Containers, func(container corev1.Container) bool {
+			return container.Name == status.Name
+		})
+		probe, found := findProbeInProbes(status.Name, probes)
+		if !ok || !found {
+			statuses = append(statuses, status)
+			continue
+		}
+
+		if restartAt, ok := probeKilledRestartTime(status); ok {
+			if now.Before(restartAt) {
+				if d := restartAt.Sub(now); restartAfter == 0 || d < restartAfter {
+					restartAfter = d
+				}
+				statuses = append(statuses, status)
+				continue
+			}
+			statuses = append(statuses, restartContainer(*status.DeepCopy(), now))
+			restarted = append(restarted, status.Name)
+			changed = true
+			continue
+		}
+
+		if status.State.Running == nil {
+			statuses = append(statuses, status)
+			continue
+		}
+
+		state := evaluateProbes(&container, probe, now.Sub(status.State.Running.StartedAt.Time))
+		newStatus := *status.DeepCopy()
+		if state.KilledBy != "" {
+			newStatus = killContainer(pod, newStatus, now)
+			killed = append(killed, containerKill{Name: status.Name, ProbeType: state.KilledBy})
+			if newStatus.State.Waiting != nil {
+				if d := crashLoopBackOff(newStatus); restartAfter == 0 || d < restartAfter {
+					restartAfter = d
+				}
+			}
+		} else {
+			newStatus.Started = &state.Started
+			newStatus.Ready = state.Ready
+			if status.Ready && !state.Ready {
+				unready = append(unready, status.Name)
+			}
+		}
+
+		if status.Ready != newStatus.Ready ||
+			!equalBoolPointer(status.Started, newStatus.Started) ||
+			status.RestartCount != newStatus.RestartCount ||
+			newStatus.State.Running == nil {
+			changed = true
+		}
+		statuses = append(statuses, newStatus)
+	}
+
+	if !changed {
+		return restartAfter, nil
+	}
+
+	err := c.patchStatus(ctx, pod, statuses, now)
+	if err != nil {
+		return restartAfter, err
+	}
+
+	for range unready {
+		c.recordPodEvent(pod, corev1.EventTypeWarning, "Unhealthy", "Readiness probe failed")
+	}
+	for _, kill := range killed {
+		c.recordPodEvent(pod, corev1.EventTypeWarning, "Unhealthy", kill.ProbeType+" probe failed")
+		c.recordPodEvent(pod, corev1.EventTypeNormal, "Killing",
+			fmt.Sprintf("Container %s failed %s probe, will be restarted", kill.Name, strings.ToLower(kill.ProbeType)))
+	}
+	for _, name := range restarted {
+		c.recordPodEvent(pod, corev1.EventTypeNormal, "Started", "Started container "+name)
+	}
+	return restartAfter, nil
+}
+
+// containerKill is a container killed by the failed probe.
+type containerKill struct {
+	Name      string
+	ProbeType string
+}
+
+func (c *ProbeController) patchStatus(ctx context.Context, pod *corev1.Pod, statuses []corev1.ContainerStatus, now time.Time) error {
+	var unready []string
+	for _, status := range statuses {
+		if !status.Ready {
+			unready = append(unready, status.Name)
+		}
+	}
+
+	ready := len(unready) == 0
+	containersReady := podCondition(corev1.ContainersReady, ready, unready, now)
+	podReady := podCondition(corev1.PodReady, ready && readinessGatesReady(pod), unready, now)
+
+	status := map[string]any{
+		"containerStatuses": statuses,
+	}
+	var conditions []corev1.PodCondition
+	for _, cond := range []corev1.PodCondition{containersReady, podReady} {
+		old, ok := utilsslices.Find(pod.Status.Conditions, func(old corev1.PodCondition) bool {
+			return old.Type == cond.Type
+		})
+		if ok && old.Status == cond.Status {
+			continue
+		}
+		conditions = append(conditions, cond)
+	}
+	if len(conditions) != 0 {
+		status["conditions"] = conditions
+	}
+
+	// The pod fails once all of its containers are terminated and will not be restarted.
+	if pod.Spec.RestartPolicy == corev1.RestartPolicyNever &&
+		!slices.ContainsFunc(statuses, func(status corev1.ContainerStatus) bool {
+			return status.State.Terminated == nil
+		}) {
+		status["phase"] = corev1.PodFailed
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"status": status,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.typedClient.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.StrategicMergePatchType, data, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return fmt.Errorf("failed to patch probe status of pod %s: %w", log.KObj(pod), err)
+	}
+	return nil
+}
+
+func (c *ProbeController) recordPodEvent(pod *corev1.Pod, eventType, reason, message string) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder.Event(&corev1.ObjectReference{
+		Kind:      "Pod",
+		UID:       pod.UID,
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+	}, eventType, reason, message)
+}
+
+func (c *ProbeController) getProbes(podName, podNamespace string) ([]internalversion.ProbeContainer, bool) {
+	p, has := c.probes.Get()[log.ObjectRef{Name: podName, Namespace: podNamespace}]
+	if has {
+		return p.Spec.Probes, true
+	}
+
+	for _, cp := range c.clusterProbes.Get() {
+		if !cp.Spec.Selector.Match(podName, podNamespace) {
+			continue
+		}
+		return cp.Spec.Probes, true
+	}
+	return nil, false
+}
+
+func findProbeInProbes(containerName string, probes []internalversion.ProbeContainer) (*internalversion.ProbeContainer, bool) {
+	var defaultProbe *internalversion.ProbeContainer
+	for i, p := range probes {
+		if len(p.Containers) == 0 && defaultProbe == nil {
+			defaultProbe = &probes[i]
+			continue
+		}
+		if slices.Contains(p.Containers, containerName) {
+			return &probes[i], true
+		}
+	}
+	return defaultProbe, defaultProbe != nil
+}
+
+// killContainer returns the status of the container killed by kubelet,
+// the container backs off in CrashLoopBackOff before restarting unless the restart policy is Never.
+func killContainer(pod *corev1.Pod, status corev1.ContainerStatus, now time.Time) corev1.ContainerStatus {
+	terminated := &corev1.ContainerStateTerminated{
+		ExitCode:    probeKilledExitCode,
+		Reason:      "Error",
+		StartedAt:   status.State.Running.StartedAt,
+		FinishedAt:  metav1.NewTime(now),
+		ContainerID: status.ContainerID,
+	}
+
+	status.Ready = false
+	status.Started = new(false)
+	if pod.Spec.RestartPolicy == corev1.RestartPolicyNever {
+		status.State = corev1.ContainerState{
+			Terminated: terminated,
+		}
+		return status
+	}
+
+	status.LastTerminationState = corev1.ContainerState{
+		Terminated: terminated,
+	}
+	status.State = corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{
+			Reason: crashLoopBackOffReason,
+			Message: fmt.Sprintf("back-off %s restarting failed container=%s pod=%s_%s(%s)",
+				crashLoopBackOff(status), status.Name, pod.Name, pod.Namespace, pod.UID),
+		},
+	}
+	return status
+}
+
+// crashLoopBackOff returns the back-off before restarting the container, the same as the crash stages use.
+func crashLoopBackOff(status corev1.ContainerStatus) time.Duration {
+	return gotpl.CrashLoopBackOff(int64(status.RestartCount))
+}
+
+// probeKilledRestartTime returns when the container backing off from a probe kill is restarted,
+// the containers backing off from other failures are left to the stages.
+func probeKilledRestartTime(status corev1.ContainerStatus) (time.Time, bool) {
+	waiting := status.State.Waiting
+	terminated := status.LastTerminationState.Terminated
+	if waiting == nil || waiting.Reason != crashLoopBackOffReason ||
+		terminated == nil || terminated.ExitCode != probeKilledExitCode || terminated.Reason != "Error" {
+		return time.Time{}, false
+	}
+	return terminated.FinishedAt.Add(crashLoopBackOff(status)), true
+}
+
+// restartContainer returns the status of the container restarted after backing off.
+func restartContainer(status corev1.ContainerStatus, now time.Time) corev1.ContainerStatus {
+	status.RestartCount++
+	status.Ready = false
+	status.Started = new(false)
+	status.State = corev1.ContainerState{
+		Running: &corev1.ContainerStateRunning{
+			StartedAt: metav1.NewTime(now),
+		},
+	}
+	return status
+}
+
+func podCondition(typ corev1.PodConditionType, ready bool, unready []string, now time.Time) corev1.PodCondition {
+	cond := corev1.PodCondition{
+		Type:               typ,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	if !ready {
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = "ContainersNotReady"
+		cond.Message = fmt.Sprintf("containers with unready status: [%s]", strings.Join(unready, " "))
+	}
+	return cond
+}
+
+// readinessGatesReady returns true if all the readiness gates of the pod are true.
+func readinessGatesReady(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		cond, ok := utilsslices.Find(pod.Status.Conditions, func(cond corev1.PodCondition) bool {
+			return cond.Type == gate.ConditionType
+		})
+		if !ok || cond.Status != corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+func equalBoolPointer(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// containerProbeState is the state of a container evaluated by its probes.
+type containerProbeState struct {
+	Started bool
+	Ready   bool
+	// KilledBy is the type of the probe whose failure killed the container.
+	KilledBy string
+}
+
+// evaluateProbes returns the state of the container at the elapsed time since it started.
+// Like kubelet, the startup probe gates the other probes, the readiness and liveness probes
+// change the state once the result is returned by the successThreshold or failureThreshold probes in a row,
+// and the probes not defined in the spec of the container are not simulated.
+func evaluateProbes(container *corev1.Container, probe *internalversion.ProbeContainer, elapsed time.Duration) containerProbeState {
+	state := containerProbeState{}
+
+	startedAt := time.Duration(0)
+	if container.StartupProbe != nil {
+		timing := newProbeTiming(container.StartupProbe)
+		segments := probeSegments(probe.Startup)
+		successAt, succeeded := firstSustained(segments, timing.InitialDelay, true, 0)
+		killAt := timing.InitialDelay + timing.FailureDuration()
+		if !succeeded || successAt > killAt {
+			if elapsed >= killAt {
+				state.KilledBy = probeTypeStartup
+			}
+			return state
+		}
+		if elapsed < successAt {
+			return state
+		}
+		startedAt = successAt
+	}
+	state.Started = true
+
+	if container.LivenessProbe != nil {
+		timing := newProbeTiming(container.LivenessProbe)
+		killAt, failed := firstSustained(probeSegments(probe.Liveness), max(startedAt, timing.InitialDelay), false, timing.FailureDuration())
+		if failed && elapsed >= killAt {
+			state.KilledBy = probeTypeLiveness
+			return state
+		}
+	}
+
+	if container.ReadinessProbe == nil {
+		state.Ready = true
+		return state
+	}
+	timing := newProbeTiming(container.ReadinessProbe)
+	state.Ready = readinessAt(probeSegments(probe.Readiness), max(startedAt, timing.InitialDelay), elapsed, timing)
+	return state
+}
+
+// probeTiming is the timing of a probe in the spec of the container.
+type probeTiming struct {
+	InitialDelay     time.Duration
+	Period           time.Duration
+	SuccessThreshold int32
+	FailureThreshold int32
+}
+
+func newProbeTiming(probe *corev1.Probe) probeTiming {
+	// The same as the defaults of the probe in the API server.
+	timing := probeTiming{
+		InitialDelay:     time.Duration(probe.InitialDelaySeconds) * time.Second,
+		Period:           10 * time.Second,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	if probe.PeriodSeconds > 0 {
+		timing.Period = time.Duration(probe.PeriodSeconds) * time.Second
+	}
+	if probe.SuccessThreshold > 0 {
+		timing.SuccessThreshold = probe.SuccessThreshold
+	}
+	if probe.FailureThreshold > 0 {
+		timing.FailureThreshold = probe.FailureThreshold
+	}
+	return timing
+}
+
+// SuccessDuration returns how long the probe has to succeed in a row to be considered successful.
+func (t probeTiming) SuccessDuration() time.Duration {
+	return time.Duration(t.SuccessThreshold-1) * t.Period
+}
+
+// FailureDuration returns how long the probe has to fail in a row to be considered failed.
+func (t probeTiming) FailureDuration() time.Duration {
+	return time.Duration(t.FailureThreshold-1) * t.Period
+}
+
+// probeSegment is a period of the same result of the probe, it lasts until the next segment starts.
+type probeSegment struct {
+	Start   time.Duration
+	Success bool
+}
+
+// probeSegments returns the segments of the results ordered by the start time,
+// the probe succeeds before the first result.
+func probeSegments(results []internalversion.ProbeResult) []probeSegment {
+	results = slices.Clone(results)
+	slices.SortStableFunc(results, func(a, b internalversion.ProbeResult) int {
+		return cmp.Compare(a.AfterMilliseconds, b.AfterMilliseconds)
+	})
+
+	segments := []probeSegment{{Start: 0, Success: true}}
+	for _, result := range results {
+		start := time.Duration(max(result.AfterMilliseconds, 0)) * time.Millisecond
+		last := &segments[len(segments)-1]
+		switch {
+		case last.Start == start:
+			last.Success = result.Success
+		case last.Success != result.Success:
+			segments = append(segments, probeSegment{Start: start, Success: result.Success})
+		}
+	}
+	return segments
+}
+
+// firstSustained returns the first time since the from that the probe has returned the result for the duration in a row.
+func firstSustained(segments []probeSegment, from time.Duration, success bool, duration time.Duration) (time.Duration, bool) {
+	for i, segment := range segments {
+		if segment.Success != success {
+			continue
+		}
+		start := max(segment.Start, from)
+		at := start + duration
+		if i+1 < len(segments) && segments[i+1].Start <= at {
+			continue
+		}
+		return at, true
+	}
+	return 0, false
+}
+
+// readinessAt returns whether the container is ready at the elapsed time,
+// the container is not ready until the readiness probe succeeds for the first time since the from.
+func readinessAt(segments []probeSegment, from, elapsed time.Duration, timing probeTiming) bool {
+	ready := false
+	for i, segment := range segments {
+		end := elapsed
+		if i+1 < len(segments) {
+			end = min(segments[i+1].Start, elapsed)
+		}
+		start := max(segment.Start, from)
+		if start > end || (start == end && end != elapsed) {
+			continue
+		}
+
+		duration := timing.FailureDuration()
+		if segment.Success {
+			duration = timing.SuccessDuration()
+		}
+		if start+duration <= end {
+			ready = segment.Success
+		}
+	}
+	return ready
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/config/resources"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+	"sigs.k8s.io/kwok/pkg/utils/informer"
+)
+
+func TestEvaluateProbes(t *testing.T) {
+	probe := &corev1.Probe{
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	tests := []struct {
+		name      string
+		container corev1.Container
+		probe     internalversion.ProbeContainer
+		elapsed   time.Duration
+		want      containerProbeState
+	}{
+		{
+			name:      "no probes",
+			container: corev1.Container{},
+			probe: internalversion.ProbeContainer{
+				Readiness: []internalversion.ProbeResult{{Success: false}},
+			},
+			want: containerProbeState{Started: true, Ready: true},
+		},
+		{
+			name: "not ready before initial delay",
+			container: corev1.Container{
+				ReadinessProbe: &corev1.Probe{InitialDelaySeconds: 5},
+			},
+			elapsed: 4 * time.Second,
+			want:    containerProbeState{Started: true, Ready: false},
+		},
+		{
+			name: "readiness failing within failure threshold",
+			container: corev1.Container{
+				ReadinessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Readiness: []internalversion.ProbeResult{{AfterMilliseconds: 30000, Success: false}},
+			},
+			elapsed: 49 * time.Second,
+			want:    containerProbeState{Started: true, Ready: true},
+		},
+		{
+			name: "readiness failed",
+			container: corev1.Container{
+				ReadinessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Readiness: []internalversion.ProbeResult{{AfterMilliseconds: 30000, Success: false}},
+			},
+			elapsed: 50 * time.Second,
+			want:    containerProbeState{Started: true, Ready: false},
+		},
+		{
+			name: "readiness recovered",
+			container: corev1.Container{
+				ReadinessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Readiness: []internalversion.ProbeResult{
+					{AfterMilliseconds: 30000, Success: false},
+					{AfterMilliseconds: 60000, Success: true},
+				},
+			},
+			elapsed: 60 * time.Second,
+			want:    containerProbeState{Started: true, Ready: true},
+		},
+		{
+			name: "short readiness failure is ignored",
+			container: corev1.Container{
+				ReadinessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Readiness: []internalversion.ProbeResult{
+					{AfterMilliseconds: 30000, Success: false},
+					{AfterMilliseconds: 40000, Success: true},
+				},
+			},
+			elapsed: 60 * time.Second,
+			want:    containerProbeState{Started: true, Ready: true},
+		},
+		{
+			name: "not started before startup probe succeeds",
+			container: corev1.Container{
+				StartupProbe:   probe,
+				ReadinessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Startup: []internalversion.ProbeResult{
+					{AfterMilliseconds: 0, Success: false},
+					{AfterMilliseconds: 15000, Success: true},
+				},
+			},
+			elapsed: 10 * time.Second,
+			want:    containerProbeState{Started: false, Ready: false},
+		},
+		{
+			name: "started after startup probe succeeds",
+			container: corev1.Container{
+				StartupProbe:   probe,
+				ReadinessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Startup: []internalversion.ProbeResult{
+					{AfterMilliseconds: 0, Success: false},
+					{AfterMilliseconds: 15000, Success: true},
+				},
+			},
+			elapsed: 15 * time.Second,
+			want:    containerProbeState{Started: true, Ready: true},
+		},
+		{
+			name: "killed by startup probe",
+			container: corev1.Container{
+				StartupProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Startup: []internalversion.ProbeResult{{Success: false}},
+			},
+			elapsed: 20 * time.Second,
+			want:    containerProbeState{KilledBy: probeTypeStartup},
+		},
+		{
+			name: "liveness failing within failure threshold",
+			container: corev1.Container{
+				LivenessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Liveness: []internalversion.ProbeResult{{AfterMilliseconds: 60000, Success: false}},
+			},
+			elapsed: 79 * time.Second,
+			want:    containerProbeState{Started: true, Ready: true},
+		},
+		{
+			name: "killed by liveness probe",
+			container: corev1.Container{
+				LivenessProbe: probe,
+			},
+			probe: internalversion.ProbeContainer{
+				Liveness: []internalversion.ProbeResult{{AfterMilliseconds: 60000, Success: false}},
+			},
+			elapsed: 80 * time.Second,
+			want:    containerProbeState{Started: true, KilledBy: probeTypeLiveness},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateProbes(&tt.container, &tt.probe, tt.elapsed)
+			if got != tt.want {
+				t.Errorf("evaluateProbes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeController(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	clock := clocktesting.NewFakeClock(now)
+
+	newContainerStatus := func(name string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:    name,
+			Ready:   true,
+			Started: new(true),
+			State: corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{
+					StartedAt: metav1.NewTime(now),
+				},
+			},
+		}
+	}
+
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod0",
+				Namespace: "default",
+			},
+			Spec: corev1.PodSpec{
+				NodeName:      "node0",
+				RestartPolicy: corev1.RestartPolicyAlways,
+				Containers: []corev1.Container{
+					{
+						Name: "readiness",
+						ReadinessProbe: &corev1.Probe{
+							PeriodSeconds:    10,
+							FailureThreshold: 1,
+						},
+					},
+					{
+						Name: "liveness",
+						LivenessProbe: &corev1.Probe{
+							PeriodSeconds:    10,
+							FailureThreshold: 1,
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+					{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					newContainerStatus("readiness"),
+					newContainerStatus("liveness"),
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	ctx = log.NewContext(ctx, log.NewLogger(os.Stderr, log.LevelDebug))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	t.Cleanup(cancel)
+
+	podCacheGetter, err := informer.NewInformer[*corev1.Pod, *corev1.PodList](clientset.CoreV1().Pods(corev1.NamespaceAll)).
+		WatchWithSyncedCache(ctx, informer.Option{}, nil, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewProbeController(ProbeControllerConfig{
+		Clock:          clock,
+		TypedClient:    clientset,
+		PodCacheGetter: podCacheGetter,
+		ListNodes: func() []string {
+			return []string{"node0"}
+		},
+		ListPods: func(nodeName string) ([]log.ObjectRef, bool) {
+			return []log.ObjectRef{
+				{Name: "pod0", Namespace: "default"},
+			}, true
+		},
+		SyncInterval: time.Hour,
+		ClusterProbes: resources.NewStaticGetter([]*internalversion.ClusterProbe{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster-probe",
+				},
+				Spec: internalversion.ClusterProbeSpec{
+					Probes: []internalversion.ProbeContainer{
+						{
+							Readiness: []internalversion.ProbeResult{{AfterMilliseconds: 5000, Success: false}},
+							Liveness:  []internalversion.ProbeResult{{AfterMilliseconds: 5000, Success: false}},
+						},
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.syncAll(ctx)
+	ref, ok := c.queue.Get()
+	if !ok || ref != (log.ObjectRef{Name: "pod0", Namespace: "default"}) {
+		t.Fatalf("expected the pod to be scheduled, got %v", ref)
+	}
+	c.syncPod(ctx, ref)
+	pod, err := clientset.CoreV1().Pods("default").Get(ctx, "pod0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pod.Status.ContainerStatuses[0].Ready || pod.Status.ContainerStatuses[1].RestartCount != 0 {
+		t.Fatalf("unexpected status before the probes fail: %+v", pod.Status.ContainerStatuses)
+	}
+
+	// The pod is scheduled by the period of its probes and not again by the sync.
+	c.syncAll(ctx)
+	if _, ok := c.queue.Get(); ok {
+		t.Fatalf("expected the pod to wait for the next period of the probes")
+	}
+	if !c.queue.Cancel(ref) {
+		t.Fatalf("expected the pod to be scheduled after the period of the probes")
+	}
+
+	clock.Step(10 * time.Second)
+	c.syncPod(ctx, ref)
+	pod, err = clientset.CoreV1().Pods("default").Get(ctx, "pod0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readiness := pod.Status.ContainerStatuses[0]
+	if readiness.Ready {
+		t.Errorf("expected the container to be unready")
+	}
+	liveness := pod.Status.ContainerStatuses[1]
+	if liveness.RestartCount != 0 {
+		t.Errorf("expected the container not to be restarted before the back-off, got restart count %d", liveness.RestartCount)
+	}
+	if liveness.LastTerminationState.Terminated == nil || liveness.LastTerminationState.Terminated.ExitCode != probeKilledExitCode {
+		t.Errorf("expected the last state to be terminated, got %+v", liveness.LastTerminationState)
+	}
+	if liveness.State.Waiting == nil || liveness.State.Waiting.Reason != crashLoopBackOffReason {
+		t.Errorf("expected the container to back off, got %+v", liveness.State)
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Status != corev1.ConditionFalse {
+			t.Errorf("expected the condition %s to be false, got %s", cond.Type, cond.Status)
+		}
+	}
+
+	// The container is restarted once the back-off is over.
+	if !c.queue.Cancel(ref) {
+		t.Fatalf("expected the pod to be scheduled after the back-off")
+	}
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		pod, ok := podCacheGetter.GetWithNamespace("pod0", "default")
+		return ok && pod.Status.ContainerStatuses[1].State.Waiting != nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Step(gotpl.CrashLoopBackOff(0))
+	c.syncPod(ctx, ref)
+	pod, err = clientset.CoreV1().Pods("default").Get(ctx, "pod0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	liveness = pod.Status.ContainerStatuses[1]
+	if liveness.RestartCount != 1 {
+		t.Errorf("expected the container to be restarted, got restart count %d", liveness.RestartCount)
+	}
+	if liveness.State.Running == nil || !liveness.State.Running.StartedAt.Time.Equal(clock.Now()) {
+		t.Errorf("expected the container to be running again, got %+v", liveness.State)
+	}
+}
+
+func TestProbePeriod(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers []corev1.Container
+		want       time.Duration
+	}{
+		{
+			name: "no probes",
+			containers: []corev1.Container{
+				{Name: "app"},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "default period",
+			containers: []corev1.Container{
+				{Name: "app", ReadinessProbe: &corev1.Probe{}},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "shortest period",
+			containers: []corev1.Container{
+				{Name: "app", ReadinessProbe: &corev1.Probe{PeriodSeconds: 5}, LivenessProbe: &corev1.Probe{PeriodSeconds: 20}},
+				{Name: "sidecar", StartupProbe: &corev1.Probe{PeriodSeconds: 2}},
+			},
+			want: 2 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: tt.containers}}
+			if got := probePeriod(pod); got != tt.want {
+				t.Errorf("probePeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -49,6 +50,7 @@ import (
 // StageController is a fake resources implementation that can be used to test
 type StageController struct {
 	clock                                 clock.Clock
+	queueClock                            queue.Clock
 	dynamicClient                         dynamic.Interface
 	impersonatingDynamicClient            client.DynamicClientImpersonator
 	restMapper                            meta.RESTMapper
@@ -60,15 +62,20 @@ type StageController struct {
 	preprocessChan                        chan *unstructured.Unstructured
 	playStageParallelism                  uint
 	lifecycle                             resources.Getter[lifecycle.Lifecycle]
+	cacheGetter                           informer.Getter[*unstructured.Unstructured]
+	limiters                              *lifecycle.Limiters
 	delayQueue                            queue.WeightDelayingQueue[resourceStageJob[*unstructured.Unstructured]]
 	backoff                               wait.Backoff
 	delayQueueMapping                     utilsmaps.SyncMap[string, resourceStageJob[*unstructured.Unstructured]]
 	recorder                              record.EventRecorder
+	stageStatus                           *StageStatusController
+	playingJobs                           *JobGroup
 }
 
 // StageControllerConfig is the configuration for the StageController
 type StageControllerConfig struct {
 	Clock                                 clock.Clock
+	QueueClock                            queue.Clock
 	DynamicClient                         dynamic.Interface
 	ImpersonatingDynamicClient            client.DynamicClientImpersonator
 	RESTMapper                            meta.RESTMapper
@@ -77,9 +84,12 @@ type StageControllerConfig struct {
 	DisregardStatusWithAnnotationSelector string
 	DisregardStatusWithLabelSelector      string
 	Lifecycle                             resources.Getter[lifecycle.Lifecycle]
+	CacheGetter                           informer.Getter[*unstructured.Unstructured]
 	PlayStageParallelism                  uint
 	FuncMap                               gotpl.FuncMap
 	Recorder                              record.EventRecorder
+	StageStatus                           *StageStatusController
+	PlayingJobs                           *JobGroup
 }
 
 // NewStageController creates a new fake resources controller
@@ -101,9 +111,13 @@ func NewStageController(conf StageControllerConfig) (*StageController, error) {
 	if conf.Clock == nil {
 		conf.Clock = clock.RealClock{}
 	}
+	if conf.QueueClock == nil {
+		conf.QueueClock = conf.Clock
+	}
 
 	c := &StageController{
 		clock:                                 conf.Clock,
+		queueClock:                            conf.QueueClock,
 		dynamicClient:                         conf.DynamicClient,
 		impersonatingDynamicClient:            conf.ImpersonatingDynamicClient,
 		restMapper:                            conf.RESTMapper,
@@ -111,12 +125,16 @@ func NewStageController(conf StageControllerConfig) (*StageController, error) {
 		gvr:                                   conf.GVR,
 		disregardStatusWithAnnotationSelector: disregardStatusWithAnnotationSelector,
 		disregardStatusWithLabelSelector:      disregardStatusWithLabelSelector,
-		delayQueue:                            queue.NewWeightDelayingQueue[resourceStageJob[*unstructured.Unstructured]](conf.Clock),
+		limiters:                              lifecycle.NewLimiters(),
+		delayQueue:                            queue.NewWeightDelayingQueue[resourceStageJob[*unstructured.Unstructured]](conf.QueueClock),
 		backoff:                               defaultBackoff(),
 		lifecycle:                             conf.Lifecycle,
+		cacheGetter:                           conf.CacheGetter,
 		playStageParallelism:                  conf.PlayStageParallelism,
 		preprocessChan:                        make(chan *unstructured.Unstructured),
 		recorder:                              conf.Recorder,
+		stageStatus:                           conf.StageStatus,
+		playingJobs:                           conf.PlayingJobs,
 	}
 
 	c.renderer = gotpl.NewRenderer(conf.FuncMap)
@@ -202,7 +220,10 @@ func (c *StageController) preprocess(ctx context.Context, resource *unstructured
 	}
 
 	lc := c.lifecycle.Get()
+	c.limiters.Prune(c.lifecycle.Version(), lc)
+	start := c.clock.Now()
 	stage, err := lc.Match(ctx, event)
+	observeStageMatch(stage, resource.GetKind(), c.clock.Since(start))
 	if err != nil {
 		return fmt.Errorf("stage match: %w", err)
 	}
@@ -213,6 +234,8 @@ func (c *StageController) preprocess(ctx context.Context, resource *unstructured
 		return nil
 	}
 
+	c.stageStatus.Matched(stage.Name())
+
 	now := c.clock.Now()
 	delay, _, err := stage.Delay(ctx, event, now)
 	if err != nil {
@@ -236,6 +259,9 @@ func (c *StageController) preprocess(ctx context.Context, resource *unstructured
 		RetryCount: new(uint64),
 		StepIndex:  new(uint64),
 	}
+	if ok {
+		item = continueStageJob(resourceJob, item)
+	}
 
 	// we add a normal(fresh) stage job with weight 0,
 	// resulting in that it will always be processed with high priority compared to those retry ones
@@ -248,12 +274,60 @@ func (c *StageController) playStageWorker(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
 	for ctx.Err() == nil {
+		if !c.playingJobs.WaitResumed(ctx.Done()) {
+			return
+		}
 		resource, ok := c.delayQueue.GetOrWaitWithDone(ctx.Done())
 		if !ok {
 			return
 		}
+		if c.playingJobs.Paused() {
+			// The playing is paused while waiting for the job, put it back to the queue.
+			c.delayQueue.AddWeightAfter(resource, resource.Weight, 0)
+			continue
+		}
+		observeStageDequeued(resource.Stage.Name(), resource.Resource.GetKind())
+
+		now := c.clock.Now()
+		requeue := func(delay time.Duration) bool {
+			if !requeueStageJob(&c.delayQueueMapping, c.delayQueue, resource, c.queueClock.Now(), delay) {
+				return false
+			}
+			observeStageEnqueued(resource.Stage.Name(), resource.Resource.GetKind())
+			return true
+		}
+		if delay, ok := c.limiters.Acquire(resource.Stage, now, func() bool { return requeue(0) }); !ok {
+			// The stage is limited by its rate limit, put it back to the queue
+			// instead of blocking the worker so that the other stages can be played,
+			// or when the stage is released if it is limited by the max in-flight.
+			if delay > 0 {
+				requeue(delay)
+			}
+			continue
+		}
+
+		if !c.playingJobs.Add() {
+			// The controller is being drained, the job is left to the next leader.
+			c.limiters.Release(resource.Stage)
+			return
+		}
 		c.delayQueueMapping.Delete(resource.Key)
-		remainIndex, err := c.playStage(ctx, resource.Resource, resource.Stage, int(*resource.StepIndex))
+		resource = refreshWaitJob(resource, c.getFromCache)
+		// The stage is played to the end even if the context is canceled,
+		// so that the resource is not left half-played for the next leader,
+		// unless the playing is aborted as the resource may be taken over already.
+		playCtx, playCancel := c.playingJobs.Context(ctx)
+		remainIndex, err := c.playStage(playCtx, resource.Resource, resource.Stage, int(*resource.StepIndex))
+		playCancel()
+		c.limiters.Release(resource.Stage)
+		c.playingJobs.Done()
+		var waitErr *lifecycle.WaitError
+		if errors.As(err, &waitErr) {
+			c.waitStageJob(ctx, resource, waitErr, remainIndex, now)
+			continue
+		}
+		observeStagePlay(resource.Stage.Name(), resource.Resource.GetKind(), c.clock.Since(now), err, remainIndex >= 0)
+		c.stageStatus.Played(resource.Stage.Name(), err)
 		if err != nil {
 			logger.Error("failed to apply stage",
 				"err", err,
@@ -272,12 +346,53 @@ func (c *StageController) playStageWorker(ctx context.Context) {
 			)
 			// for failed jobs, we re-push them into the queue with a lower weight
 			// and a backoff period to avoid blocking normal tasks
-			retryDelay := backoffDelayByStep(retryCount, c.backoff)
+			retryDelay := backoffDelayByStep(resource.Key+"/"+resource.Stage.Name(), retryCount, c.backoff)
 			c.addStageJob(ctx, resource, retryDelay, 1)
 		}
 	}
 }
 
+// waitStageJob puts the job back to the queue to wait for the condition of the wait step,
+// or plays the timeout stage if the wait times out.
+func (c *StageController) waitStageJob(ctx context.Context, job resourceStageJob[*unstructured.Unstructured], waitErr *lifecycle.WaitError, stepIndex int, now time.Time) {
+	changed := false
+	if queued, ok := c.delayQueueMapping.Load(job.Key); ok {
+		// The resource has been changed and matched again while playing the stage.
+		if queued.Stage.Name() != job.Stage.Name() {
+			return
+		}
+		// Continue waiting on the step with the latest resource.
+		job.Resource = queued.Resource
+		changed = true
+	}
+
+	// The wait is timed on the queue clock, which is frozen while the simulation is paused.
+	next, delay, err := nextWaitJob(job, waitErr, stepIndex, c.lifecycle.Get(), c.queueClock.Now())
+	if err != nil {
+		observeStagePlay(job.Stage.Name(), job.Resource.GetKind(), c.clock.Since(now), err, false)
+		c.stageStatus.Played(job.Stage.Name(), err)
+		logger := log.FromContext(ctx)
+		logger.Error("failed to wait for stage",
+			"err", err,
+			"resource", job.Key,
+			"stage", job.Stage.Name(),
+		)
+		return
+	}
+	if changed {
+		delay = 0
+	}
+	c.addStageJob(ctx, next, delay, 0)
+}
+
+// getFromCache returns the latest resource from the informer cache.
+func (c *StageController) getFromCache(resource *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	if c.cacheGetter == nil {
+		return nil, false
+	}
+	return c.cacheGetter.GetWithNamespace(resource.GetName(), resource.GetNamespace())
+}
+
 // playStage plays the stage.
 // The returned boolean indicates whether the applying action needs to be retried.
 func (c *StageController) playStage(ctx context.Context, resource *unstructured.Unstructured, stage *lifecycle.Stage, stepIndex int) (int, error) {
@@ -340,7 +455,8 @@ func (c *StageController) playStage(ctx context.Context, resource *unstructured.
 		},
 	)
 	if err != nil {
-		if shouldRetry(err) {
+		var waitErr *lifecycle.WaitError
+		if shouldRetry(err) || errors.As(err, &waitErr) {
 			return remainIndex, err
 		}
 		return -1, err
@@ -483,7 +599,9 @@ loop:
 					key := log.KObj(resource).String()
 					resourceJob, ok := c.delayQueueMapping.LoadAndDelete(key)
 					if ok {
-						c.delayQueue.Cancel(resourceJob)
+						if c.delayQueue.Cancel(resourceJob) {
+							observeStageDequeued(resourceJob.Stage.Name(), resourceJob.Resource.GetKind())
+						}
 					}
 				}
 			}
@@ -494,8 +612,17 @@ loop:
 	logger.Info("Stop watch resources")
 }
 
+// PendingJobs returns the stage jobs of the resources waiting to be played.
+func (c *StageController) PendingJobs() []StageJob {
+	return pendingJobs(&c.delayQueueMapping, func(resource *unstructured.Unstructured) string {
+		return resource.GetKind()
+	})
+}
+
 // addStageJob adds a stage to be applied into the underlying weight delay queue and the associated helper map
 func (c *StageController) addStageJob(ctx context.Context, job resourceStageJob[*unstructured.Unstructured], delay time.Duration, weight int) {
+	job.ScheduledTime = c.queueClock.Now().Add(delay)
+	job.Weight = weight
 	old, loaded := c.delayQueueMapping.Swap(job.Key, job)
 	if loaded {
 		if !c.delayQueue.Cancel(old) {
@@ -503,7 +630,10 @@ func (c *StageController) addStageJob(ctx context.Context, job resourceStageJob[
 			logger.Debug("Failed to cancel stage",
 				"stage", job.Stage.Name(),
 			)
+		} else {
+			observeStageDequeued(old.Stage.Name(), old.Resource.GetKind())
 		}
 	}
 	c.delayQueue.AddWeightAfter(job, weight, delay)
+	observeStageEnqueued(job.Stage.Name(), job.Resource.GetKind())
 }
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
+)
+
+const (
+	stageMetricsNamespace = "kwok"
+	stageMetricsSubsystem = "stage"
+)
+
+var stageMetricsLabels = []string{"stage", "kind"}
+
+var (
+	stageMatchDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: stageMetricsNamespace,
+			Subsystem: stageMetricsSubsystem,
+			Name:      "match_duration_seconds",
+			Help:      "Latency of matching a resource against the stages, the stage label is empty if no stage is matched.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		},
+		stageMetricsLabels,
+	)
+
+	stageDelayQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: stageMetricsNamespace,
+			Subsystem: stageMetricsSubsystem,
+			Name:      "delay_queue_depth",
+			Help:      "Number of jobs of the stage waiting in the delay queue.",
+		},
+		stageMetricsLabels,
+	)
+
+	stagePlayDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: stageMetricsNamespace,
+			Subsystem: stageMetricsSubsystem,
+			Name:      "play_duration_seconds",
+			Help:      "Latency of playing the steps of the stage.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		},
+		stageMetricsLabels,
+	)
+
+	stageRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: stageMetricsNamespace,
+			Subsystem: stageMetricsSubsystem,
+			Name:      "retries_total",
+			Help:      "Number of retries of the stage after a retryable failure.",
+		},
+		stageMetricsLabels,
+	)
+
+	stageFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: stageMetricsNamespace,
+			Subsystem: stageMetricsSubsystem,
+			Name:      "failures_total",
+			Help:      "Number of failures of playing the stage.",
+		},
+		stageMetricsLabels,
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		stageMatchDuration,
+		stageDelayQueueDepth,
+		stagePlayDuration,
+		stageRetriesTotal,
+		stageFailuresTotal,
+	)
+}
+
+// observeStageMatch records the latency of matching a resource against the stages.
+func observeStageMatch(stage *lifecycle.Stage, kind string, d time.Duration) {
+	name := ""
+	if stage != nil {
+		name = stage.Name()
+	}
+	stageMatchDuration.WithLabelValues(name, kind).Observe(d.Seconds())
+}
+
+// observeStageEnqueued records that a job of the stage is added into the delay queue.
+func observeStageEnqueued(stage, kind string) {
+	stageDelayQueueDepth.WithLabelValues(stage, kind).Inc()
+}
+
+// observeStageDequeued records that a job of the stage is removed from the delay queue.
+func observeStageDequeued(stage, kind string) {
+	stageDelayQueueDepth.WithLabelValues(stage, kind).Dec()
+}
+
+// observeStagePlay records the latency and the result of playing the stage.
+func observeStagePlay(stage, kind string, d time.Duration, err error, retry bool) {
+	stagePlayDuration.WithLabelValues(stage, kind).Observe(d.Seconds())
+	if err != nil {
+		stageFailuresTotal.WithLabelValues(stage, kind).Inc()
+	}
+	if retry {
+		stageRetriesTotal.WithLabelValues(stage, kind).Inc()
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStageMetrics(t *testing.T) {
+	const (
+		stage = "test-stage-metrics"
+		kind  = "Test"
+	)
+
+	observeStageEnqueued(stage, kind)
+	observeStageEnqueued(stage, kind)
+	observeStageDequeued(stage, kind)
+	if got := testutil.ToFloat64(stageDelayQueueDepth.WithLabelValues(stage, kind)); got != 1 {
+		t.Errorf("expected delay queue depth 1, got %v", got)
+	}
+
+	observeStagePlay(stage, kind, time.Millisecond, nil, false)
+	observeStagePlay(stage, kind, time.Millisecond, fmt.Errorf("failed"), false)
+	observeStagePlay(stage, kind, time.Millisecond, fmt.Errorf("timeout"), true)
+	if got := testutil.ToFloat64(stageFailuresTotal.WithLabelValues(stage, kind)); got != 2 {
+		t.Errorf("expected failures 2, got %v", got)
+	}
+	if got := testutil.ToFloat64(stageRetriesTotal.WithLabelValues(stage, kind)); got != 1 {
+		t.Errorf("expected retries 1, got %v", got)
+	}
+
+	observeStageMatch(nil, kind, time.Millisecond)
+	if got := testutil.CollectAndCount(stageMatchDuration, "kwok_stage_match_duration_seconds"); got == 0 {
+		t.Errorf("expected match duration to be collected")
+	}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/apis/v1alpha1"
+	"sigs.k8s.io/kwok/pkg/client/clientset/versioned"
+	"sigs.k8s.io/kwok/pkg/config/resources"
+	"sigs.k8s.io/kwok/pkg/log"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
+)
+
+// StageStatusController collects the status of stages while they are played
+// and reports it to the status of the Stage resources periodically.
+// Each replica of kwok reports its own counts keyed by its identity,
+// and the counts of the Stage are the sums of them.
+type StageStatusController struct {
+	clock           clock.Clock
+	typedKwokClient versioned.Interface
+	syncInterval    time.Duration
+	stages          resources.Getter[[]*internalversion.Stage]
+	identity        string
+
+	statuses utilsmaps.SyncMap[string, *stageStatus]
+}
+
+// StageStatusControllerConfig is the configuration for the StageStatusController
+type StageStatusControllerConfig struct {
+	Clock           clock.Clock
+	TypedKwokClient versioned.Interface
+	SyncInterval    time.Duration
+	// Stages is the getter of the stages from the apiserver,
+	// only the status of them is reported.
+	Stages resources.Getter[[]*internalversion.Stage]
+	// Identity is the identity of the replica of kwok that the status is reported for.
+	Identity string
+}
+
+// NewStageStatusController creates a new StageStatusController
+func NewStageStatusController(conf StageStatusControllerConfig) (*StageStatusController, error) {
+	if conf.SyncInterval <= 0 {
+		return nil, fmt.Errorf("sync interval must be greater than 0")
+	}
+	if conf.Stages == nil {
+		return nil, fmt.Errorf("stages getter is required")
+	}
+
+	if conf.Clock == nil {
+		conf.Clock = clock.RealClock{}
+	}
+
+	c := &StageStatusController{
+		clock:           conf.Clock,
+		typedKwokClient: conf.TypedKwokClient,
+		syncInterval:    conf.SyncInterval,
+		stages:          conf.Stages,
+		identity:        conf.Identity,
+	}
+	return c, nil
+}
+
+// Start starts the StageStatusController
+func (c *StageStatusController) Start(ctx context.Context) error {
+	go c.syncWorker(ctx)
+	return nil
+}
+
+// Compiled records the result of compiling the stage.
+func (c *StageStatusController) Compiled(name string, err error) {
+	if c == nil {
+		return
+	}
+	c.get(name).setCompiled(c.clock.Now(), err)
+}
+
+// Matched records that the stage has been matched by a resource.
+func (c *StageStatusController) Matched(name string) {
+	if c == nil {
+		return
+	}
+	c.get(name).addMatched()
+}
+
+// Played records the result of playing the stage.
+func (c *StageStatusController) Played(name string, err error) {
+	if c == nil {
+		return
+	}
+	c.get(name).addPlayed(err)
+}
+
+// Get returns the status of the stage collected so far.
+func (c *StageStatusController) Get(name string) (v1alpha1.StageStatus, bool) {
+	if c == nil {
+		return v1alpha1.StageStatus{}, false
+	}
+	status, ok := c.statuses.Load(name)
+	if !ok {
+		return v1alpha1.StageStatus{}, false
+	}
+	return status.snapshot(), true
+}
+
+func (c *StageStatusController) get(name string) *stageStatus {
+	status, ok := c.statuses.Load(name)
+	if ok {
+		return status
+	}
+	status, _ = c.statuses.LoadOrStore(name, &stageStatus{})
+	return status
+}
+
+func (c *StageStatusController) syncWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(c.syncInterval):
+			c.syncAll(ctx)
+		}
+	}
+}
+
+func (c *StageStatusController) syncAll(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	stages := map[string]struct{}{}
+	for _, stage := range c.stages.Get() {
+		stages[stage.Name] = struct{}{}
+	}
+	c.statuses.Range(func(name string, status *stageStatus) bool {
+		// The stage has been removed from the apiserver.
+		if _, ok := stages[name]; !ok {
+			c.statuses.Delete(name)
+			return true
+		}
+		if !status.takeChanged() {
+			return true
+		}
+
+		snapshot := status.snapshot()
+		if status.synced != nil && reflect.DeepEqual(*status.synced, snapshot) {
+			return true
+		}
+
+		err := c.sync(ctx, name, snapshot)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return true
+			}
+			// Mark it as changed again so that it can be retried in the next sync.
+			status.markChanged()
+			logger.Error("Failed to sync stage status",
+				"err", err,
+				"stage", name,
+			)
+			return ctx.Err() == nil
+		}
+		status.synced = &snapshot
+		return ctx.Err() == nil
+	})
+}
+
+// sync updates the status of the replica in the status of the stage, and the sums of the counts of all the replicas,
+// the update is retried on conflicts so that the status reported by the other replicas is not overwritten.
+func (c *StageStatusController) sync(ctx context.Context, name string, status v1alpha1.StageStatus) error {
+	cli := c.typedKwokClient.KwokV1alpha1().Stages()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		stage, err := cli.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		stage.Status = mergeStageStatus(stage.Status, c.identity, status)
+		_, err = cli.UpdateStatus(ctx, stage, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// mergeStageStatus returns the status of the stage with the status of the replica updated,
+// the counts are the sums of the counts of all the replicas.
+func mergeStageStatus(current v1alpha1.StageStatus, identity string, status v1alpha1.StageStatus) v1alpha1.StageStatus {
+	replica := v1alpha1.StageReplicaStatus{
+		Identity:     identity,
+		MatchedCount: status.MatchedCount,
+		PlayedCount:  status.PlayedCount,
+		FailedCount:  status.FailedCount,
+		LastError:    status.LastError,
+	}
+	replicas := slices.DeleteFunc(slices.Clone(current.Replicas), func(r v1alpha1.StageReplicaStatus) bool {
+		return r.Identity == identity
+	})
+	replicas = append(replicas, replica)
+	slices.SortFunc(replicas, func(a, b v1alpha1.StageReplicaStatus) int {
+		return strings.Compare(a.Identity, b.Identity)
+	})
+
+	merged := v1alpha1.StageStatus{
+		Conditions: current.Conditions,
+		LastError:  current.LastError,
+		Replicas:   replicas,
+	}
+	if status.Conditions != nil {
+		merged.Conditions = status.Conditions
+	}
+	if status.LastError != "" {
+		merged.LastError = status.LastError
+	}
+	for _, r := range replicas {
+		merged.MatchedCount += r.MatchedCount
+		merged.PlayedCount += r.PlayedCount
+		merged.FailedCount += r.FailedCount
+	}
+	return merged
+}
+
+// stageStatus is the status of a stage collected in memory.
+type stageStatus struct {
+	mut sync.Mutex
+
+	compiled *v1alpha1.Condition
+
+	matchedCount int64
+	playedCount  int64
+	failedCount  int64
+	lastError    string
+
+	changed bool
+
+	// synced is the status reported last time, it is only accessed by the sync worker.
+	synced *v1alpha1.StageStatus
+}
+
+func (s *stageStatus) setCompiled(now time.Time, err error) {
+	cond := v1alpha1.Condition{
+		Type:   v1alpha1.StageConditionCompiled,
+		Status: v1alpha1.ConditionTrue,
+		Reason: "Compiled",
+	}
+	if err != nil {
+		cond.Status = v1alpha1.ConditionFalse
+		cond.Reason = "CompileFailed"
+		cond.Message = err.Error()
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.compiled != nil &&
+		s.compiled.Status == cond.Status &&
+		s.compiled.Message == cond.Message {
+		return
+	}
+	cond.LastTransitionTime = metav1.NewTime(now)
+	s.compiled = &cond
+	s.changed = true
+}
+
+func (s *stageStatus) addMatched() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.matchedCount++
+	s.changed = true
+}
+
+func (s *stageStatus) addPlayed(err error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if err != nil {
+		s.failedCount++
+		s.lastError = err.Error()
+	} else {
+		s.playedCount++
+	}
+	s.changed = true
+}
+
+func (s *stageStatus) markChanged() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.changed = true
+}
+
+// takeChanged returns whether the status has changed since the last call and resets it.
+func (s *stageStatus) takeChanged() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	changed := s.changed
+	s.changed = false
+	return changed
+}
+
+// snapshot returns a copy of the status.
+func (s *stageStatus) snapshot() v1alpha1.StageStatus {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	status := v1alpha1.StageStatus{
+		MatchedCount: s.matchedCount,
+		PlayedCount:  s.playedCount,
+		FailedCount:  s.failedCount,
+		LastError:    s.lastError,
+	}
+	if s.compiled != nil {
+		status.Conditions = []v1alpha1.Condition{*s.compiled}
+	}
+	return status
+}
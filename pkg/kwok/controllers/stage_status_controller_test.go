@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/apis/v1alpha1"
+	"sigs.k8s.io/kwok/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/kwok/pkg/config/resources"
+)
+
+func TestStageStatusController(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1alpha1.Stage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "stage1",
+			},
+		},
+	)
+
+	ctx := context.Background()
+	stageStatus, err := NewStageStatusController(StageStatusControllerConfig{
+		Clock:           clocktesting.NewFakeClock(time.Now()),
+		TypedKwokClient: clientset,
+		SyncInterval:    time.Second,
+		Stages: resources.NewStaticGetter([]*internalversion.Stage{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "stage1",
+				},
+			},
+		}),
+		Identity: "kwok-0",
+	})
+	if err != nil {
+		t.Fatal(fmt.Errorf("new stage status controller error: %w", err))
+	}
+
+	stageStatus.Compiled("stage1", nil)
+	stageStatus.Matched("stage1")
+	stageStatus.Matched("stage1")
+	stageStatus.Played("stage1", nil)
+	stageStatus.Played("stage1", fmt.Errorf("play failed"))
+	stageStatus.Compiled("stage2", fmt.Errorf("compile failed"))
+
+	stageStatus.syncAll(ctx)
+
+	stage, err := clientset.KwokV1alpha1().Stages().Get(ctx, "stage1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(fmt.Errorf("get stage error: %w", err))
+	}
+
+	status := stage.Status
+	if status.MatchedCount != 2 {
+		t.Errorf("expected matched count 2, got %d", status.MatchedCount)
+	}
+	if status.PlayedCount != 1 {
+		t.Errorf("expected played count 1, got %d", status.PlayedCount)
+	}
+	if status.FailedCount != 1 {
+		t.Errorf("expected failed count 1, got %d", status.FailedCount)
+	}
+	if status.LastError != "play failed" {
+		t.Errorf("expected last error %q, got %q", "play failed", status.LastError)
+	}
+	if len(status.Conditions) != 1 ||
+		status.Conditions[0].Type != v1alpha1.StageConditionCompiled ||
+		status.Conditions[0].Status != v1alpha1.ConditionTrue {
+		t.Errorf("expected compiled condition, got %v", status.Conditions)
+	}
+
+	if len(status.Replicas) != 1 ||
+		status.Replicas[0].Identity != "kwok-0" ||
+		status.Replicas[0].MatchedCount != 2 {
+		t.Errorf("expected the status of the replica, got %v", status.Replicas)
+	}
+
+	patches := func() int {
+		n := 0
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "update" {
+				n++
+			}
+		}
+		return n
+	}
+	if got := patches(); got != 1 {
+		t.Errorf("expected 1 update, the stage2 not from the apiserver is skipped, got %d", got)
+	}
+	if _, ok := stageStatus.Get("stage2"); ok {
+		t.Errorf("expected status of stage2 to be removed")
+	}
+
+	// Nothing changed, no patch.
+	stageStatus.Compiled("stage1", nil)
+	stageStatus.syncAll(ctx)
+	if got := patches(); got != 1 {
+		t.Errorf("expected no update without changes, got %d updates", got)
+	}
+
+	stageStatus.Matched("stage1")
+	stageStatus.syncAll(ctx)
+	if got := patches(); got != 2 {
+		t.Errorf("expected an update after the counters changed, got %d updates", got)
+	}
+
+	var nilStageStatus *StageStatusController
+	nilStageStatus.Matched("stage1")
+	if _, ok := nilStageStatus.Get("stage1"); ok {
+		t.Errorf("expected no status from nil controller")
+	}
+}
+
+func TestMergeStageStatus(t *testing.T) {
+	current := v1alpha1.StageStatus{
+		MatchedCount: 3,
+		LastError:    "other failed",
+		Replicas: []v1alpha1.StageReplicaStatus{
+			{
+				Identity:     "kwok-1",
+				MatchedCount: 2,
+				FailedCount:  1,
+				LastError:    "other failed",
+			},
+			{
+				Identity:     "kwok-0",
+				MatchedCount: 1,
+			},
+		},
+	}
+
+	got := mergeStageStatus(current, "kwok-0", v1alpha1.StageStatus{
+		MatchedCount: 5,
+		PlayedCount:  4,
+	})
+	want := v1alpha1.StageStatus{
+		MatchedCount: 7,
+		PlayedCount:  4,
+		FailedCount:  1,
+		LastError:    "other failed",
+		Replicas: []v1alpha1.StageReplicaStatus{
+			{
+				Identity:     "kwok-0",
+				MatchedCount: 5,
+				PlayedCount:  4,
+			},
+			{
+				Identity:     "kwok-1",
+				MatchedCount: 2,
+				FailedCount:  1,
+				LastError:    "other failed",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
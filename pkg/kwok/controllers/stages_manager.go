@@ -18,10 +18,17 @@ package controllers
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"sigs.k8s.io/kwok/pkg/apis/internalversion"
 	"sigs.k8s.io/kwok/pkg/config/resources"
 	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/format"
 	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
 	"sigs.k8s.io/kwok/pkg/utils/sets"
 	utilsslices "sigs.k8s.io/kwok/pkg/utils/slices"
@@ -31,13 +38,18 @@ import (
 type StagesManagerConfig struct {
 	StageGetter resources.DynamicGetter[[]*internalversion.Stage]
 	StartFunc   func(ctx context.Context, ref internalversion.StageResourceRef, lifecycle resources.Getter[lifecycle.Lifecycle]) error
+	StageStatus *StageStatusController
+	Lookup      *lifecycle.Lookup
 }
 
 // StagesManager is a stages manager
 // It is a dynamic getter for stages and start a stage controller
 type StagesManager struct {
 	stageGetter resources.DynamicGetter[[]*internalversion.Stage]
+	specGetter  resources.Getter[[]*internalversion.Stage]
 	startFunc   func(ctx context.Context, ref internalversion.StageResourceRef, lifecycle resources.Getter[lifecycle.Lifecycle]) error
+	stageStatus *StageStatusController
+	lookup      *lifecycle.Lookup
 	cache       map[internalversion.StageResourceRef]context.CancelCauseFunc
 }
 
@@ -45,7 +57,12 @@ type StagesManager struct {
 func NewStagesManager(conf StagesManagerConfig) *StagesManager {
 	return &StagesManager{
 		stageGetter: conf.StageGetter,
+		specGetter: &stagesSpecGetter{
+			getter: conf.StageGetter,
+		},
 		startFunc:   conf.StartFunc,
+		stageStatus: conf.StageStatus,
+		lookup:      conf.Lookup,
 		cache:       map[internalversion.StageResourceRef]context.CancelCauseFunc{},
 	}
 }
@@ -84,16 +101,27 @@ func (c *StagesManager) manage(ctx context.Context) {
 			continue
 		}
 
-		lifecycle := resources.NewFilter[lifecycle.Lifecycle, []*internalversion.Stage](c.stageGetter, func(stages []*internalversion.Stage) lifecycle.Lifecycle {
+		lifecycle := resources.NewFilter[lifecycle.Lifecycle, []*internalversion.Stage](c.specGetter, func(stages []*internalversion.Stage) lifecycle.Lifecycle {
 			return utilsslices.FilterAndMap(stages, func(stage *internalversion.Stage) (*lifecycle.Stage, bool) {
 				if stage.Spec.ResourceRef != ref {
 					return nil, false
 				}
 
-				lc := lifecycle.NewStage(stage)
+				lc := lifecycle.NewStageWithLookup(stage, c.lookup)
 				if lc == nil {
 					return nil, false
 				}
+
+				// Compile the stage in advance so that a broken stage does not break the others.
+				err := lc.Compile(stageData(ref))
+				c.stageStatus.Compiled(stage.Name, err)
+				if err != nil {
+					logger.Error("failed to compile stage",
+						"err", err,
+						"stage", stage.Name,
+					)
+					return nil, false
+				}
 				return lc, true
 			})
 		})
@@ -105,6 +133,7 @@ func (c *StagesManager) manage(ctx context.Context) {
 				"err", err,
 				"ref", ref,
 			)
+			cancel(err)
 			continue
 		}
 
@@ -126,3 +155,49 @@ func (c *StagesManager) manage(ctx context.Context) {
 		delete(c.cache, ref)
 	}
 }
+
+// stageData returns an empty object of the resource that the stages of the ref are played on.
+func stageData(ref internalversion.StageResourceRef) any {
+	switch ref {
+	case podRef:
+		return &corev1.Pod{}
+	case nodeRef:
+		return &corev1.Node{}
+	default:
+		return &unstructured.Unstructured{}
+	}
+}
+
+// stagesSpecGetter is a getter of the stages whose version only changes when the spec of the stages changes,
+// so that the lifecycles are not rebuilt when only the status of the stages is updated.
+type stagesSpecGetter struct {
+	getter resources.Getter[[]*internalversion.Stage]
+
+	mut         sync.Mutex
+	version     string
+	specVersion string
+}
+
+func (g *stagesSpecGetter) Get() []*internalversion.Stage {
+	return g.getter.Get()
+}
+
+func (g *stagesSpecGetter) Version() string {
+	version := g.getter.Version()
+
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	if version == g.version && g.specVersion != "" {
+		return g.specVersion
+	}
+
+	// The generation is only increased when the spec changes, as the status is a subresource.
+	keys := []string{}
+	for _, stage := range g.getter.Get() {
+		keys = append(keys, stage.Name+"/"+string(stage.UID)+"/"+format.String(stage.Generation))
+	}
+	slices.Sort(keys)
+	g.version = version
+	g.specVersion = strings.Join(keys, ",")
+	return g.specVersion
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+type fakeVersionedGetter struct {
+	version string
+	stages  []*internalversion.Stage
+}
+
+func (f *fakeVersionedGetter) Get() []*internalversion.Stage {
+	return f.stages
+}
+
+func (f *fakeVersionedGetter) Version() string {
+	return f.version
+}
+
+func TestStagesSpecGetter(t *testing.T) {
+	stage := &internalversion.Stage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "stage1",
+			UID:        "uid1",
+			Generation: 1,
+		},
+	}
+	getter := &fakeVersionedGetter{
+		version: "1",
+		stages:  []*internalversion.Stage{stage},
+	}
+	specGetter := &stagesSpecGetter{
+		getter: getter,
+	}
+
+	version := specGetter.Version()
+
+	// The status is updated.
+	getter.version = "2"
+	if got := specGetter.Version(); got != version {
+		t.Errorf("expected version %q after the status update, got %q", version, got)
+	}
+
+	// The spec is updated.
+	getter.version = "3"
+	stage.Generation = 2
+	if got := specGetter.Version(); got == version {
+		t.Errorf("expected version to change after the spec update, got %q", got)
+	}
+}
@@ -18,11 +18,14 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch/v5"
@@ -32,29 +35,72 @@ import (
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 
+	"sigs.k8s.io/kwok/pkg/utils/format"
 	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
 	utilsnet "sigs.k8s.io/kwok/pkg/utils/net"
+	"sigs.k8s.io/kwok/pkg/utils/queue"
+	utilsrand "sigs.k8s.io/kwok/pkg/utils/rand"
 	"sigs.k8s.io/kwok/pkg/utils/wait"
 )
 
+// errIPPoolExhausted is returned when all the IPs of the pool are in use.
+var errIPPoolExhausted = errors.New("no IP addresses available")
+
+// ipPool allocates the IPs from a CIDR, the IPs are reserved by their owners,
+// the owner is the pod that the IP is allocated to.
 type ipPool struct {
-	mut    sync.Mutex
-	used   map[string]struct{}
+	mut sync.Mutex
+	// used is the IPs in use and their owners.
+	used map[string]string
+	// owners is the owners and their IPs.
+	owners map[string]string
 	usable map[string]struct{}
 	cidr   *net.IPNet
 	index  int
+	size   int
 }
 
 func newIPPool(cidr *net.IPNet) *ipPool {
 	return &ipPool{
-		used:   make(map[string]struct{}),
+		used:   make(map[string]string),
+		owners: make(map[string]string),
 		usable: make(map[string]struct{}),
 		cidr:   cidr,
+		size:   ipPoolSize(cidr),
 	}
 }
 
-func (i *ipPool) new() string {
-	for {
+// ipPoolSize returns the number of the IPs after the IP of the CIDR,
+// the broadcast address of IPv4 is excluded.
+func ipPoolSize(cidr *net.IPNet) int {
+	ones, bits := cidr.Mask.Size()
+	hostBits := bits - ones
+	if hostBits >= 62 {
+		return math.MaxInt
+	}
+
+	ip := cidr.IP.To16()
+	if bits == 32 {
+		ip = cidr.IP.To4()
+	}
+	offset := 0
+	for i, b := range ip {
+		if i < len(cidr.Mask) {
+			b &^= cidr.Mask[i]
+		}
+		offset = offset<<8 | int(b)
+	}
+
+	last := 1<<hostBits - 1
+	if bits == 32 && hostBits >= 2 {
+		last--
+	}
+	return max(last-offset, 0)
+}
+
+func (i *ipPool) new() (string, error) {
+	for i.index < i.size {
 		i.index++
 		ip := utilsnet.AddIP(i.cidr.IP, i.index).String()
 
@@ -62,15 +108,32 @@ func (i *ipPool) new() string {
 			continue
 		}
 
-		i.used[ip] = struct{}{}
 		i.usable[ip] = struct{}{}
-		return ip
+		return ip, nil
 	}
+	return "", fmt.Errorf("%w in range set: %s", errIPPoolExhausted, i.ipRange())
 }
 
-func (i *ipPool) Get() string {
+// ipRange returns the range of the IPs of the pool.
+func (i *ipPool) ipRange() string {
+	first := utilsnet.AddIP(i.cidr.IP, 1)
+	if i.size == 0 {
+		return first.String()
+	}
+	return first.String() + "-" + utilsnet.AddIP(i.cidr.IP, i.size).String()
+}
+
+// Get returns the IP reserved for the owner, or reserves a new IP for it.
+// A new IP is returned each time if the owner is empty.
+func (i *ipPool) Get(owner string) (string, error) {
 	i.mut.Lock()
 	defer i.mut.Unlock()
+	if owner != "" {
+		if ip, ok := i.owners[owner]; ok {
+			return ip, nil
+		}
+	}
+
 	ip := ""
 	if len(i.usable) != 0 {
 		for s := range i.usable {
@@ -79,30 +142,71 @@ func (i *ipPool) Get() string {
 		}
 	}
 	if ip == "" {
-		ip = i.new()
+		var err error
+		ip, err = i.new()
+		if err != nil {
+			return "", err
+		}
 	}
 	delete(i.usable, ip)
-	i.used[ip] = struct{}{}
-	return ip
+	i.used[ip] = owner
+	if owner != "" {
+		i.owners[owner] = ip
+	}
+	return ip, nil
 }
 
+// Put releases the IP.
 func (i *ipPool) Put(ip string) {
 	i.mut.Lock()
 	defer i.mut.Unlock()
 	if !i.cidr.Contains(net.ParseIP(ip)) {
 		return
 	}
-	delete(i.used, ip)
+	i.put(ip)
+}
+
+// Release releases the IP reserved for the owner.
+func (i *ipPool) Release(owner string) {
+	i.mut.Lock()
+	defer i.mut.Unlock()
+	ip, ok := i.owners[owner]
+	if !ok {
+		return
+	}
+	i.put(ip)
+}
+
+func (i *ipPool) put(ip string) {
+	if owner, ok := i.used[ip]; ok {
+		if owner != "" && i.owners[owner] == ip {
+			delete(i.owners, owner)
+		}
+		delete(i.used, ip)
+	}
 	i.usable[ip] = struct{}{}
 }
 
-func (i *ipPool) Use(ip string) {
+// Use reserves the IP for the owner, it restores the IPs already in use.
+// It returns false if the IP is reserved for another owner, which keeps the IP.
+func (i *ipPool) Use(ip string, owner string) bool {
 	i.mut.Lock()
 	defer i.mut.Unlock()
 	if !i.cidr.Contains(net.ParseIP(ip)) {
-		return
+		return true
+	}
+	if prev, ok := i.used[ip]; ok && prev != "" && prev != owner && i.owners[prev] == ip {
+		return false
 	}
-	i.used[ip] = struct{}{}
+	if old, ok := i.owners[owner]; ok && old != ip {
+		i.put(old)
+	}
+	delete(i.usable, ip)
+	i.used[ip] = owner
+	if owner != "" {
+		i.owners[owner] = ip
+	}
+	return true
 }
 
 func labelsParse(selector string) (labels.Selector, error) {
@@ -122,6 +226,270 @@ type resourceStageJob[T any] struct {
 	// StepIndex is used to record what has been executed.
 	// Must be initialized to 0.
 	StepIndex *uint64
+	// WaitSince is the time when the job starts waiting on the wait step.
+	WaitSince time.Time
+	// ScheduledTime is the time when the job is scheduled to be played, in the time of the delay queue.
+	ScheduledTime time.Time
+	// Weight is the weight of the job in the delay queue,
+	// it is kept when the job is put back to the queue.
+	Weight int
+}
+
+// StageJob is a stage job waiting to be played.
+type StageJob struct {
+	Kind          string    `json:"kind"`
+	Key           string    `json:"key"`
+	Stage         string    `json:"stage"`
+	ScheduledTime time.Time `json:"scheduledTime"`
+}
+
+// requeueStageJob puts the job dequeued back to the delay queue after the delay,
+// and updates its scheduled time in the mapping so that the pending jobs are reported correctly.
+// The job is dropped if it is replaced in the mapping meanwhile, as the new one is in the queue already.
+func requeueStageJob[T comparable](mapping *utilsmaps.SyncMap[string, resourceStageJob[T]], delayQueue queue.WeightDelayingQueue[resourceStageJob[T]], job resourceStageJob[T], now time.Time, delay time.Duration) bool {
+	requeued := job
+	requeued.ScheduledTime = now.Add(delay)
+	if !mapping.CompareAndSwap(job.Key, job, requeued) {
+		return false
+	}
+	delayQueue.AddWeightAfter(requeued, requeued.Weight, delay)
+	return true
+}
+
+// pendingJobs returns the stage jobs in the mapping of the delay queue.
+func pendingJobs[T any](mapping *utilsmaps.SyncMap[string, resourceStageJob[T]], kind func(resource T) string) []StageJob {
+	jobs := []StageJob{}
+	mapping.Range(func(key string, job resourceStageJob[T]) bool {
+		jobs = append(jobs, StageJob{
+			Kind:          kind(job.Resource),
+			Key:           key,
+			Stage:         job.Stage.Name(),
+			ScheduledTime: job.ScheduledTime,
+		})
+		return true
+	})
+	return jobs
+}
+
+// JobGroup tracks the stage jobs being played,
+// so that they can be drained before another replica of kwok takes over the resources,
+// and pauses the playing of the stage jobs.
+type JobGroup struct {
+	mut     sync.Mutex
+	wg      sync.WaitGroup
+	drained bool
+	// resumed is closed when the group is resumed, it is nil if the group is not paused.
+	resumed chan struct{}
+	// abortCtx is canceled when the jobs being played are aborted.
+	abortCtx context.Context
+	abort    context.CancelFunc
+}
+
+// NewJobGroup returns a new JobGroup
+func NewJobGroup() *JobGroup {
+	abortCtx, abort := context.WithCancel(context.Background())
+	return &JobGroup{
+		abortCtx: abortCtx,
+		abort:    abort,
+	}
+}
+
+// Add adds a job being played, it returns false if the group is drained.
+func (g *JobGroup) Add() bool {
+	if g == nil {
+		return true
+	}
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	if g.drained {
+		return false
+	}
+	g.wg.Add(1)
+	return true
+}
+
+// Done marks a job as finished
+func (g *JobGroup) Done() {
+	if g == nil {
+		return
+	}
+	g.wg.Done()
+}
+
+// Context returns the context to play a job with, which is not canceled with the ctx
+// so that the job is played to the end while draining, but is canceled when the jobs are aborted.
+func (g *JobGroup) Context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if g == nil {
+		return ctx, cancel
+	}
+	stop := context.AfterFunc(g.abortCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Drain stops accepting new jobs and waits for the jobs being played to finish,
+// the jobs still being played are aborted when the ctx is done.
+func (g *JobGroup) Drain(ctx context.Context) {
+	if g == nil {
+		return
+	}
+	g.mut.Lock()
+	g.drained = true
+	g.mut.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return
+	case <-ctx.Done():
+	}
+	g.abort()
+	<-finished
+}
+
+// Abort stops accepting new jobs, cancels the jobs being played and waits for them to return.
+func (g *JobGroup) Abort() {
+	if g == nil {
+		return
+	}
+	g.mut.Lock()
+	g.drained = true
+	g.mut.Unlock()
+	g.abort()
+	g.wg.Wait()
+}
+
+// Pause pauses the playing of the stage jobs, the jobs being played are not interrupted.
+func (g *JobGroup) Pause() {
+	if g == nil {
+		return
+	}
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	if g.resumed == nil {
+		g.resumed = make(chan struct{})
+	}
+}
+
+// Resume resumes the playing of the stage jobs.
+func (g *JobGroup) Resume() {
+	if g == nil {
+		return
+	}
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	if g.resumed != nil {
+		close(g.resumed)
+		g.resumed = nil
+	}
+}
+
+// Paused returns true if the playing of the stage jobs is paused.
+func (g *JobGroup) Paused() bool {
+	if g == nil {
+		return false
+	}
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	return g.resumed != nil
+}
+
+// WaitResumed blocks while the group is paused, it returns false if the done is closed first.
+func (g *JobGroup) WaitResumed(done <-chan struct{}) bool {
+	if g == nil {
+		return true
+	}
+	g.mut.Lock()
+	resumed := g.resumed
+	g.mut.Unlock()
+	if resumed == nil {
+		return true
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// stageWaitPollInterval is the interval to check the condition of a wait step again.
+const stageWaitPollInterval = time.Second
+
+// nextWaitJob returns the job to continue after the condition of the wait step at the stepIndex is not satisfied,
+// which waits on the step again, or plays the timeout stage if the wait times out.
+// It returns an error if the wait times out and there is no timeout stage to play.
+func nextWaitJob[T any](job resourceStageJob[T], waitErr *lifecycle.WaitError, stepIndex int, lc lifecycle.Lifecycle, now time.Time) (resourceStageJob[T], time.Duration, error) {
+	if job.WaitSince.IsZero() || atomic.LoadUint64(job.StepIndex) != uint64(stepIndex) {
+		job.WaitSince = now
+	}
+
+	timeout := waitErr.Timeout()
+	if timeout == 0 {
+		atomic.StoreUint64(job.StepIndex, uint64(stepIndex))
+		return job, stageWaitPollInterval, nil
+	}
+
+	if remaining := timeout - now.Sub(job.WaitSince); remaining > 0 {
+		atomic.StoreUint64(job.StepIndex, uint64(stepIndex))
+		return job, min(remaining, stageWaitPollInterval), nil
+	}
+
+	stageName := waitErr.Wait.TimeoutStage
+	if stageName == "" {
+		return job, 0, fmt.Errorf("wait timed out after %s", timeout)
+	}
+
+	stage := lc.StageByName(stageName)
+	if stage == nil {
+		return job, 0, fmt.Errorf("wait timed out after %s, timeout stage %q not found", timeout, stageName)
+	}
+	err := stage.Compile(job.Resource)
+	if err != nil {
+		return job, 0, fmt.Errorf("wait timed out after %s, failed to compile timeout stage %q: %w", timeout, stageName, err)
+	}
+
+	next := resourceStageJob[T]{
+		Resource:   job.Resource,
+		Stage:      stage,
+		Key:        job.Key,
+		RetryCount: new(uint64),
+		StepIndex:  new(uint64),
+	}
+	return next, 0, nil
+}
+
+// continueStageJob returns the job of the resource matched again, which continues from the step of the queued job
+// if both are of the same stage, so that the played steps are not played again and the wait step keeps its timeout.
+func continueStageJob[T any](queued, job resourceStageJob[T]) resourceStageJob[T] {
+	if queued.Stage.Name() != job.Stage.Name() {
+		return job
+	}
+	job.StepIndex = new(atomic.LoadUint64(queued.StepIndex))
+	job.WaitSince = queued.WaitSince
+	return job
+}
+
+// refreshWaitJob returns the job with the latest resource from the cache if the job waits on a wait step,
+// so that the condition is checked on the resource as it is now instead of as it was matched.
+// The job is returned as is if the resource is not in the cache.
+func refreshWaitJob[T any](job resourceStageJob[T], getFromCache func(T) (T, bool)) resourceStageJob[T] {
+	if job.WaitSince.IsZero() {
+		return job
+	}
+	latest, ok := getFromCache(job.Resource)
+	if !ok {
+		return job
+	}
+	job.Resource = latest
+	return job
 }
 
 // defaultBackoff provides a backoff setting for kwok controllers to apply failed jobs
@@ -129,12 +497,13 @@ func defaultBackoff() wait.Backoff {
 	return wait.Backoff{Duration: 1 * time.Second, Factor: 2.0, Jitter: 0.2, Cap: 32 * time.Minute}
 }
 
-// backoffDelayByStep calculates the backoff delay period based on steps
-func backoffDelayByStep(steps uint64, c wait.Backoff) time.Duration {
+// backoffDelayByStep calculates the backoff delay period based on steps,
+// the jitter is drawn from the random source of the key.
+func backoffDelayByStep(key string, steps uint64, c wait.Backoff) time.Duration {
 	delay := math.Min(
 		float64(c.Duration)*math.Pow(c.Factor, float64(steps)),
 		float64(c.Cap))
-	return wait.Jitter(time.Duration(delay), c.Jitter)
+	return utilsrand.Jitter(key+"/"+format.String(steps), time.Duration(delay), c.Jitter)
 }
 
 // shouldRetry determines if a certain error needs to be retried
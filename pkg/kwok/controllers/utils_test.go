@@ -17,14 +17,22 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"net"
 	"syscall"
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
 	utilsnet "sigs.k8s.io/kwok/pkg/utils/net"
 )
 
@@ -63,13 +71,87 @@ func Test_ipPool_new(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pool := newIPPool(tt.fields.cidr)
-			if got := pool.new(); got != tt.want {
+			got, err := pool.new()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
 				t.Errorf("new() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func Test_ipPoolSize(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want int
+	}{
+		{cidr: "10.0.0.0/24", want: 254},
+		{cidr: "10.0.0.1/24", want: 253},
+		{cidr: "10.0.0.0/30", want: 2},
+		{cidr: "10.0.0.0/32", want: 0},
+		{cidr: "fd00::/120", want: 255},
+		{cidr: "fd00::/64", want: math.MaxInt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			cidr, err := utilsnet.ParseCIDR(tt.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ipPoolSize(cidr); got != tt.want {
+				t.Errorf("ipPoolSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ipPool(t *testing.T) {
+	cidr, _ := utilsnet.ParseCIDR("10.0.0.0/30")
+	pool := newIPPool(cidr)
+
+	ip1, err := pool.Get("pod1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := pool.Get("pod1"); got != ip1 {
+		t.Errorf("Get() = %v, want the reserved %v", got, ip1)
+	}
+
+	if pool.Use(ip1, "pod2") {
+		t.Errorf("Use() = true, want the IP %v reserved for pod1 kept", ip1)
+	}
+	if !pool.Use("10.0.0.2", "pod2") {
+		t.Errorf("Use() = false, want the IP %v reserved for pod2", "10.0.0.2")
+	}
+	_, err = pool.Get("pod3")
+	if !errors.Is(err, errIPPoolExhausted) {
+		t.Fatalf("Get() error = %v, want %v", err, errIPPoolExhausted)
+	}
+	if want := "no IP addresses available in range set: 10.0.0.1-10.0.0.2"; err.Error() != want {
+		t.Errorf("Get() error = %q, want %q", err.Error(), want)
+	}
+
+	pool.Release("pod1")
+	ip3, err := pool.Get("pod3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip3 != ip1 {
+		t.Errorf("Get() = %v, want the released %v", ip3, ip1)
+	}
+
+	pool.Put("10.0.0.2")
+	ip4, err := pool.Get("pod4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip4 != "10.0.0.2" {
+		t.Errorf("Get() = %v, want the released %v", ip4, "10.0.0.2")
+	}
+}
+
 func genResource(resource string) schema.GroupResource {
 	return schema.GroupResource{Group: "", Resource: resource}
 }
@@ -120,3 +202,285 @@ func Test_shouldRetry(t *testing.T) {
 		})
 	}
 }
+
+func Test_nextWaitJob(t *testing.T) {
+	lc, err := lifecycle.NewLifecycle([]*internalversion.Stage{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-wait",
+			},
+			Spec: internalversion.StageSpec{
+				Selector: &internalversion.StageSelector{},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-failed",
+			},
+			Spec: internalversion.StageSpec{
+				Selector: &internalversion.StageSelector{},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitErr := &lifecycle.WaitError{
+		Wait: &internalversion.StageWait{
+			TimeoutMilliseconds: new(int64(1500)),
+			TimeoutStage:        "pod-failed",
+		},
+	}
+
+	now := time.Now()
+	job := resourceStageJob[*corev1.Pod]{
+		Resource:   &corev1.Pod{},
+		Stage:      lc.StageByName("pod-wait"),
+		Key:        "default/pod",
+		RetryCount: new(uint64),
+		StepIndex:  new(uint64),
+	}
+
+	job, delay, err := nextWaitJob(job, waitErr, 1, lc, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Stage.Name() != "pod-wait" || *job.StepIndex != 1 || !job.WaitSince.Equal(now) {
+		t.Fatalf("unexpected job %s at step %d since %s", job.Stage.Name(), *job.StepIndex, job.WaitSince)
+	}
+	if delay != stageWaitPollInterval {
+		t.Errorf("expected delay %s, got %s", stageWaitPollInterval, delay)
+	}
+
+	job, delay, err = nextWaitJob(job, waitErr, 1, lc, now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !job.WaitSince.Equal(now) {
+		t.Errorf("expected wait since %s, got %s", now, job.WaitSince)
+	}
+	if delay != 500*time.Millisecond {
+		t.Errorf("expected delay %s, got %s", 500*time.Millisecond, delay)
+	}
+
+	job, _, err = nextWaitJob(job, waitErr, 1, lc, now.Add(2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Stage.Name() != "pod-failed" || *job.StepIndex != 0 {
+		t.Errorf("expected timeout stage, got %s at step %d", job.Stage.Name(), *job.StepIndex)
+	}
+
+	waitErr.Wait.TimeoutStage = ""
+	job = resourceStageJob[*corev1.Pod]{
+		Resource:   &corev1.Pod{},
+		Stage:      lc.StageByName("pod-wait"),
+		Key:        "default/pod",
+		RetryCount: new(uint64),
+		StepIndex:  new(uint64(1)),
+		WaitSince:  now,
+	}
+	_, _, err = nextWaitJob(job, waitErr, 1, lc, now.Add(2*time.Second))
+	if err == nil {
+		t.Errorf("expected error if there is no timeout stage")
+	}
+}
+
+func Test_continueStageJob(t *testing.T) {
+	lc, err := lifecycle.NewLifecycle([]*internalversion.Stage{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-wait",
+			},
+			Spec: internalversion.StageSpec{
+				Selector: &internalversion.StageSelector{},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-ready",
+			},
+			Spec: internalversion.StageSpec{
+				Selector: &internalversion.StageSelector{},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	queued := resourceStageJob[*corev1.Pod]{
+		Resource:   &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"}},
+		Stage:      lc.StageByName("pod-wait"),
+		Key:        "default/pod",
+		RetryCount: new(uint64),
+		StepIndex:  new(uint64(2)),
+		WaitSince:  now,
+	}
+	latest := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "2"}}
+
+	job := continueStageJob(queued, resourceStageJob[*corev1.Pod]{
+		Resource:   latest,
+		Stage:      lc.StageByName("pod-wait"),
+		Key:        "default/pod",
+		RetryCount: new(uint64),
+		StepIndex:  new(uint64),
+	})
+	if *job.StepIndex != 2 || !job.WaitSince.Equal(now) || job.Resource != latest {
+		t.Errorf("expected to continue at step 2 since %s with the latest pod, got step %d since %s", now, *job.StepIndex, job.WaitSince)
+	}
+	if job.StepIndex == queued.StepIndex {
+		t.Errorf("expected the step index not to be shared with the queued job")
+	}
+
+	job = continueStageJob(queued, resourceStageJob[*corev1.Pod]{
+		Resource:   latest,
+		Stage:      lc.StageByName("pod-ready"),
+		Key:        "default/pod",
+		RetryCount: new(uint64),
+		StepIndex:  new(uint64),
+	})
+	if *job.StepIndex != 0 || !job.WaitSince.IsZero() {
+		t.Errorf("expected to start from the first step of another stage, got step %d since %s", *job.StepIndex, job.WaitSince)
+	}
+
+	getFromCache := func(*corev1.Pod) (*corev1.Pod, bool) {
+		return latest, true
+	}
+	if job := refreshWaitJob(queued, getFromCache); job.Resource != latest {
+		t.Errorf("expected the waiting job to be refreshed with the latest pod")
+	}
+	queued.WaitSince = time.Time{}
+	if job := refreshWaitJob(queued, getFromCache); job.Resource == latest {
+		t.Errorf("expected the job not waiting to be kept")
+	}
+}
+
+func TestJobGroup(t *testing.T) {
+	g := NewJobGroup()
+	if !g.Add() {
+		t.Fatal("expected the job to be added")
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		g.Drain(context.Background())
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("expected the drain to wait for the job being played")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	g.Done()
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("expected the drain to finish after the job is done")
+	}
+
+	if g.Add() {
+		t.Error("expected no more jobs to be added after the drain")
+	}
+
+	var nilGroup *JobGroup
+	if !nilGroup.Add() {
+		t.Error("expected the nil group to accept jobs")
+	}
+	nilGroup.Done()
+	nilGroup.Drain(context.Background())
+	nilGroup.Abort()
+}
+
+func TestJobGroupDrainTimeout(t *testing.T) {
+	g := NewJobGroup()
+	if !g.Add() {
+		t.Fatal("expected the job to be added")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	playCtx, playCancel := g.Context(ctx)
+	defer playCancel()
+	go func() {
+		// The job returns once it is aborted.
+		<-playCtx.Done()
+		g.Done()
+	}()
+
+	cancel()
+	if playCtx.Err() != nil {
+		t.Fatal("expected the job not to be canceled with the context")
+	}
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer drainCancel()
+	drained := make(chan struct{})
+	go func() {
+		g.Drain(drainCtx)
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("expected the drain to abort the job after the timeout")
+	}
+	if playCtx.Err() == nil {
+		t.Error("expected the job to be aborted")
+	}
+	if g.Add() {
+		t.Error("expected no more jobs to be added after the drain")
+	}
+}
+
+func TestJobGroupPause(t *testing.T) {
+	g := NewJobGroup()
+	done := make(chan struct{})
+	if !g.WaitResumed(done) {
+		t.Fatal("expected not to wait when not paused")
+	}
+
+	g.Pause()
+	g.Pause()
+	if !g.Paused() {
+		t.Fatal("expected the group to be paused")
+	}
+
+	resumed := make(chan bool)
+	go func() {
+		resumed <- g.WaitResumed(done)
+	}()
+	select {
+	case <-resumed:
+		t.Fatal("expected to wait while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	g.Resume()
+	select {
+	case ok := <-resumed:
+		if !ok {
+			t.Error("expected to be resumed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the wait to finish after resumed")
+	}
+	if g.Paused() {
+		t.Error("expected the group not to be paused")
+	}
+
+	g.Pause()
+	close(done)
+	if g.WaitResumed(done) {
+		t.Error("expected the wait to be canceled by done")
+	}
+
+	var nilGroup *JobGroup
+	nilGroup.Pause()
+	if nilGroup.Paused() || !nilGroup.WaitResumed(done) {
+		t.Error("expected the nil group not to be paused")
+	}
+	nilGroup.Resume()
+}
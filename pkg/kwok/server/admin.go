@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+// Admin is the interface that introspects and controls the controller for the admin API.
+type Admin interface {
+	ListNodes() []string
+	ListPods(nodeName string) ([]log.ObjectRef, bool)
+	PendingJobs() []AdminJob
+	ListStages() []*internalversion.Stage
+	Pause()
+	Resume(shiftDelays bool)
+	Paused() bool
+}
+
+// AdminJob is a stage job waiting to be played.
+type AdminJob struct {
+	Kind          string    `json:"kind"`
+	Key           string    `json:"key"`
+	Stage         string    `json:"stage"`
+	ScheduledTime time.Time `json:"scheduledTime"`
+}
+
+// adminStage is a stage in use.
+type adminStage struct {
+	Name            string `json:"name"`
+	APIGroup        string `json:"apiGroup"`
+	Kind            string `json:"kind"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Generation      int64  `json:"generation,omitempty"`
+}
+
+// The ways to handle the delays of the pending jobs when resuming.
+const (
+	// adminResumeDelaysPreserve keeps the scheduled time of the jobs, the ones due while paused are played immediately.
+	adminResumeDelaysPreserve = "preserve"
+	// adminResumeDelaysShift shifts the scheduled time of the jobs by the paused duration.
+	adminResumeDelaysShift = "shift"
+)
+
+// adminStatus is the status of the controller.
+type adminStatus struct {
+	Paused bool `json:"paused"`
+}
+
+// InstallAdmin installs the handlers of the admin API, which are authenticated by the bearer token.
+func (s *Server) InstallAdmin(token string) {
+	ws := new(restful.WebService)
+	ws.
+		Path("/admin").
+		Produces(restful.MIME_JSON).
+		Filter(adminAuthFilter(token))
+	ws.Route(ws.GET("/nodes").
+		To(s.adminListNodes).
+		Operation("adminListNodes"))
+	ws.Route(ws.GET("/nodes/{nodeName}/pods").
+		To(s.adminListPods).
+		Operation("adminListPods"))
+	ws.Route(ws.GET("/jobs").
+		To(s.adminListJobs).
+		Operation("adminListJobs"))
+	ws.Route(ws.GET("/stages").
+		To(s.adminListStages).
+		Operation("adminListStages"))
+	ws.Route(ws.GET("/status").
+		To(s.adminGetStatus).
+		Operation("adminGetStatus"))
+	ws.Route(ws.POST("/pause").
+		To(s.adminPause).
+		Operation("adminPause"))
+	ws.Route(ws.POST("/resume").
+		To(s.adminResume).
+		Param(ws.QueryParameter("delays", "How to handle the delays of the pending jobs, preserve or shift").DefaultValue(adminResumeDelaysPreserve)).
+		Operation("adminResume"))
+	if s.nodePartitioner != nil {
+		s.installNodePartitions(ws)
+	}
+	s.restfulCont.Add(ws)
+}
+
+func adminAuthFilter(token string) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		got, ok := strings.CutPrefix(req.HeaderParameter("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(resp, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+func writeJSON(resp *restful.Response, obj any) {
+	resp.Header().Set("Content-Type", restful.MIME_JSON)
+	err := json.NewEncoder(resp).Encode(obj)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) adminListNodes(req *restful.Request, resp *restful.Response) {
+	nodes := s.admin.ListNodes()
+	if nodes == nil {
+		nodes = []string{}
+	}
+	writeJSON(resp, nodes)
+}
+
+func (s *Server) adminListPods(req *restful.Request, resp *restful.Response) {
+	nodeName := req.PathParameter("nodeName")
+	pods, ok := s.admin.ListPods(nodeName)
+	if !ok {
+		http.Error(resp, "node "+nodeName+" is not managed", http.StatusNotFound)
+		return
+	}
+	writeJSON(resp, pods)
+}
+
+func (s *Server) adminListJobs(req *restful.Request, resp *restful.Response) {
+	writeJSON(resp, s.admin.PendingJobs())
+}
+
+func (s *Server) adminListStages(req *restful.Request, resp *restful.Response) {
+	stages := []adminStage{}
+	for _, stage := range s.admin.ListStages() {
+		stages = append(stages, adminStage{
+			Name:            stage.Name,
+			APIGroup:        stage.Spec.ResourceRef.APIGroup,
+			Kind:            stage.Spec.ResourceRef.Kind,
+			ResourceVersion: stage.ResourceVersion,
+			Generation:      stage.Generation,
+		})
+	}
+	writeJSON(resp, stages)
+}
+
+func (s *Server) adminGetStatus(req *restful.Request, resp *restful.Response) {
+	writeJSON(resp, adminStatus{
+		Paused: s.admin.Paused(),
+	})
+}
+
+func (s *Server) adminPause(req *restful.Request, resp *restful.Response) {
+	s.admin.Pause()
+	s.adminGetStatus(req, resp)
+}
+
+func (s *Server) adminResume(req *restful.Request, resp *restful.Response) {
+	switch delays := req.QueryParameter("delays"); delays {
+	case "", adminResumeDelaysPreserve:
+		s.admin.Resume(false)
+	case adminResumeDelaysShift:
+		s.admin.Resume(true)
+	default:
+		http.Error(resp, "unknown delays "+delays+", must be "+adminResumeDelaysPreserve+" or "+adminResumeDelaysShift, http.StatusBadRequest)
+		return
+	}
+	s.adminGetStatus(req, resp)
+}
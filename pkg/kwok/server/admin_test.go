@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+type fakeAdmin struct {
+	paused      bool
+	shiftDelays bool
+}
+
+func (f *fakeAdmin) ListNodes() []string {
+	return []string{"node0"}
+}
+
+func (f *fakeAdmin) ListPods(nodeName string) ([]log.ObjectRef, bool) {
+	if nodeName != "node0" {
+		return nil, false
+	}
+	return []log.ObjectRef{{Name: "pod0", Namespace: "default"}}, true
+}
+
+func (f *fakeAdmin) PendingJobs() []AdminJob {
+	return []AdminJob{
+		{
+			Kind:          "Pod",
+			Key:           "default/pod0",
+			Stage:         "pod-ready",
+			ScheduledTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func (f *fakeAdmin) ListStages() []*internalversion.Stage {
+	return []*internalversion.Stage{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "pod-ready",
+				ResourceVersion: "10",
+				Generation:      2,
+			},
+			Spec: internalversion.StageSpec{
+				ResourceRef: internalversion.StageResourceRef{
+					APIGroup: "v1",
+					Kind:     "Pod",
+				},
+			},
+		},
+	}
+}
+
+func (f *fakeAdmin) Pause() {
+	f.paused = true
+}
+
+func (f *fakeAdmin) Resume(shiftDelays bool) {
+	f.paused = false
+	f.shiftDelays = shiftDelays
+}
+
+func (f *fakeAdmin) Paused() bool {
+	return f.paused
+}
+
+func TestAdmin(t *testing.T) {
+	admin := &fakeAdmin{}
+	svc, err := NewServer(Config{
+		Admin: admin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.InstallAdmin("secret")
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "without token",
+			method:   http.MethodGet,
+			path:     "/admin/nodes",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `Unauthorized`,
+		},
+		{
+			name:     "with wrong token",
+			method:   http.MethodGet,
+			path:     "/admin/nodes",
+			token:    "wrong",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `Unauthorized`,
+		},
+		{
+			name:     "list nodes",
+			method:   http.MethodGet,
+			path:     "/admin/nodes",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `["node0"]`,
+		},
+		{
+			name:     "list pods",
+			method:   http.MethodGet,
+			path:     "/admin/nodes/node0/pods",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `[{"name":"pod0","namespace":"default"}]`,
+		},
+		{
+			name:     "list pods of unmanaged node",
+			method:   http.MethodGet,
+			path:     "/admin/nodes/node1/pods",
+			token:    "secret",
+			wantCode: http.StatusNotFound,
+			wantBody: `node node1 is not managed`,
+		},
+		{
+			name:     "list jobs",
+			method:   http.MethodGet,
+			path:     "/admin/jobs",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `[{"kind":"Pod","key":"default/pod0","stage":"pod-ready","scheduledTime":"2024-01-01T00:00:00Z"}]`,
+		},
+		{
+			name:     "list stages",
+			method:   http.MethodGet,
+			path:     "/admin/stages",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `[{"name":"pod-ready","apiGroup":"v1","kind":"Pod","resourceVersion":"10","generation":2}]`,
+		},
+		{
+			name:     "pause",
+			method:   http.MethodPost,
+			path:     "/admin/pause",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `{"paused":true}`,
+		},
+		{
+			name:     "status",
+			method:   http.MethodGet,
+			path:     "/admin/status",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `{"paused":true}`,
+		},
+		{
+			name:     "resume",
+			method:   http.MethodPost,
+			path:     "/admin/resume",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `{"paused":false}`,
+		},
+		{
+			name:     "pause again",
+			method:   http.MethodPost,
+			path:     "/admin/pause",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `{"paused":true}`,
+		},
+		{
+			name:     "resume with unknown delays",
+			method:   http.MethodPost,
+			path:     "/admin/resume?delays=drop",
+			token:    "secret",
+			wantCode: http.StatusBadRequest,
+			wantBody: `unknown delays drop, must be preserve or shift`,
+		},
+		{
+			name:     "resume with shifted delays",
+			method:   http.MethodPost,
+			path:     "/admin/resume?delays=shift",
+			token:    "secret",
+			wantCode: http.StatusOK,
+			wantBody: `{"paused":false}`,
+		},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		svc.restfulCont.ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: want code %d, got %d", tt.name, tt.wantCode, rec.Code)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != tt.wantBody {
+			t.Errorf("%s: want body %q, got %q", tt.name, tt.wantBody, body)
+		}
+	}
+	if !admin.shiftDelays {
+		t.Errorf("want the delays to be shifted on resume")
+	}
+}
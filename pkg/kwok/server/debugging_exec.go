@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	"k8s.io/cri-streaming/pkg/streaming/remotecommand"
+	utilexec "k8s.io/utils/exec"
 
 	"sigs.k8s.io/kwok/pkg/apis/internalversion"
 	"sigs.k8s.io/kwok/pkg/log"
@@ -46,7 +48,26 @@ func (s *Server) ExecInContainer(ctx context.Context, name string, uid string, c
 		return err
 	}
 
-	// Currently only support local exec.
+	// Respond with the canned response if any matches the command.
+	if execTarget.Scripted != nil {
+		response, found, err := findExecResponse(execTarget.Scripted.Responses, cmd)
+		if err != nil {
+			return err
+		}
+		if found {
+			return execScripted(ctx, response, stdout, stderr, tty)
+		}
+		if execTarget.Local == nil && execTarget.Container == nil {
+			return fmt.Errorf("no scripted response matches command %q", strings.Join(cmd, " "))
+		}
+	}
+
+	// Exec in the container started on demand.
+	if execTarget.Container != nil {
+		return s.execInRuntimeContainer(ctx, execTarget.Container, podName, podNamespace, uid, container, cmd, in, stdout, stderr, tty, resize)
+	}
+
+	// Otherwise only support local exec.
 	if execTarget.Local == nil {
 		return fmt.Errorf("not set local exec")
 	}
@@ -101,6 +122,73 @@ func (s *Server) execInContainer(ctx context.Context, cmd []string, in io.Reader
 	return nil
 }
 
+// findExecResponse returns the first response matching the command line.
+func findExecResponse(responses []internalversion.ExecResponse, cmd []string) (*internalversion.ExecResponse, bool, error) {
+	commandLine := strings.Join(cmd, " ")
+	for i, response := range responses {
+		match := response.Match
+		switch {
+		case match.Exact != "":
+			if commandLine != match.Exact {
+				continue
+			}
+		case match.Prefix != "":
+			if !strings.HasPrefix(commandLine, match.Prefix) {
+				continue
+			}
+		case match.Regex != "":
+			re, err := regexp.Compile(match.Regex)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid regex %q: %w", match.Regex, err)
+			}
+			if !re.MatchString(commandLine) {
+				continue
+			}
+		}
+		return &responses[i], true, nil
+	}
+	return nil, false, nil
+}
+
+// execScripted writes the canned response to the streams after its delay,
+// and returns an exit error if the exit code is not zero.
+func execScripted(ctx context.Context, response *internalversion.ExecResponse, stdout, stderr io.Writer, tty bool) error {
+	if response.DelayMilliseconds > 0 {
+		timer := time.NewTimer(time.Duration(response.DelayMilliseconds) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	// The stderr is merged into the stdout with a tty.
+	if tty {
+		stderr = stdout
+	}
+	if response.Stdout != "" && stdout != nil {
+		_, err := io.WriteString(stdout, response.Stdout)
+		if err != nil {
+			return err
+		}
+	}
+	if response.Stderr != "" && stderr != nil {
+		_, err := io.WriteString(stderr, response.Stderr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if response.ExitCode != 0 {
+		return utilexec.CodeExitError{
+			Err:  fmt.Errorf("command terminated with exit code %d", response.ExitCode),
+			Code: int(response.ExitCode),
+		}
+	}
+	return nil
+}
+
 func getExecTarget(rules []*internalversion.Exec, clusterRules []*internalversion.ClusterExec, podName, podNamespace string, containerName string) (*internalversion.ExecTarget, error) {
 	e, has := utilsslices.Find(rules, func(pf *internalversion.Exec) bool {
 		return pf.Name == podName && pf.Namespace == podNamespace
@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/cri-streaming/pkg/streaming/remotecommand"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/consts"
+	"sigs.k8s.io/kwok/pkg/log"
+	utilsexec "sigs.k8s.io/kwok/pkg/utils/exec"
+	"sigs.k8s.io/kwok/pkg/utils/format"
+)
+
+const (
+	// execContainerLabel is the label of the containers started for exec.
+	execContainerLabel = "kwok.x-k8s.io/exec=true"
+	// execContainerImageLabelKey is the label key of the image the container started for exec is run with.
+	execContainerImageLabelKey = "kwok.x-k8s.io/exec-image"
+	// execContainersGCInterval is the interval to remove the containers started for exec of the pods gone.
+	execContainersGCInterval = time.Minute
+)
+
+// execContainer is the container started for exec in the container of a pod.
+type execContainer struct {
+	mut     sync.Mutex
+	runtime string
+	pod     log.ObjectRef
+	uid     string
+}
+
+// execContainerRuntimes is the container runtimes the containers started for exec are run by.
+var execContainerRuntimes = []string{
+	consts.RuntimeTypeDocker,
+	consts.RuntimeTypePodman,
+	consts.RuntimeTypeNerdctl,
+}
+
+// execContainerName returns the name of the container started for exec in the container of the pod,
+// the separator is not allowed in the names of Kubernetes objects so that the name is unique,
+// and the pod recreated with the same name gets a new container.
+func execContainerName(podName, podNamespace, podUID, container string) string {
+	return "kwok-exec_" + podNamespace + "_" + podName + "_" + podUID + "_" + container
+}
+
+// parseExecContainerName returns the pod of the container started for exec by the name of the container.
+func parseExecContainerName(name string) (pod log.ObjectRef, podUID string, ok bool) {
+	rest, ok := strings.CutPrefix(name, "kwok-exec_")
+	if !ok {
+		return pod, "", false
+	}
+	parts := strings.Split(rest, "_")
+	if len(parts) != 4 {
+		return pod, "", false
+	}
+	return log.KRef(parts[0], parts[1]), parts[2], true
+}
+
+// execContainerArgs returns the arguments of the container runtime to exec the command in the container.
+func execContainerArgs(target *internalversion.ExecTargetContainer, name string, cmd []string, stdin, tty bool) []string {
+	args := []string{"exec"}
+	if stdin {
+		args = append(args, "--interactive")
+	}
+	if tty {
+		args = append(args, "--tty")
+	}
+	if target.WorkDir != "" {
+		args = append(args, "--workdir="+target.WorkDir)
+	}
+	for _, env := range target.Envs {
+		args = append(args, "--env="+env.Name+"="+env.Value)
+	}
+	if sc := target.SecurityContext; sc != nil && sc.RunAsUser != nil {
+		user := format.String(*sc.RunAsUser)
+		if sc.RunAsGroup != nil {
+			user += ":" + format.String(*sc.RunAsGroup)
+		}
+		args = append(args, "--user="+user)
+	}
+	args = append(args, name)
+	args = append(args, cmd...)
+	return args
+}
+
+// execInRuntimeContainer executes the command in a container started on demand by the container runtime.
+func (s *Server) execInRuntimeContainer(ctx context.Context, target *internalversion.ExecTargetContainer, podName, podNamespace, podUID, container string, cmd []string, in io.Reader, stdout, stderr io.WriteCloser, tty bool, resize <-chan remotecommand.TerminalSize) error {
+	runtime := target.Runtime
+	if runtime == "" {
+		runtime = consts.RuntimeTypeDocker
+	}
+	switch runtime {
+	case consts.RuntimeTypeDocker, consts.RuntimeTypePodman, consts.RuntimeTypeNerdctl:
+	default:
+		return fmt.Errorf("unsupported container runtime %q", runtime)
+	}
+	if target.Image == "" || strings.HasPrefix(target.Image, "-") {
+		return fmt.Errorf("invalid image %q", target.Image)
+	}
+
+	name := execContainerName(podName, podNamespace, podUID, container)
+	err := s.ensureExecContainer(ctx, runtime, target.Image, name, log.KRef(podNamespace, podName), podUID)
+	if err != nil {
+		return err
+	}
+
+	execCmd := append([]string{runtime}, execContainerArgs(target, name, cmd, in != nil, tty)...)
+
+	// Set cancel context.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if tty {
+		return s.execInContainerWithTTY(ctx, execCmd, in, stdout, resize)
+	}
+
+	return s.execInContainer(ctx, execCmd, in, stdout, stderr)
+}
+
+// ensureExecContainer starts the container if it is not running,
+// the container is kept running to serve the subsequent execs, and recreated if the image is changed.
+func (s *Server) ensureExecContainer(ctx context.Context, runtime, image, name string, pod log.ObjectRef, podUID string) error {
+	c := s.lockExecContainer(name, pod, podUID)
+	defer c.mut.Unlock()
+
+	if c.runtime != "" && c.runtime != runtime {
+		// The runtime is changed, remove the container from the previous runtime.
+		err := utilsexec.Exec(ctx, c.runtime, "rm", "--force", name)
+		if err != nil {
+			return fmt.Errorf("failed to remove exec container %s: %w", name, err)
+		}
+	}
+	c.runtime = runtime
+
+	out := bytes.NewBuffer(nil)
+	err := utilsexec.Exec(utilsexec.WithWriteTo(ctx, out), runtime, "inspect",
+		"--format={{.State.Running}} {{index .Config.Labels \""+execContainerImageLabelKey+"\"}}",
+		name,
+	)
+	if err == nil {
+		running, current, _ := strings.Cut(strings.TrimSpace(out.String()), " ")
+		if current == image {
+			if running != "true" {
+				err = utilsexec.Exec(ctx, runtime, "start", name)
+				if err != nil {
+					return fmt.Errorf("failed to start exec container %s: %w", name, err)
+				}
+			}
+			return nil
+		}
+
+		// The image is changed, recreate the container with the image.
+		err = utilsexec.Exec(ctx, runtime, "rm", "--force", name)
+		if err != nil {
+			return fmt.Errorf("failed to remove exec container %s: %w", name, err)
+		}
+	}
+
+	err = utilsexec.Exec(ctx, runtime, "run",
+		"--detach",
+		"--name="+name,
+		"--label="+execContainerLabel,
+		"--label="+execContainerImageLabelKey+"="+image,
+		"--entrypoint=sleep",
+		image,
+		"infinity",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to run exec container %s: %w", name, err)
+	}
+	return nil
+}
+
+// lockExecContainer returns the locked container tracked by the name,
+// the lock is held per container so that starting a container does not block the others.
+func (s *Server) lockExecContainer(name string, pod log.ObjectRef, podUID string) *execContainer {
+	for {
+		c, _ := s.execContainers.LoadOrStore(name, &execContainer{
+			pod: pod,
+			uid: podUID,
+		})
+		c.mut.Lock()
+		if cur, ok := s.execContainers.Load(name); ok && cur == c {
+			return c
+		}
+		// The container is removed while waiting for the lock.
+		c.mut.Unlock()
+	}
+}
+
+// removeExecContainer removes the container started for exec.
+func (s *Server) removeExecContainer(ctx context.Context, name string, c *execContainer) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.runtime != "" {
+		err := utilsexec.Exec(ctx, c.runtime, "rm", "--force", name)
+		if err != nil {
+			logger := log.FromContext(ctx)
+			logger.Warn("Failed to remove exec container",
+				"container", name,
+				"err", err,
+			)
+		}
+	}
+	s.execContainers.CompareAndDelete(name, c)
+}
+
+// removeExecContainers removes the containers started for exec.
+func (s *Server) removeExecContainers(ctx context.Context) {
+	s.execContainers.Range(func(name string, c *execContainer) bool {
+		s.removeExecContainer(ctx, name, c)
+		return true
+	})
+}
+
+// gcExecContainers removes the containers started for exec of the pods gone periodically,
+// including the ones left by the previous run, which are listed from the container runtimes.
+func (s *Server) gcExecContainers(ctx context.Context) {
+	s.listExecContainers(ctx)
+
+	ticker := time.NewTicker(execContainersGCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.listExecContainers(ctx)
+			s.removeOrphanExecContainers(ctx)
+		}
+	}
+}
+
+// listExecContainers tracks the containers started for exec that are not tracked yet,
+// such as the ones left by the previous run, so that they are removed once their pods are gone.
+func (s *Server) listExecContainers(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	for _, runtime := range execContainerRuntimes {
+		if _, err := exec.LookPath(runtime); err != nil {
+			continue
+		}
+
+		out := bytes.NewBuffer(nil)
+		err := utilsexec.Exec(utilsexec.WithWriteTo(ctx, out), runtime, "ps",
+			"--all",
+			"--filter=label="+execContainerLabel,
+			"--format={{.Names}}",
+		)
+		if err != nil {
+			logger.Warn("Failed to list exec containers",
+				"runtime", runtime,
+				"err", err,
+			)
+			continue
+		}
+
+		for _, name := range strings.Fields(out.String()) {
+			pod, podUID, ok := parseExecContainerName(name)
+			if !ok {
+				continue
+			}
+			s.execContainers.LoadOrStore(name, &execContainer{
+				runtime: runtime,
+				pod:     pod,
+				uid:     podUID,
+			})
+		}
+	}
+}
+
+// removeOrphanExecContainers removes the containers started for exec of the pods gone.
+func (s *Server) removeOrphanExecContainers(ctx context.Context) {
+	exists := s.execContainerPodExists()
+	if exists == nil {
+		return
+	}
+	s.execContainers.Range(func(name string, c *execContainer) bool {
+		if !exists(c.pod, c.uid) {
+			s.removeExecContainer(ctx, name, c)
+		}
+		return true
+	})
+}
+
+// execContainerPodExists returns the function to check whether the pod of a container started for exec exists,
+// or nil if the pods are unknown.
+func (s *Server) execContainerPodExists() func(pod log.ObjectRef, uid string) bool {
+	if s.podCacheGetter != nil {
+		return func(ref log.ObjectRef, uid string) bool {
+			pod, ok := s.podCacheGetter.GetWithNamespace(ref.Name, ref.Namespace)
+			return ok && (uid == "" || string(pod.UID) == uid)
+		}
+	}
+	if s.dataSource != nil {
+		pods := map[log.ObjectRef]struct{}{}
+		for _, nodeName := range s.dataSource.ListNodes() {
+			refs, _ := s.dataSource.ListPods(nodeName)
+			for _, ref := range refs {
+				pods[ref] = struct{}{}
+			}
+		}
+		return func(ref log.ObjectRef, _ string) bool {
+			_, ok := pods[ref]
+			return ok
+		}
+	}
+	return nil
+}
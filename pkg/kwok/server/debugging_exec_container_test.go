@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+func Test_execContainerArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		target *internalversion.ExecTargetContainer
+		cmd    []string
+		stdin  bool
+		tty    bool
+		want   []string
+	}{
+		{
+			name: "simple",
+			target: &internalversion.ExecTargetContainer{
+				Image: "busybox",
+			},
+			cmd:  []string{"cat", "/etc/config"},
+			want: []string{"exec", "kwok-exec_default_pod_uid_app", "cat", "/etc/config"},
+		},
+		{
+			name: "all options",
+			target: &internalversion.ExecTargetContainer{
+				Image:   "busybox",
+				WorkDir: "/tmp",
+				Envs: []internalversion.EnvVar{
+					{Name: "FOO", Value: "bar"},
+				},
+				SecurityContext: &internalversion.SecurityContext{
+					RunAsUser:  new(int64(1000)),
+					RunAsGroup: new(int64(2000)),
+				},
+			},
+			cmd:   []string{"sh"},
+			stdin: true,
+			tty:   true,
+			want: []string{
+				"exec", "--interactive", "--tty", "--workdir=/tmp", "--env=FOO=bar", "--user=1000:2000",
+				"kwok-exec_default_pod_uid_app", "sh",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := execContainerName("pod", "default", "uid", "app")
+			got := execContainerArgs(tt.target, name, tt.cmd, tt.stdin, tt.tty)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("execContainerArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseExecContainerName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    log.ObjectRef
+		wantUID string
+		wantOK  bool
+	}{
+		{
+			name:    execContainerName("pod", "default", "uid", "app"),
+			want:    log.KRef("default", "pod"),
+			wantUID: "uid",
+			wantOK:  true,
+		},
+		{
+			name: "kwok-exec_default_pod",
+		},
+		{
+			name: "other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotUID, ok := parseExecContainerName(tt.name)
+			if got != tt.want || gotUID != tt.wantUID || ok != tt.wantOK {
+				t.Errorf("parseExecContainerName() = %v, %v, %v, want %v, %v, %v", got, gotUID, ok, tt.want, tt.wantUID, tt.wantOK)
+			}
+		})
+	}
+}
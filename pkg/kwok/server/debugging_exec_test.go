@@ -17,10 +17,14 @@ limitations under the License.
 package server
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilexec "k8s.io/utils/exec"
 
 	"sigs.k8s.io/kwok/pkg/apis/internalversion"
 )
@@ -237,3 +241,118 @@ func Test_getExecTarget(t *testing.T) {
 		})
 	}
 }
+
+func Test_findExecResponse(t *testing.T) {
+	responses := []internalversion.ExecResponse{
+		{
+			Match:  internalversion.ExecCommandMatch{Exact: "cat /etc/config"},
+			Stdout: "exact",
+		},
+		{
+			Match:  internalversion.ExecCommandMatch{Prefix: "cat "},
+			Stdout: "prefix",
+		},
+		{
+			Match:  internalversion.ExecCommandMatch{Regex: "^sh -c .*ready.*$"},
+			Stdout: "regex",
+		},
+	}
+	tests := []struct {
+		name       string
+		responses  []internalversion.ExecResponse
+		cmd        []string
+		wantStdout string
+		wantOk     bool
+		wantErr    bool
+	}{
+		{
+			name:       "match exact",
+			responses:  responses,
+			cmd:        []string{"cat", "/etc/config"},
+			wantStdout: "exact",
+			wantOk:     true,
+		},
+		{
+			name:       "match prefix",
+			responses:  responses,
+			cmd:        []string{"cat", "/etc/hosts"},
+			wantStdout: "prefix",
+			wantOk:     true,
+		},
+		{
+			name:       "match regex",
+			responses:  responses,
+			cmd:        []string{"sh", "-c", "test -f /tmp/ready"},
+			wantStdout: "regex",
+			wantOk:     true,
+		},
+		{
+			name:      "not match",
+			responses: responses,
+			cmd:       []string{"ls"},
+		},
+		{
+			name: "match any",
+			responses: append(responses, internalversion.ExecResponse{
+				Stdout: "any",
+			}),
+			cmd:        []string{"ls"},
+			wantStdout: "any",
+			wantOk:     true,
+		},
+		{
+			name: "invalid regex",
+			responses: []internalversion.ExecResponse{
+				{
+					Match: internalversion.ExecCommandMatch{Regex: "("},
+				},
+			},
+			cmd:     []string{"ls"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk, err := findExecResponse(tt.responses, tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findExecResponse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("findExecResponse() gotOk = %v, want %v", gotOk, tt.wantOk)
+				return
+			}
+			if gotOk && got.Stdout != tt.wantStdout {
+				t.Errorf("findExecResponse() got stdout = %q, want %q", got.Stdout, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func Test_execScripted(t *testing.T) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	err := execScripted(context.Background(), &internalversion.ExecResponse{
+		Stdout:   "out",
+		Stderr:   "err",
+		ExitCode: 2,
+	}, stdout, stderr, false)
+
+	var exitErr utilexec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 2 {
+		t.Fatalf("expected exit code 2, got %v", err)
+	}
+	if stdout.String() != "out" || stderr.String() != "err" {
+		t.Fatalf("unexpected output, stdout %q, stderr %q", stdout.String(), stderr.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = execScripted(ctx, &internalversion.ExecResponse{
+		Stdout:            "out",
+		DelayMilliseconds: 10000,
+	}, stdout, stderr, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+}
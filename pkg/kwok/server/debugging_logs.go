@@ -29,6 +29,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apiserver/pkg/util/flushwriter"
 	criapi "k8s.io/cri-api/pkg/apis"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
@@ -62,7 +63,16 @@ func (s *Server) GetContainerLogs(ctx context.Context, podName, podNamespace, co
 
 	logsFile := log.LogsFile
 	if logOptions.Previous {
+		if log.PreviousLogsFile == "" {
+			return fmt.Errorf("previous terminated container %q in pod %q not found", container, podName)
+		}
 		logsFile = log.PreviousLogsFile
+	} else if log.Generator != nil {
+		generator, err := newLogGenerator(log.Generator, s.containerStartTime(podName, podNamespace, container), podName, podNamespace, s.podUID(podName, podNamespace), container)
+		if err != nil {
+			return err
+		}
+		return generator.Generate(ctx, opts, stdout)
 	}
 
 	return readLogs(ctx, logsFile, opts, stdout, stderr)
@@ -160,6 +170,39 @@ func findLogInLogs(containerName string, logs []internalversion.Log) (*internalv
 	return defaultLog, defaultLog != nil
 }
 
+// podUID returns the UID of the pod, or empty if the pod is not found.
+func (s *Server) podUID(podName, podNamespace string) types.UID {
+	if s.podCacheGetter == nil {
+		return ""
+	}
+	pod, ok := s.podCacheGetter.GetWithNamespace(podName, podNamespace)
+	if !ok {
+		return ""
+	}
+	return pod.UID
+}
+
+// containerStartTime returns the time when the container started,
+// or the time when the pod was created if the container is not running.
+func (s *Server) containerStartTime(podName, podNamespace, container string) time.Time {
+	if s.podCacheGetter == nil {
+		return logGeneratorStartTime
+	}
+	pod, ok := s.podCacheGetter.GetWithNamespace(podName, podNamespace)
+	if !ok {
+		return logGeneratorStartTime
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container && status.State.Running != nil {
+			return status.State.Running.StartedAt.Time
+		}
+	}
+	if pod.CreationTimestamp.IsZero() {
+		return logGeneratorStartTime
+	}
+	return pod.CreationTimestamp.Time
+}
+
 func readLogs(ctx context.Context, logsFile string, opts *crilogs.LogOptions, stdout, stderr io.Writer) error {
 	return crilogs.ReadLogs(ctx, logsFile, "", opts, runtimeServiceStub{}, stdout, stderr)
 }
@@ -0,0 +1,252 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"k8s.io/apimachinery/pkg/types"
+	crilogs "k8s.io/cri-client/pkg/logs"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+// logGeneratorStartTime is the start time of the generated logs if the start time of the container is unknown.
+var logGeneratorStartTime = time.Now()
+
+// logGenerator generates synthetic logs, the i-th line is generated at start + i/rate,
+// and its content only depends on the seed, the container and i, so every request gets the same lines.
+type logGenerator struct {
+	template *template.Template
+	rate     int64
+	seed     int64
+	start    time.Time
+	now      func() time.Time
+
+	podName      string
+	podNamespace string
+	podUID       types.UID
+	container    string
+}
+
+// logLine is the data to render a line of the generated logs.
+type logLine struct {
+	Index        int64
+	Time         time.Time
+	PodName      string
+	PodNamespace string
+	Container    string
+	Rand         *logRand
+}
+
+// logRand provides random values to the template of the generated logs.
+type logRand struct {
+	rand *rand.Rand
+}
+
+// Int returns a random int in [low, high).
+func (r *logRand) Int(low, high int) int {
+	if high <= low {
+		return low
+	}
+	return low + r.rand.IntN(high-low)
+}
+
+// Float returns a random float in [0.0, 1.0).
+func (r *logRand) Float() float64 {
+	return r.rand.Float64()
+}
+
+// Pick returns one of the items randomly.
+func (r *logRand) Pick(items ...string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return items[r.rand.IntN(len(items))]
+}
+
+// Hex returns a random hex string of n bytes.
+func (r *logRand) Hex(n int) string {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(r.rand.UintN(256))
+	}
+	return hex.EncodeToString(buf)
+}
+
+// newLogGenerator returns a new logGenerator of the container, the lines are generated since the start.
+func newLogGenerator(conf *internalversion.LogGenerator, start time.Time, podName, podNamespace string, podUID types.UID, container string) (*logGenerator, error) {
+	temp, err := template.New("_").
+		Funcs(sprig.TxtFuncMap()).
+		Parse(conf.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log template: %w", err)
+	}
+
+	rate := conf.LinesPerSecond
+	if rate <= 0 {
+		rate = 1
+	}
+	return &logGenerator{
+		template:     temp,
+		rate:         rate,
+		seed:         conf.Seed,
+		start:        start,
+		now:          time.Now,
+		podName:      podName,
+		podNamespace: podNamespace,
+		podUID:       podUID,
+		container:    container,
+	}, nil
+}
+
+// timeOf returns the time when the i-th line is generated.
+func (g *logGenerator) timeOf(i int64) time.Time {
+	sec := i / g.rate
+	rem := i % g.rate
+	return g.start.Add(time.Duration(sec)*time.Second + time.Duration(rem)*time.Second/time.Duration(g.rate))
+}
+
+// linesUntil returns the number of lines generated until the time.
+func (g *logGenerator) linesUntil(t time.Time) int64 {
+	d := t.Sub(g.start)
+	if d < 0 {
+		return 0
+	}
+	n := int64(d/time.Second)*g.rate + int64(d%time.Second)*g.rate/int64(time.Second)
+	// Correct the rounding of timeOf.
+	for n > 0 && g.timeOf(n-1).After(t) {
+		n--
+	}
+	for !g.timeOf(n).After(t) {
+		n++
+	}
+	return n
+}
+
+// render writes the i-th line.
+func (g *logGenerator) render(buf *bytes.Buffer, i int64, timestamps bool) error {
+	t := g.timeOf(i)
+	if timestamps {
+		buf.WriteString(t.Format(crilogs.RFC3339NanoFixed))
+		buf.WriteByte(' ')
+	}
+	line := logLine{
+		Index:        i,
+		Time:         t,
+		PodName:      g.podName,
+		PodNamespace: g.podNamespace,
+		Container:    g.container,
+		Rand: &logRand{
+			rand: rand.New(rand.NewPCG(uint64(g.seed), g.stream(i))),
+		},
+	}
+	err := g.template.Execute(buf, line)
+	if err != nil {
+		return fmt.Errorf("failed to render log line %d: %w", i, err)
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// stream returns the stream of the random source of the i-th line,
+// which differs between the containers so that they do not generate the same lines with the same seed.
+func (g *logGenerator) stream(i int64) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(g.podNamespace + "/" + g.podName + "/" + string(g.podUID) + "/" + g.container + "/" + strconv.FormatInt(i, 10)))
+	return h.Sum64()
+}
+
+// Generate writes the generated logs to the stdout,
+// and keeps generating the new lines until the context is done if follow is set.
+func (g *logGenerator) Generate(ctx context.Context, opts *crilogs.LogOptions, stdout io.Writer) error {
+	begin := int64(0)
+	if !opts.Since.IsZero() {
+		begin = g.linesUntil(opts.Since.Add(-1))
+	}
+	end := g.linesUntil(g.now())
+	if opts.TailLines != nil && *opts.TailLines >= 0 && end-*opts.TailLines > begin {
+		begin = end - *opts.TailLines
+	}
+
+	var limit int64 = -1
+	if opts.LimitBytes != nil && *opts.LimitBytes >= 0 {
+		limit = *opts.LimitBytes
+	}
+
+	buf := bytes.NewBuffer(nil)
+	write := func(i int64) (bool, error) {
+		buf.Reset()
+		err := g.render(buf, i, opts.Timestamp)
+		if err != nil {
+			return false, err
+		}
+		data := buf.Bytes()
+		if limit >= 0 && int64(len(data)) >= limit {
+			_, err = stdout.Write(data[:limit])
+			return false, err
+		}
+		_, err = stdout.Write(data)
+		if err != nil {
+			return false, err
+		}
+		if limit >= 0 {
+			limit -= int64(len(data))
+		}
+		return true, nil
+	}
+
+	for i := begin; i < end; i++ {
+		more, err := write(i)
+		if err != nil || !more {
+			return err
+		}
+	}
+
+	if !opts.Follow {
+		return nil
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for i := end; ; i++ {
+		if wait := g.timeOf(i).Sub(g.now()); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-timer.C:
+			}
+		}
+		more, err := write(i)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	crilogs "k8s.io/cri-client/pkg/logs"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+func TestLogGenerator(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(2*time.Second + 100*time.Millisecond)
+
+	tests := []struct {
+		name string
+		conf internalversion.LogGenerator
+		opts crilogs.LogOptions
+		want string
+	}{
+		{
+			name: "all lines",
+			conf: internalversion.LogGenerator{
+				Template:       `{{ .Index }} {{ .PodNamespace }}/{{ .PodName }}/{{ .Container }}`,
+				LinesPerSecond: 2,
+			},
+			want: "0 default/pod/app\n1 default/pod/app\n2 default/pod/app\n3 default/pod/app\n4 default/pod/app\n",
+		},
+		{
+			name: "tail lines",
+			conf: internalversion.LogGenerator{
+				Template:       `{{ .Index }}`,
+				LinesPerSecond: 2,
+			},
+			opts: crilogs.LogOptions{
+				TailLines: new(int64(2)),
+			},
+			want: "3\n4\n",
+		},
+		{
+			name: "since time",
+			conf: internalversion.LogGenerator{
+				Template:       `{{ .Index }}`,
+				LinesPerSecond: 2,
+			},
+			opts: crilogs.LogOptions{
+				Since: start.Add(time.Second),
+			},
+			want: "2\n3\n4\n",
+		},
+		{
+			name: "timestamps",
+			conf: internalversion.LogGenerator{
+				Template: `{{ .Time.Unix }}`,
+			},
+			opts: crilogs.LogOptions{
+				Timestamp: true,
+				Since:     start.Add(1500 * time.Millisecond),
+			},
+			want: "2024-01-01T00:00:02.000000000Z 1704067202\n",
+		},
+		{
+			name: "limit bytes",
+			conf: internalversion.LogGenerator{
+				Template:       `{{ .Index }}`,
+				LinesPerSecond: 2,
+			},
+			opts: crilogs.LogOptions{
+				LimitBytes: new(int64(5)),
+			},
+			want: "0\n1\n2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := newLogGenerator(&tt.conf, start, "pod", "default", "uid", "app")
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.now = func() time.Time { return now }
+
+			buf := bytes.NewBuffer(nil)
+			err = g.Generate(context.Background(), &tt.opts, buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Generate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogGeneratorWithSeed(t *testing.T) {
+	conf := &internalversion.LogGenerator{
+		Template: `{{ .Rand.Int 0 1000000 }} {{ .Rand.Pick "GET" "POST" }} {{ .Rand.Hex 4 }}`,
+		Seed:     1,
+	}
+	start := time.Now().Add(-10 * time.Second)
+
+	generate := func(conf *internalversion.LogGenerator, podName, podNamespace string, podUID types.UID, container string) string {
+		g, err := newLogGenerator(conf, start, podName, podNamespace, podUID, container)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.now = func() time.Time { return start.Add(5 * time.Second) }
+		buf := bytes.NewBuffer(nil)
+		err = g.Generate(context.Background(), &crilogs.LogOptions{}, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	first := generate(conf, "pod", "default", "uid", "app")
+	if second := generate(conf, "pod", "default", "uid", "app"); first != second {
+		t.Errorf("expected the same logs with the same seed, got %q and %q", first, second)
+	}
+	if other := generate(&internalversion.LogGenerator{Template: conf.Template, Seed: 2}, "pod", "default", "uid", "app"); first == other {
+		t.Errorf("expected different logs with different seeds, got %q", other)
+	}
+
+	// The containers sharing the seed generate different lines.
+	for _, other := range []string{
+		generate(conf, "other", "default", "uid", "app"),
+		generate(conf, "pod", "other", "uid", "app"),
+		generate(conf, "pod", "default", "other", "app"),
+		generate(conf, "pod", "default", "uid", "other"),
+	} {
+		if first == other {
+			t.Errorf("expected different logs of different containers, got %q", other)
+		}
+	}
+}
+
+func TestLogGeneratorFollow(t *testing.T) {
+	g, err := newLogGenerator(&internalversion.LogGenerator{
+		Template:       `{{ .Index }}`,
+		LinesPerSecond: 100,
+	}, time.Now(), "pod", "default", "uid", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	buf := bytes.NewBuffer(nil)
+	err = g.Generate(ctx, &crilogs.LogOptions{Follow: true}, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines < 5 {
+		t.Errorf("expected the lines to be followed, got %d lines", lines)
+	}
+}
@@ -34,10 +34,8 @@ type cumulative struct {
 	value float64
 }
 
-func (s *Server) containerResourceCumulativeUsage(resourceName, podNamespace, podName, containerName string) float64 {
-	key := fmt.Sprintf("%s/%s/%s/%s", resourceName, podNamespace, podName, containerName)
-	v := s.containerResourceUsage(resourceName, podNamespace, podName, containerName)
-
+// cumulativeUsage accumulates the usage v over the time since the last accumulation of the key.
+func (s *Server) cumulativeUsage(key string, v float64) float64 {
 	now := time.Now()
 	s.cumulativesMut.Lock()
 	defer s.cumulativesMut.Unlock()
@@ -52,6 +50,15 @@ func (s *Server) containerResourceCumulativeUsage(resourceName, podNamespace, po
 	return c.value
 }
 
+func containerCumulativeKey(resourceName, podNamespace, podName, containerName string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", resourceName, podNamespace, podName, containerName)
+}
+
+func (s *Server) containerResourceCumulativeUsage(resourceName, podNamespace, podName, containerName string) float64 {
+	v := s.containerResourceUsage(resourceName, podNamespace, podName, containerName)
+	return s.cumulativeUsage(containerCumulativeKey(resourceName, podNamespace, podName, containerName), v)
+}
+
 func (s *Server) podResourceCumulativeUsage(resourceName, podNamespace, podName string) float64 {
 	pod, ok := s.podCacheGetter.GetWithNamespace(podName, podNamespace)
 	if !ok {
@@ -93,19 +100,7 @@ func (s *Server) nodeResourceCumulativeUsage(resourceName, nodeName string) floa
 		}
 	}
 
-	now := time.Now()
-	key := nodeName
-	s.cumulativesMut.Lock()
-	defer s.cumulativesMut.Unlock()
-	c, ok := s.cumulatives[key]
-	if ok {
-		c.value += now.Sub(c.time).Seconds() * sum
-	}
-
-	c.time = now
-	s.cumulatives[key] = c
-
-	return c.value
+	return s.cumulativeUsage(nodeName, sum)
 }
 
 func (s *Server) containerResourceUsage(resourceName, podNamespace, podName, containerName string) float64 {
@@ -144,6 +139,11 @@ func (s *Server) evaluateContainerResourceUsage(resourceName string, data metric
 		)
 		return 0
 	}
+	return s.evaluateResourceUsage(u, resourceName, data)
+}
+
+// evaluateResourceUsage returns the usage of the resource from the resource usage of the container.
+func (s *Server) evaluateResourceUsage(u *internalversion.ResourceUsageContainer, resourceName string, data metrics.Data) float64 {
 	if u.Usage == nil {
 		return 0
 	}
@@ -177,6 +177,11 @@ func (s *Server) evaluateContainerResourceUsage(resourceName string, data metric
 	return 0
 }
 
+// PodResourceUsage returns the simulated usage of the resource by the pod.
+func (s *Server) PodResourceUsage(resourceName, podNamespace, podName string) float64 {
+	return s.podResourceUsage(resourceName, podNamespace, podName)
+}
+
 func (s *Server) podResourceUsage(resourceName, podNamespace, podName string) float64 {
 	pod, ok := s.podCacheGetter.GetWithNamespace(podName, podNamespace)
 	if !ok {
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+)
+
+// NodePartitioner is the interface that simulates the network partitions between nodes and the apiserver.
+type NodePartitioner interface {
+	PartitionNodes(nodeNames []string, duration time.Duration)
+	HealNode(nodeName string) bool
+	ListNodePartitions() map[string]time.Time
+}
+
+// nodePartitionRequest is the request to partition the nodes.
+type nodePartitionRequest struct {
+	NodeNames []string `json:"nodeNames"`
+	// Duration is the duration of the partition, such as "5m",
+	// the nodes are partitioned until they are healed if it is empty.
+	Duration string `json:"duration,omitempty"`
+}
+
+// nodePartition is a partitioned node.
+type nodePartition struct {
+	NodeName   string     `json:"nodeName"`
+	ExpireTime *time.Time `json:"expireTime,omitempty"`
+}
+
+// installNodePartitions installs the handlers of the node partitions to the admin API.
+func (s *Server) installNodePartitions(ws *restful.WebService) {
+	ws.Route(ws.GET("/partitions").
+		To(s.listNodePartitions).
+		Operation("listNodePartitions"))
+	ws.Route(ws.POST("/partitions").
+		To(s.createNodePartitions).
+		Operation("createNodePartitions"))
+	ws.Route(ws.DELETE("/partitions/{nodeName}").
+		To(s.deleteNodePartition).
+		Operation("deleteNodePartition"))
+}
+
+func (s *Server) listNodePartitions(req *restful.Request, resp *restful.Response) {
+	partitions := []nodePartition{}
+	for nodeName, expireTime := range s.nodePartitioner.ListNodePartitions() {
+		partition := nodePartition{
+			NodeName: nodeName,
+		}
+		if !expireTime.IsZero() {
+			partition.ExpireTime = &expireTime
+		}
+		partitions = append(partitions, partition)
+	}
+	slices.SortFunc(partitions, func(a, b nodePartition) int {
+		return strings.Compare(a.NodeName, b.NodeName)
+	})
+
+	writeJSON(resp, partitions)
+}
+
+func (s *Server) createNodePartitions(req *restful.Request, resp *restful.Response) {
+	var partition nodePartitionRequest
+	err := json.NewDecoder(req.Request.Body).Decode(&partition)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(partition.NodeNames) == 0 {
+		http.Error(resp, "nodeNames is required", http.StatusBadRequest)
+		return
+	}
+
+	var duration time.Duration
+	if partition.Duration != "" {
+		duration, err = time.ParseDuration(partition.Duration)
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if duration <= 0 {
+			http.Error(resp, "duration must be positive", http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.nodePartitioner.PartitionNodes(partition.NodeNames, duration)
+	s.listNodePartitions(req, resp)
+}
+
+func (s *Server) deleteNodePartition(req *restful.Request, resp *restful.Response) {
+	nodeName := req.PathParameter("nodeName")
+	if !s.nodePartitioner.HealNode(nodeName) {
+		http.Error(resp, "node "+nodeName+" is not partitioned", http.StatusNotFound)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeNodePartitioner struct {
+	partitions map[string]time.Time
+}
+
+func (f *fakeNodePartitioner) PartitionNodes(nodeNames []string, duration time.Duration) {
+	var expireTime time.Time
+	if duration > 0 {
+		expireTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(duration)
+	}
+	for _, nodeName := range nodeNames {
+		f.partitions[nodeName] = expireTime
+	}
+}
+
+func (f *fakeNodePartitioner) HealNode(nodeName string) bool {
+	_, ok := f.partitions[nodeName]
+	delete(f.partitions, nodeName)
+	return ok
+}
+
+func (f *fakeNodePartitioner) ListNodePartitions() map[string]time.Time {
+	return f.partitions
+}
+
+func TestNodePartitions(t *testing.T) {
+	svc, err := NewServer(Config{
+		NodePartitioner: &fakeNodePartitioner{
+			partitions: map[string]time.Time{},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.InstallAdmin("secret")
+
+	do := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		svc.restfulCont.ServeHTTP(rec, req)
+		return rec.Code, strings.TrimSpace(rec.Body.String())
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "list empty",
+			method:   http.MethodGet,
+			path:     "/admin/partitions",
+			wantCode: http.StatusOK,
+			wantBody: `[]`,
+		},
+		{
+			name:     "partition with duration",
+			method:   http.MethodPost,
+			path:     "/admin/partitions",
+			body:     `{"nodeNames":["node1"],"duration":"5m"}`,
+			wantCode: http.StatusOK,
+			wantBody: `[{"nodeName":"node1","expireTime":"2024-01-01T00:05:00Z"}]`,
+		},
+		{
+			name:     "partition until healed",
+			method:   http.MethodPost,
+			path:     "/admin/partitions",
+			body:     `{"nodeNames":["node0"]}`,
+			wantCode: http.StatusOK,
+			wantBody: `[{"nodeName":"node0"},{"nodeName":"node1","expireTime":"2024-01-01T00:05:00Z"}]`,
+		},
+		{
+			name:     "partition without nodes",
+			method:   http.MethodPost,
+			path:     "/admin/partitions",
+			body:     `{"duration":"5m"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `nodeNames is required`,
+		},
+		{
+			name:     "partition with invalid duration",
+			method:   http.MethodPost,
+			path:     "/admin/partitions",
+			body:     `{"nodeNames":["node0"],"duration":"-5m"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `duration must be positive`,
+		},
+		{
+			name:     "heal",
+			method:   http.MethodDelete,
+			path:     "/admin/partitions/node0",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "heal not partitioned",
+			method:   http.MethodDelete,
+			path:     "/admin/partitions/node0",
+			wantCode: http.StatusNotFound,
+			wantBody: `node node0 is not partitioned`,
+		},
+		{
+			name:     "list",
+			method:   http.MethodGet,
+			path:     "/admin/partitions",
+			wantCode: http.StatusOK,
+			wantBody: `[{"nodeName":"node1","expireTime":"2024-01-01T00:05:00Z"}]`,
+		},
+	}
+	for _, tt := range tests {
+		code, body := do(tt.method, tt.path, tt.body)
+		if code != tt.wantCode {
+			t.Errorf("%s: want code %d, got %d", tt.name, tt.wantCode, code)
+		}
+		if body != tt.wantBody {
+			t.Errorf("%s: want body %q, got %q", tt.name, tt.wantBody, body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/partitions", strings.NewReader(`{"nodeNames":["node0"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	svc.restfulCont.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("want code %d without the token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
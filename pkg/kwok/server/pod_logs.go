@@ -0,0 +1,619 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crilogs "k8s.io/cri-client/pkg/logs"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/format"
+)
+
+// PodLogsConfig holds configurations of writing the logs of the containers to the files.
+type PodLogsConfig struct {
+	// Dir is the directory to write the logs, which is laid out the same as /var/log/pods of kubelet.
+	Dir string
+	// MaxSizeBytes is the maximum size of a log file before it is rotated.
+	MaxSizeBytes int64
+	// MaxFiles is the maximum number of log files of a container, including the current one.
+	MaxFiles int
+	// Interval is the interval to write the new logs.
+	Interval time.Duration
+}
+
+// StartPodLogs starts writing the logs of the running containers of the managed pods
+// from the Logs and ClusterLogs to the files in the CRI format, so that the node-level log collectors can read them.
+func (s *Server) StartPodLogs(ctx context.Context, conf PodLogsConfig) error {
+	if s.podCacheGetter == nil {
+		return fmt.Errorf("pod cache is required to write the pod logs")
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = time.Second
+	}
+	err := os.MkdirAll(conf.Dir, 0750)
+	if err != nil {
+		return fmt.Errorf("failed to create pod logs dir: %w", err)
+	}
+
+	w := newPodLogsWriter(conf, s.listManagedPods, s.podCacheGetter.GetWithNamespace, func(podName, podNamespace, container string) (*internalversion.Log, error) {
+		return getPodLogs(s.logs.Get(), s.clusterLogs.Get(), podName, podNamespace, container)
+	})
+	err = w.scan()
+	if err != nil {
+		return fmt.Errorf("failed to scan pod logs dir: %w", err)
+	}
+
+	go func() {
+		logger := log.FromContext(ctx)
+		ticker := time.NewTicker(conf.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := w.sync()
+				if err != nil {
+					logger.Error("Failed to write pod logs",
+						"err", err,
+					)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// listManagedPods returns the pods on the managed nodes.
+func (s *Server) listManagedPods() []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	for _, nodeName := range s.dataSource.ListNodes() {
+		refs, ok := s.dataSource.ListPods(nodeName)
+		if !ok {
+			continue
+		}
+		for _, ref := range refs {
+			pod, ok := s.podCacheGetter.GetWithNamespace(ref.Name, ref.Namespace)
+			if !ok {
+				continue
+			}
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// podLogsWriter writes the logs of the containers to the files.
+type podLogsWriter struct {
+	conf      PodLogsConfig
+	listPods  func() []*corev1.Pod
+	getPod    func(name, namespace string) (*corev1.Pod, bool)
+	getLog    func(podName, podNamespace, container string) (*internalversion.Log, error)
+	now       func() time.Time
+	startTime time.Time
+
+	// containers is the state of the logs of each container, keyed by the dir of the container.
+	containers map[string]*containerLogs
+	// podDirs is the dirs of the pods that have been written.
+	podDirs map[string]struct{}
+	// scannedPodDirs is the dirs of the pods written before the writer started, which are not written yet.
+	scannedPodDirs map[string]*corev1.Pod
+}
+
+// containerLogs is the state of the logs of a container.
+type containerLogs struct {
+	file string
+	size int64
+
+	generatorConf internalversion.LogGenerator
+	generator     *logGenerator
+	next          int64
+
+	source string
+	offset int64
+}
+
+func newPodLogsWriter(conf PodLogsConfig, listPods func() []*corev1.Pod, getPod func(name, namespace string) (*corev1.Pod, bool), getLog func(podName, podNamespace, container string) (*internalversion.Log, error)) *podLogsWriter {
+	return &podLogsWriter{
+		conf:           conf,
+		listPods:       listPods,
+		getPod:         getPod,
+		getLog:         getLog,
+		now:            time.Now,
+		startTime:      time.Now(),
+		containers:     map[string]*containerLogs{},
+		podDirs:        map[string]struct{}{},
+		scannedPodDirs: map[string]*corev1.Pod{},
+	}
+}
+
+// podLogsDir returns the dir of the logs of the pod, which is the same as kubelet.
+func podLogsDir(dir string, pod *corev1.Pod) string {
+	return filepath.Join(dir, pod.Namespace+"_"+pod.Name+"_"+string(pod.UID))
+}
+
+// scan finds the dirs of the pods written before the writer started,
+// so that the logs of the pods deleted while kwok was down are removed too.
+func (w *podLogsWriter) scan() error {
+	entries, err := os.ReadDir(w.conf.Dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		parts := strings.SplitN(entry.Name(), "_", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		pod := &corev1.Pod{}
+		pod.Namespace, pod.Name, pod.UID = parts[0], parts[1], types.UID(parts[2])
+		w.scannedPodDirs[filepath.Join(w.conf.Dir, entry.Name())] = pod
+	}
+	return nil
+}
+
+// sync writes the new logs of the running containers, and removes the logs of the pods that are gone.
+func (w *podLogsWriter) sync() error {
+	now := w.now()
+	var errs []error
+	seen := map[string]struct{}{}
+	for _, pod := range w.listPods() {
+		podDir := podLogsDir(w.conf.Dir, pod)
+		seen[podDir] = struct{}{}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Running == nil {
+				continue
+			}
+			l, err := w.getLog(pod.Name, pod.Namespace, status.Name)
+			if err != nil {
+				continue
+			}
+			err = w.writeContainer(pod, podDir, status, l, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("container %q in pod %q: %w", status.Name, log.KObj(pod), err))
+			}
+		}
+	}
+
+	for podDir := range w.podDirs {
+		if _, ok := seen[podDir]; ok {
+			continue
+		}
+		err := os.RemoveAll(podDir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(w.podDirs, podDir)
+		for containerDir := range w.containers {
+			if filepath.Dir(containerDir) == podDir {
+				delete(w.containers, containerDir)
+			}
+		}
+	}
+
+	// The pods not listed yet may be still alive, only the logs of the pods that no longer exist are removed.
+	for podDir, scanned := range w.scannedPodDirs {
+		if _, ok := seen[podDir]; ok {
+			w.podDirs[podDir] = struct{}{}
+			delete(w.scannedPodDirs, podDir)
+			continue
+		}
+		if pod, ok := w.getPod(scanned.Name, scanned.Namespace); ok && pod.UID == scanned.UID {
+			continue
+		}
+		err := os.RemoveAll(podDir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(w.scannedPodDirs, podDir)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *podLogsWriter) writeContainer(pod *corev1.Pod, podDir string, status corev1.ContainerStatus, l *internalversion.Log, now time.Time) error {
+	containerDir := filepath.Join(podDir, status.Name)
+	file := filepath.Join(containerDir, format.String(status.RestartCount)+".log")
+
+	state, ok := w.containers[containerDir]
+	if !ok || state.file != file {
+		err := os.MkdirAll(containerDir, 0750)
+		if err != nil {
+			return err
+		}
+		w.podDirs[podDir] = struct{}{}
+
+		err = pruneContainerLogs(containerDir, status.RestartCount)
+		if err != nil {
+			return err
+		}
+
+		state = &containerLogs{
+			file: file,
+		}
+		if fi, err := os.Stat(file); err == nil {
+			state.size = fi.Size()
+		}
+		w.containers[containerDir] = state
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if l.Generator != nil {
+		if state.generator == nil || state.generatorConf != *l.Generator {
+			started := status.State.Running.StartedAt.Time
+			g, err := newLogGenerator(l.Generator, started, pod.Name, pod.Namespace, pod.UID, status.Name)
+			if err != nil {
+				return err
+			}
+			// The lines generated before the writer started are skipped, so that they are not written again after restarts.
+			if state.generator == nil && started.Before(w.startTime) {
+				state.next = g.linesUntil(w.startTime)
+			}
+			state.generator = g
+			state.generatorConf = *l.Generator
+		}
+
+		line := bytes.NewBuffer(nil)
+		for end := state.generator.linesUntil(now); state.next < end; state.next++ {
+			line.Reset()
+			err := state.generator.render(line, state.next, false)
+			if err != nil {
+				return err
+			}
+			timestamp := state.generator.timeOf(state.next).Format(crilogs.RFC3339NanoFixed)
+			for _, content := range strings.Split(strings.TrimSuffix(line.String(), "\n"), "\n") {
+				buf.WriteString(timestamp)
+				buf.WriteString(" stdout F ")
+				buf.WriteString(content)
+				buf.WriteByte('\n')
+			}
+		}
+	} else if l.LogsFile != "" {
+		if state.source != l.LogsFile {
+			state.source = l.LogsFile
+			// The offset is restored after restarts, so that the source is not written again.
+			state.offset = loadLogsFileOffset(state.file, l.LogsFile)
+		}
+		source := bytes.NewBuffer(nil)
+		err := readFrom(source, state)
+		if err != nil {
+			return err
+		}
+		if source.Len() == 0 {
+			return nil
+		}
+		err = convertLogs(buf, source.Bytes())
+		if err != nil {
+			return err
+		}
+		err = w.write(state, buf.Bytes(), now)
+		if err != nil {
+			return err
+		}
+		return saveLogsFileOffset(state.file, state.source, state.offset)
+	}
+
+	if buf.Len() == 0 {
+		return nil
+	}
+	return w.write(state, buf.Bytes(), now)
+}
+
+// logsFileOffset is the offset of the source logs file that has been written to a log file.
+type logsFileOffset struct {
+	Source string `json:"source"`
+	Offset int64  `json:"offset"`
+}
+
+// logsFileOffsetPath returns the path of the offset of the log file,
+// which is hidden so that it is not read by the log collectors nor taken as a rotated log file.
+func logsFileOffsetPath(file string) string {
+	return filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".offset")
+}
+
+// loadLogsFileOffset returns the offset of the source written to the log file,
+// it is zero if the log file has not been written from the source.
+func loadLogsFileOffset(file string, source string) int64 {
+	data, err := os.ReadFile(logsFileOffsetPath(file))
+	if err != nil {
+		return 0
+	}
+	var offset logsFileOffset
+	err = json.Unmarshal(data, &offset)
+	if err != nil || offset.Source != source {
+		return 0
+	}
+	return offset.Offset
+}
+
+// saveLogsFileOffset saves the offset of the source written to the log file.
+func saveLogsFileOffset(file string, source string, offset int64) error {
+	data, err := json.Marshal(logsFileOffset{
+		Source: source,
+		Offset: offset,
+	})
+	if err != nil {
+		return err
+	}
+	path := logsFileOffsetPath(file)
+	err = os.WriteFile(path+".tmp", data, 0640)
+	if err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// readFrom reads the new complete lines of the source logs file.
+func readFrom(buf *bytes.Buffer, state *containerLogs) error {
+	f, err := os.Open(state.source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	// The source is truncated, read it from the beginning.
+	if fi.Size() < state.offset {
+		state.offset = 0
+	}
+	if fi.Size() == state.offset {
+		return nil
+	}
+
+	_, err = f.Seek(state.offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(buf, f)
+	if err != nil {
+		return err
+	}
+	// Only the complete lines are written, the rest is read again next time.
+	n := bytes.LastIndexByte(buf.Bytes(), '\n') + 1
+	buf.Truncate(n)
+	state.offset += int64(n)
+	return nil
+}
+
+// convertLogs parses the lines of the source logs file by crilogs,
+// which accepts both the CRI and the docker json-file formats,
+// and writes them to the buf in the CRI format.
+func convertLogs(buf *bytes.Buffer, source []byte) error {
+	f, err := os.CreateTemp("", "kwok-pod-logs-*.log")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	_, err = f.Write(source)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	w := &criLogsWriter{
+		buf:     buf,
+		newLine: true,
+	}
+	opts := &crilogs.LogOptions{
+		Timestamp: true,
+	}
+	return readLogs(context.Background(), f.Name(), opts, w.stream("stdout"), w.stream("stderr"))
+}
+
+// criLogsWriter writes the messages read by crilogs in the CRI format.
+// Each write is a message, which is prefixed with the timestamp only if it starts a new line.
+type criLogsWriter struct {
+	buf       *bytes.Buffer
+	timestamp []byte
+	newLine   bool
+}
+
+func (w *criLogsWriter) stream(stream string) io.Writer {
+	return &criLogsStreamWriter{
+		w:      w,
+		stream: stream,
+	}
+}
+
+type criLogsStreamWriter struct {
+	w      *criLogsWriter
+	stream string
+}
+
+func (s *criLogsStreamWriter) Write(p []byte) (int, error) {
+	w := s.w
+	msg := p
+	if w.newLine {
+		i := bytes.IndexByte(msg, ' ')
+		if i < 0 {
+			return 0, fmt.Errorf("timestamp is not found in %q", p)
+		}
+		w.timestamp = append(w.timestamp[:0], msg[:i]...)
+		msg = msg[i+1:]
+	}
+
+	tag := "P"
+	w.newLine = len(msg) != 0 && msg[len(msg)-1] == '\n'
+	if w.newLine {
+		tag = "F"
+		msg = msg[:len(msg)-1]
+	}
+	lines := bytes.Split(msg, []byte{'\n'})
+	for i, line := range lines {
+		w.buf.Write(w.timestamp)
+		w.buf.WriteByte(' ')
+		w.buf.WriteString(s.stream)
+		if i == len(lines)-1 {
+			w.buf.WriteString(" " + tag + " ")
+		} else {
+			w.buf.WriteString(" F ")
+		}
+		w.buf.Write(line)
+		w.buf.WriteByte('\n')
+	}
+	return len(p), nil
+}
+
+// write appends the data to the log file, and rotates the log file if it is too large.
+func (w *podLogsWriter) write(state *containerLogs, data []byte, now time.Time) error {
+	if w.conf.MaxSizeBytes > 0 && state.size > 0 && state.size+int64(len(data)) > w.conf.MaxSizeBytes {
+		err := w.rotate(state.file, now)
+		if err != nil {
+			return err
+		}
+		state.size = 0
+	}
+
+	f, err := os.OpenFile(state.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	n, err := f.Write(data)
+	state.size += int64(n)
+	return err
+}
+
+// pruneContainerLogs removes the log files of the restarts before the previous one along with their offsets,
+// the same as kubelet which only keeps the logs of the last terminated container for `kubectl logs --previous`.
+func pruneContainerLogs(containerDir string, restartCount int32) error {
+	entries, err := os.ReadDir(containerDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		count, _, ok := strings.Cut(strings.TrimPrefix(entry.Name(), "."), ".log")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(count, 10, 32)
+		if err != nil || int32(n) >= restartCount-1 {
+			continue
+		}
+		err = os.Remove(filepath.Join(containerDir, entry.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotatedLogTimeFormat is the format of the timestamp suffix of the rotated log files, the same as kubelet.
+const rotatedLogTimeFormat = "20060102-150405"
+
+// rotatedLogName returns the name of the rotated log file with the timestamp suffix,
+// a sequence number is appended after the last one if the log file has been rotated in the same second.
+func rotatedLogName(file string, now time.Time) (string, error) {
+	name := file + "." + now.Format(rotatedLogTimeFormat)
+	existing, err := filepath.Glob(name + "*")
+	if err != nil {
+		return "", err
+	}
+	if len(existing) == 0 {
+		return name, nil
+	}
+	last := slices.MaxFunc(existing, compareRotatedLogs)
+	_, seq := splitRotatedLogSeq(last)
+	return name + "-" + strconv.Itoa(seq+1), nil
+}
+
+// splitRotatedLogSeq splits the sequence number from the name of the rotated log file.
+func splitRotatedLogSeq(name string) (string, int) {
+	suffix := filepath.Ext(name)
+	if len(suffix) <= len(rotatedLogTimeFormat)+1 {
+		return name, 0
+	}
+	seq, err := strconv.Atoi(suffix[len(rotatedLogTimeFormat)+2:])
+	if err != nil {
+		return name, 0
+	}
+	return name[:len(name)-len(suffix)+len(rotatedLogTimeFormat)+1], seq
+}
+
+// compareRotatedLogs orders the rotated log files by the timestamp and then the sequence number.
+func compareRotatedLogs(a, b string) int {
+	aName, aSeq := splitRotatedLogSeq(a)
+	bName, bSeq := splitRotatedLogSeq(b)
+	if c := strings.Compare(aName, bName); c != 0 {
+		return c
+	}
+	return aSeq - bSeq
+}
+
+// rotate renames the log file with the timestamp suffix the same as kubelet,
+// and removes the oldest rotated files that exceed the maximum number of files.
+func (w *podLogsWriter) rotate(file string, now time.Time) error {
+	name, err := rotatedLogName(file, now)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(file, name)
+	if err != nil {
+		return err
+	}
+
+	if w.conf.MaxFiles <= 0 {
+		return nil
+	}
+	rotated, err := filepath.Glob(file + ".*")
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(rotated, compareRotatedLogs)
+	for len(rotated) > w.conf.MaxFiles-1 {
+		err = os.Remove(rotated[0])
+		if err != nil {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
@@ -0,0 +1,448 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+func TestPodLogsWriter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(time.Second)
+	dir := t.TempDir()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "uid",
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "app",
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{
+							StartedAt: metav1.NewTime(start),
+						},
+					},
+				},
+				{
+					Name: "waiting",
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{},
+					},
+				},
+			},
+		},
+	}
+	pods := []*corev1.Pod{pod}
+
+	w := newPodLogsWriter(PodLogsConfig{
+		Dir:          dir,
+		MaxSizeBytes: 100,
+		MaxFiles:     2,
+	}, func() []*corev1.Pod {
+		return pods
+	}, func(name, namespace string) (*corev1.Pod, bool) {
+		return nil, false
+	}, func(podName, podNamespace, container string) (*internalversion.Log, error) {
+		return &internalversion.Log{
+			Containers: []string{container},
+			Generator: &internalversion.LogGenerator{
+				Template:       `{{ .Index }} {{ .Container }}`,
+				LinesPerSecond: 2,
+			},
+		}, nil
+	})
+	w.startTime = start
+	w.now = func() time.Time {
+		return now
+	}
+
+	err := w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	file := filepath.Join(dir, "default_pod_uid", "app", "0.log")
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	want := "2024-01-01T00:00:00.000000000Z stdout F 0 app\n" +
+		"2024-01-01T00:00:00.500000000Z stdout F 1 app\n" +
+		"2024-01-01T00:00:01.000000000Z stdout F 2 app\n"
+	if string(got) != want {
+		t.Errorf("expected log file %q, got %q", want, string(got))
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "default_pod_uid", "waiting"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected no logs of the waiting container, got %v", err)
+	}
+
+	// Write more lines than the max size to rotate the log file.
+	for range 4 {
+		now = now.Add(time.Second)
+		err = w.sync()
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+	}
+	rotated, err := filepath.Glob(file + ".*")
+	if err != nil {
+		t.Fatalf("glob rotated files: %v", err)
+	}
+	if len(rotated) != 1 {
+		t.Errorf("expected 1 rotated file, got %v", rotated)
+	}
+	got, err = os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if len(got) > 100 {
+		t.Errorf("expected log file no larger than 100 bytes, got %d", len(got))
+	}
+
+	// Remove the logs of the pod that is gone.
+	pods = nil
+	err = w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	_, err = os.Stat(filepath.Join(dir, "default_pod_uid"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the logs of the pod to be removed, got %v", err)
+	}
+}
+
+func TestPodLogsWriterLogsFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(t.TempDir(), "source.log")
+	line := "2024-01-01T00:00:00.000000000Z stdout F %d\n"
+
+	err := os.WriteFile(source, []byte(fmt.Sprintf(line, 0)+"2024-01-01T00:00:01"), 0640)
+	if err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "uid",
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					RestartCount: 1,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	}
+
+	newWriter := func() *podLogsWriter {
+		return newPodLogsWriter(PodLogsConfig{
+			Dir: dir,
+		}, func() []*corev1.Pod {
+			return []*corev1.Pod{pod}
+		}, func(name, namespace string) (*corev1.Pod, bool) {
+			return pod, true
+		}, func(podName, podNamespace, container string) (*internalversion.Log, error) {
+			return &internalversion.Log{
+				Containers: []string{container},
+				LogsFile:   source,
+			}, nil
+		})
+	}
+	w := newWriter()
+
+	err = w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	file := filepath.Join(dir, "default_pod_uid", "app", "1.log")
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	// The incomplete line is not written.
+	if want := fmt.Sprintf(line, 0); string(got) != want {
+		t.Errorf("expected log file %q, got %q", want, string(got))
+	}
+
+	err = os.WriteFile(source, []byte(fmt.Sprintf(line, 0)+fmt.Sprintf(line, 1)), 0640)
+	if err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	err = w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	got, err = os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if want := fmt.Sprintf(line, 0) + fmt.Sprintf(line, 1); string(got) != want {
+		t.Errorf("expected log file %q, got %q", want, string(got))
+	}
+
+	// The source written before the restart is not written again.
+	err = os.WriteFile(source, []byte(fmt.Sprintf(line, 0)+fmt.Sprintf(line, 1)+fmt.Sprintf(line, 2)), 0640)
+	if err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	w = newWriter()
+	err = w.scan()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	err = w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	got, err = os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	if want := fmt.Sprintf(line, 0) + fmt.Sprintf(line, 1) + fmt.Sprintf(line, 2); string(got) != want {
+		t.Errorf("expected log file %q after restart, got %q", want, string(got))
+	}
+}
+
+func TestPodLogsWriterLogsFileJSON(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(t.TempDir(), "source.log")
+
+	err := os.WriteFile(source, []byte(`{"log":"hello\n","stream":"stdout","time":"2024-01-01T00:00:00.1Z"}
+{"log":"partial ","stream":"stderr","time":"2024-01-01T00:00:01Z"}
+{"log":"line\n","stream":"stderr","time":"2024-01-01T00:00:02Z"}
+`), 0640)
+	if err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			UID:       "uid",
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "app",
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	}
+
+	w := newPodLogsWriter(PodLogsConfig{
+		Dir: dir,
+	}, func() []*corev1.Pod {
+		return []*corev1.Pod{pod}
+	}, func(name, namespace string) (*corev1.Pod, bool) {
+		return pod, true
+	}, func(podName, podNamespace, container string) (*internalversion.Log, error) {
+		return &internalversion.Log{
+			Containers: []string{container},
+			LogsFile:   source,
+		}, nil
+	})
+
+	err = w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "default_pod_uid", "app", "0.log"))
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	want := "2024-01-01T00:00:00.100000000Z stdout F hello\n" +
+		"2024-01-01T00:00:01.000000000Z stderr P partial \n" +
+		"2024-01-01T00:00:01.000000000Z stderr F line\n"
+	if string(got) != want {
+		t.Errorf("expected log file %q, got %q", want, string(got))
+	}
+}
+
+func TestPodLogsWriterScan(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"default_alive_uid-0",
+		"default_deleted_uid-1",
+		"default_recreated_uid-2",
+	} {
+		err := os.MkdirAll(filepath.Join(dir, name, "app"), 0750)
+		if err != nil {
+			t.Fatalf("create pod dir: %v", err)
+		}
+	}
+
+	pods := map[string]*corev1.Pod{
+		"alive": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "alive",
+				Namespace: "default",
+				UID:       "uid-0",
+			},
+		},
+		"recreated": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "recreated",
+				Namespace: "default",
+				UID:       "uid-3",
+			},
+		},
+	}
+	w := newPodLogsWriter(PodLogsConfig{
+		Dir: dir,
+	}, func() []*corev1.Pod {
+		return nil
+	}, func(name, namespace string) (*corev1.Pod, bool) {
+		pod, ok := pods[name]
+		return pod, ok
+	}, nil)
+
+	err := w.scan()
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	err = w.sync()
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	want := []string{
+		"default_alive_uid-0",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected pod dirs %v, got %v", want, got)
+	}
+}
+
+func TestPodLogsWriterRotate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	file := filepath.Join(t.TempDir(), "0.log")
+	w := newPodLogsWriter(PodLogsConfig{
+		MaxFiles: 3,
+	}, nil, nil, nil)
+
+	// Rotate more than ten times in the same second.
+	for i := range 12 {
+		err := os.WriteFile(file, []byte(fmt.Sprint(i)), 0640)
+		if err != nil {
+			t.Fatalf("write log file: %v", err)
+		}
+		err = w.rotate(file, now)
+		if err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+	}
+
+	rotated, err := filepath.Glob(file + ".*")
+	if err != nil {
+		t.Fatalf("glob rotated files: %v", err)
+	}
+	slices.SortFunc(rotated, compareRotatedLogs)
+	var got []string
+	for _, name := range rotated {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read rotated file: %v", err)
+		}
+		got = append(got, filepath.Base(name)+"="+string(data))
+	}
+	want := []string{
+		"0.log.20240101-000000-10=10",
+		"0.log.20240101-000000-11=11",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected rotated files %v, got %v", want, got)
+	}
+}
+
+func TestPruneContainerLogs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"0.log",
+		"0.log.20240101-000000",
+		".0.log.offset",
+		"1.log",
+		".1.log.offset",
+		"1.log.20240101-000000",
+		"2.log",
+		"other",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0640)
+		if err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	err := pruneContainerLogs(dir, 2)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	want := []string{
+		".1.log.offset",
+		"1.log",
+		"1.log.20240101-000000",
+		"2.log",
+		"other",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected files %v, got %v", want, got)
+	}
+}
@@ -78,6 +78,8 @@ type Server struct {
 
 	metricsUpdateHandler utilsmaps.SyncMap[string, *metrics.UpdateHandler]
 
+	execContainers utilsmaps.SyncMap[string, *execContainer]
+
 	cumulatives    map[string]cumulative
 	cumulativesMut sync.Mutex
 
@@ -86,6 +88,8 @@ type Server struct {
 	dataSource      DataSource
 	nodeCacheGetter informer.Getter[*corev1.Node]
 	podCacheGetter  informer.Getter[*corev1.Pod]
+	nodePartitioner NodePartitioner
+	admin           Admin
 }
 
 // DataSource is the interface that provides data for the server handlers.
@@ -115,6 +119,8 @@ type Config struct {
 	DataSource      DataSource
 	NodeCacheGetter informer.Getter[*corev1.Node]
 	PodCacheGetter  informer.Getter[*corev1.Pod]
+	NodePartitioner NodePartitioner
+	Admin           Admin
 }
 
 // NewServer creates a new Server.
@@ -145,6 +151,8 @@ func NewServer(conf Config) (*Server, error) {
 		dataSource:      conf.DataSource,
 		podCacheGetter:  conf.PodCacheGetter,
 		nodeCacheGetter: conf.NodeCacheGetter,
+		nodePartitioner: conf.NodePartitioner,
+		admin:           conf.Admin,
 
 		bufPool: pools.NewPool(func() []byte {
 			return make([]byte, 32*1024)
@@ -506,6 +514,8 @@ func (s *Server) Run(ctx context.Context, address string, certFile, privateKeyFi
 
 	s.ctx = ctx
 
+	go s.gcExecContainers(ctx)
+
 	errCh := make(chan error, 1)
 
 	var handler http.Handler = s.restfulCont
@@ -573,6 +583,8 @@ func (s *Server) Run(ctx context.Context, address string, certFile, privateKeyFi
 		err = ctx.Err()
 	}
 
+	s.removeExecContainers(context.WithoutCancel(ctx))
+
 	return err
 }
 
@@ -0,0 +1,533 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/kwok/metrics"
+	utilsslices "sigs.k8s.io/kwok/pkg/utils/slices"
+)
+
+const (
+	// volumeUsagePrefix is the prefix of the resource name in the ResourceUsage for the used bytes of a volume,
+	// e.g. "volume/data" is the usage of the volume named data.
+	volumeUsagePrefix = "volume/"
+)
+
+// statsSummary is the subset of the Summary API of kubelet that is simulated,
+// the fields are the same as k8s.io/kubelet/pkg/apis/stats/v1alpha1.Summary.
+type statsSummary struct {
+	Node statsNode  `json:"node"`
+	Pods []statsPod `json:"pods"`
+}
+
+// statsNode holds the stats of the node.
+type statsNode struct {
+	NodeName  string       `json:"nodeName"`
+	StartTime metav1.Time  `json:"startTime"`
+	CPU       *statsCPU    `json:"cpu,omitempty"`
+	Memory    *statsMemory `json:"memory,omitempty"`
+	Fs        *statsFs     `json:"fs,omitempty"`
+}
+
+// statsPodReference is the reference to the pod.
+type statsPodReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid"`
+}
+
+// statsPod holds the stats of the pod.
+type statsPod struct {
+	PodRef           statsPodReference `json:"podRef"`
+	StartTime        metav1.Time       `json:"startTime"`
+	Containers       []statsContainer  `json:"containers"`
+	CPU              *statsCPU         `json:"cpu,omitempty"`
+	Memory           *statsMemory      `json:"memory,omitempty"`
+	VolumeStats      []statsVolume     `json:"volume,omitempty"`
+	EphemeralStorage *statsFs          `json:"ephemeral-storage,omitempty"`
+}
+
+// statsContainer holds the stats of the container.
+type statsContainer struct {
+	Name      string       `json:"name"`
+	StartTime metav1.Time  `json:"startTime"`
+	CPU       *statsCPU    `json:"cpu,omitempty"`
+	Memory    *statsMemory `json:"memory,omitempty"`
+	Rootfs    *statsFs     `json:"rootfs,omitempty"`
+}
+
+// statsCPU holds the stats of the CPU.
+type statsCPU struct {
+	Time                 metav1.Time `json:"time"`
+	UsageNanoCores       *uint64     `json:"usageNanoCores,omitempty"`
+	UsageCoreNanoSeconds *uint64     `json:"usageCoreNanoSeconds,omitempty"`
+}
+
+// statsMemory holds the stats of the memory.
+type statsMemory struct {
+	Time            metav1.Time `json:"time"`
+	AvailableBytes  *uint64     `json:"availableBytes,omitempty"`
+	UsageBytes      *uint64     `json:"usageBytes,omitempty"`
+	WorkingSetBytes *uint64     `json:"workingSetBytes,omitempty"`
+	RSSBytes        *uint64     `json:"rssBytes,omitempty"`
+}
+
+// statsFs holds the stats of the filesystem.
+type statsFs struct {
+	Time           metav1.Time `json:"time"`
+	AvailableBytes *uint64     `json:"availableBytes,omitempty"`
+	CapacityBytes  *uint64     `json:"capacityBytes,omitempty"`
+	UsedBytes      *uint64     `json:"usedBytes,omitempty"`
+}
+
+// statsPVCReference is the reference to the persistent volume claim.
+type statsPVCReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// statsVolume holds the stats of the volume.
+type statsVolume struct {
+	statsFs
+	Name   string             `json:"name"`
+	PVCRef *statsPVCReference `json:"pvcRef,omitempty"`
+}
+
+// InstallStatsSummary installs the kubelet Summary API and the /metrics/resource endpoint of the nodes,
+// the stats are derived from the ResourceUsage and ClusterResourceUsage.
+func (s *Server) InstallStatsSummary() error {
+	if s.env == nil {
+		return fmt.Errorf("metrics must be installed before the stats summary")
+	}
+	if s.podCacheGetter == nil {
+		return fmt.Errorf("pod cache is required to serve the stats summary")
+	}
+
+	ws := new(restful.WebService)
+	ws.Path("/nodes")
+	ws.Route(ws.GET("/{nodeName}/stats/summary").
+		To(s.getStatsSummary).
+		Operation("getStatsSummary"))
+	ws.Route(ws.GET("/{nodeName}/metrics/resource").
+		To(s.getResourceMetrics).
+		Operation("getResourceMetrics"))
+	s.restfulCont.Add(ws)
+
+	ws = new(restful.WebService)
+	ws.Path("/stats")
+	ws.Route(ws.GET("/summary").
+		To(s.getStatsSummary).
+		Operation("getStatsSummary"))
+	s.restfulCont.Add(ws)
+	return nil
+}
+
+// statsNodeName returns the node of the request, which is the node in the path,
+// or the only managed node the same as kubelet, or the only node served at the host of the request.
+func (s *Server) statsNodeName(req *restful.Request) (string, error) {
+	nodeName := req.PathParameter("nodeName")
+	if nodeName != "" {
+		return nodeName, nil
+	}
+	nodes := s.dataSource.ListNodes()
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	nodeName, ok := s.nodeNameByHost(nodes, req.Request.Host)
+	if !ok {
+		return "", fmt.Errorf("%d nodes are managed but not one of them is served at %q, use /nodes/{nodeName}/stats/summary instead", len(nodes), req.Request.Host)
+	}
+	return nodeName, nil
+}
+
+// nodeNameByHost returns the only node served at the host, which is the name or an address of the node,
+// and the port of the host is the kubelet port of the node if both are set.
+func (s *Server) nodeNameByHost(nodes []string, hostport string) (string, bool) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, ""
+	}
+	host = strings.Trim(host, "[]")
+
+	found := ""
+	for _, nodeName := range nodes {
+		node, ok := s.nodeCacheGetter.Get(nodeName)
+		if !ok || !nodeServedAt(node, host, port) {
+			continue
+		}
+		if found != "" {
+			return "", false
+		}
+		found = nodeName
+	}
+	return found, found != ""
+}
+
+func nodeServedAt(node *corev1.Node, host, port string) bool {
+	kubeletPort := node.Status.DaemonEndpoints.KubeletEndpoint.Port
+	if port != "" && kubeletPort != 0 && strconv.Itoa(int(kubeletPort)) != port {
+		return false
+	}
+	if node.Name == host {
+		return true
+	}
+	for _, addr := range node.Status.Addresses {
+		if addr.Address == host {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) getStatsSummary(req *restful.Request, resp *restful.Response) {
+	nodeName, err := s.statsNodeName(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	summary, err := s.statsSummary(nodeName, time.Now())
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(resp, summary)
+}
+
+func (s *Server) getResourceMetrics(req *restful.Request, resp *restful.Response) {
+	nodeName := req.PathParameter("nodeName")
+	summary, err := s.statsSummary(nodeName, time.Now())
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusNotFound)
+		return
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(&resourceMetricsCollector{summary: summary})
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(resp.ResponseWriter, req.Request)
+}
+
+// statsSummary returns the summary of the node and the running pods on it.
+func (s *Server) statsSummary(nodeName string, now time.Time) (*statsSummary, error) {
+	node, ok := s.nodeCacheGetter.Get(nodeName)
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	refs, ok := s.dataSource.ListPods(nodeName)
+	if !ok {
+		return nil, fmt.Errorf("node %q is not managed", nodeName)
+	}
+
+	ts := metav1.NewTime(now)
+	summary := &statsSummary{
+		Node: statsNode{
+			NodeName:  nodeName,
+			StartTime: node.CreationTimestamp,
+		},
+		Pods: []statsPod{},
+	}
+
+	var cpu, memory, storage float64
+	for _, ref := range refs {
+		pod, ok := s.podCacheGetter.GetWithNamespace(ref.Name, ref.Namespace)
+		if !ok || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		ps, usage := s.podStats(node, pod, ts)
+		summary.Pods = append(summary.Pods, ps)
+		cpu += usage.cpu
+		memory += usage.memory
+		storage += usage.storage
+	}
+
+	summary.Node.CPU = &statsCPU{
+		Time:                 ts,
+		UsageNanoCores:       statsUint64(cpu * 1e9),
+		UsageCoreNanoSeconds: statsUint64(s.cumulativeUsage(nodeName, cpu) * 1e9),
+	}
+	summary.Node.Memory = &statsMemory{
+		Time:            ts,
+		AvailableBytes:  statsAvailable(node.Status.Capacity, corev1.ResourceMemory, memory),
+		UsageBytes:      statsUint64(memory),
+		WorkingSetBytes: statsUint64(memory),
+		RSSBytes:        statsUint64(memory),
+	}
+	summary.Node.Fs = &statsFs{
+		Time:           ts,
+		AvailableBytes: statsAvailable(node.Status.Capacity, corev1.ResourceEphemeralStorage, storage),
+		CapacityBytes:  statsCapacity(node.Status.Capacity, corev1.ResourceEphemeralStorage),
+		UsedBytes:      statsUint64(storage),
+	}
+	return summary, nil
+}
+
+// statsUsage is the usage of the resources.
+type statsUsage struct {
+	cpu     float64
+	memory  float64
+	storage float64
+}
+
+// podStats returns the stats of the pod and its usage of the resources.
+func (s *Server) podStats(node *corev1.Node, pod *corev1.Pod, ts metav1.Time) (statsPod, statsUsage) {
+	ps := statsPod{
+		PodRef: statsPodReference{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+		},
+		Containers: []statsContainer{},
+	}
+	if pod.Status.StartTime != nil {
+		ps.StartTime = *pod.Status.StartTime
+	}
+
+	data := metrics.Data{
+		Node: node,
+		Pod:  pod,
+	}
+	var usage statsUsage
+	var cpuTotal float64
+	usages := map[string]*internalversion.ResourceUsageContainer{}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running == nil {
+			continue
+		}
+		container, ok := utilsslices.Find(pod.Spec.Containers, func(c corev1.Container) bool {
+			return c.Name == status.Name
+		})
+		if !ok {
+			continue
+		}
+		data.Container = &container
+
+		var cpu, memory, storage float64
+		u, err := s.getResourceUsage(pod.Name, pod.Namespace, container.Name)
+		if err == nil {
+			usages[container.Name] = u
+			cpu = s.evaluateResourceUsage(u, string(corev1.ResourceCPU), data)
+			memory = s.evaluateResourceUsage(u, string(corev1.ResourceMemory), data)
+			storage = s.evaluateResourceUsage(u, string(corev1.ResourceEphemeralStorage), data)
+		}
+		cumulative := s.cumulativeUsage(containerCumulativeKey(string(corev1.ResourceCPU), pod.Namespace, pod.Name, container.Name), cpu)
+
+		ps.Containers = append(ps.Containers, statsContainer{
+			Name:      container.Name,
+			StartTime: status.State.Running.StartedAt,
+			CPU: &statsCPU{
+				Time:                 ts,
+				UsageNanoCores:       statsUint64(cpu * 1e9),
+				UsageCoreNanoSeconds: statsUint64(cumulative * 1e9),
+			},
+			Memory: &statsMemory{
+				Time:            ts,
+				AvailableBytes:  statsAvailable(container.Resources.Limits, corev1.ResourceMemory, memory),
+				UsageBytes:      statsUint64(memory),
+				WorkingSetBytes: statsUint64(memory),
+				RSSBytes:        statsUint64(memory),
+			},
+			Rootfs: &statsFs{
+				Time:      ts,
+				UsedBytes: statsUint64(storage),
+			},
+		})
+		usage.cpu += cpu
+		usage.memory += memory
+		usage.storage += storage
+		cpuTotal += cumulative
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		// The used bytes of the volume are summed over the containers that mount it.
+		var used float64
+		for _, container := range pod.Spec.Containers {
+			u, ok := usages[container.Name]
+			if !ok || !slices.ContainsFunc(container.VolumeMounts, func(m corev1.VolumeMount) bool {
+				return m.Name == volume.Name
+			}) {
+				continue
+			}
+			data.Container = &container
+			used += s.evaluateResourceUsage(u, volumeUsagePrefix+volume.Name, data)
+		}
+		vs := statsVolume{
+			statsFs: statsFs{
+				Time:      ts,
+				UsedBytes: statsUint64(used),
+			},
+			Name: volume.Name,
+		}
+		switch {
+		case volume.EmptyDir != nil:
+			if limit := volume.EmptyDir.SizeLimit; limit != nil {
+				vs.CapacityBytes = statsUint64(limit.AsApproximateFloat64())
+				vs.AvailableBytes = statsUint64(limit.AsApproximateFloat64() - used)
+			}
+			// The emptyDir volumes are on the ephemeral storage of the node.
+			usage.storage += used
+		case volume.PersistentVolumeClaim != nil:
+			vs.PVCRef = &statsPVCReference{
+				Name:      volume.PersistentVolumeClaim.ClaimName,
+				Namespace: pod.Namespace,
+			}
+		case volume.Ephemeral != nil:
+			vs.PVCRef = &statsPVCReference{
+				Name:      pod.Name + "-" + volume.Name,
+				Namespace: pod.Namespace,
+			}
+		}
+		ps.VolumeStats = append(ps.VolumeStats, vs)
+	}
+
+	ps.CPU = &statsCPU{
+		Time:                 ts,
+		UsageNanoCores:       statsUint64(usage.cpu * 1e9),
+		UsageCoreNanoSeconds: statsUint64(cpuTotal * 1e9),
+	}
+	ps.Memory = &statsMemory{
+		Time:            ts,
+		UsageBytes:      statsUint64(usage.memory),
+		WorkingSetBytes: statsUint64(usage.memory),
+		RSSBytes:        statsUint64(usage.memory),
+	}
+	ps.EphemeralStorage = &statsFs{
+		Time:      ts,
+		UsedBytes: statsUint64(usage.storage),
+	}
+	return ps, usage
+}
+
+func statsUint64(v float64) *uint64 {
+	if v < 0 {
+		v = 0
+	}
+	u := uint64(v)
+	return &u
+}
+
+func statsCapacity(list corev1.ResourceList, name corev1.ResourceName) *uint64 {
+	q, ok := list[name]
+	if !ok {
+		return nil
+	}
+	return statsUint64(q.AsApproximateFloat64())
+}
+
+func statsAvailable(list corev1.ResourceList, name corev1.ResourceName, used float64) *uint64 {
+	q, ok := list[name]
+	if !ok {
+		return nil
+	}
+	return statsUint64(q.AsApproximateFloat64() - used)
+}
+
+var (
+	nodeCPUUsageDesc = prometheus.NewDesc("node_cpu_usage_seconds_total",
+		"[ALPHA] Cumulative cpu time consumed by the node in core-seconds",
+		nil, nil)
+	nodeMemoryWorkingSetDesc = prometheus.NewDesc("node_memory_working_set_bytes",
+		"[ALPHA] Current working set of the node in bytes",
+		nil, nil)
+	podCPUUsageDesc = prometheus.NewDesc("pod_cpu_usage_seconds_total",
+		"[ALPHA] Cumulative cpu time consumed by the pod in core-seconds",
+		[]string{"pod", "namespace"}, nil)
+	podMemoryWorkingSetDesc = prometheus.NewDesc("pod_memory_working_set_bytes",
+		"[ALPHA] Current working set of the pod in bytes",
+		[]string{"pod", "namespace"}, nil)
+	containerCPUUsageDesc = prometheus.NewDesc("container_cpu_usage_seconds_total",
+		"[ALPHA] Cumulative cpu time consumed by the container in core-seconds",
+		[]string{"container", "pod", "namespace"}, nil)
+	containerMemoryWorkingSetDesc = prometheus.NewDesc("container_memory_working_set_bytes",
+		"[ALPHA] Current working set of the container in bytes",
+		[]string{"container", "pod", "namespace"}, nil)
+	containerStartTimeDesc = prometheus.NewDesc("container_start_time_seconds",
+		"[ALPHA] Start time of the container since unix epoch in seconds",
+		[]string{"container", "pod", "namespace"}, nil)
+	resourceScrapeErrorDesc = prometheus.NewDesc("resource_scrape_error",
+		"[ALPHA] 1 if there was an error while getting container metrics, 0 otherwise",
+		nil, nil)
+)
+
+// resourceMetricsCollector collects the metrics of /metrics/resource of kubelet from the summary.
+type resourceMetricsCollector struct {
+	summary *statsSummary
+}
+
+// Describe implements prometheus.Collector.
+func (c *resourceMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nodeCPUUsageDesc
+	ch <- nodeMemoryWorkingSetDesc
+	ch <- podCPUUsageDesc
+	ch <- podMemoryWorkingSetDesc
+	ch <- containerCPUUsageDesc
+	ch <- containerMemoryWorkingSetDesc
+	ch <- containerStartTimeDesc
+	ch <- resourceScrapeErrorDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *resourceMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(resourceScrapeErrorDesc, prometheus.GaugeValue, 0)
+
+	node := c.summary.Node
+	collectCPU(ch, nodeCPUUsageDesc, node.CPU)
+	collectMemory(ch, nodeMemoryWorkingSetDesc, node.Memory)
+
+	for _, pod := range c.summary.Pods {
+		collectCPU(ch, podCPUUsageDesc, pod.CPU, pod.PodRef.Name, pod.PodRef.Namespace)
+		collectMemory(ch, podMemoryWorkingSetDesc, pod.Memory, pod.PodRef.Name, pod.PodRef.Namespace)
+
+		for _, container := range pod.Containers {
+			collectCPU(ch, containerCPUUsageDesc, container.CPU, container.Name, pod.PodRef.Name, pod.PodRef.Namespace)
+			collectMemory(ch, containerMemoryWorkingSetDesc, container.Memory, container.Name, pod.PodRef.Name, pod.PodRef.Namespace)
+			ch <- prometheus.MustNewConstMetric(containerStartTimeDesc, prometheus.GaugeValue,
+				float64(container.StartTime.UnixNano())/float64(time.Second),
+				container.Name, pod.PodRef.Name, pod.PodRef.Namespace)
+		}
+	}
+}
+
+func collectCPU(ch chan<- prometheus.Metric, desc *prometheus.Desc, cpu *statsCPU, labels ...string) {
+	if cpu == nil || cpu.UsageCoreNanoSeconds == nil {
+		return
+	}
+	ch <- prometheus.NewMetricWithTimestamp(cpu.Time.Time,
+		prometheus.MustNewConstMetric(desc, prometheus.CounterValue,
+			float64(*cpu.UsageCoreNanoSeconds)/float64(time.Second), labels...))
+}
+
+func collectMemory(ch chan<- prometheus.Metric, desc *prometheus.Desc, memory *statsMemory, labels ...string) {
+	if memory == nil || memory.WorkingSetBytes == nil {
+		return
+	}
+	ch <- prometheus.NewMetricWithTimestamp(memory.Time.Time,
+		prometheus.MustNewConstMetric(desc, prometheus.GaugeValue,
+			float64(*memory.WorkingSetBytes), labels...))
+}
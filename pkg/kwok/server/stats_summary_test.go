@@ -0,0 +1,331 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/log"
+)
+
+type fakeStatsDataSource struct {
+	pods map[string][]log.ObjectRef
+}
+
+func (f *fakeStatsDataSource) ListNodes() []string {
+	nodes := []string{}
+	for name := range f.pods {
+		nodes = append(nodes, name)
+	}
+	return nodes
+}
+
+func (f *fakeStatsDataSource) ListPods(nodeName string) ([]log.ObjectRef, bool) {
+	pods, ok := f.pods[nodeName]
+	return pods, ok
+}
+
+func (f *fakeStatsDataSource) StartedContainersTotal(nodeName string) int64 {
+	return 0
+}
+
+type fakeGetter[T runtime.Object] struct {
+	objs map[string]T
+}
+
+func (f *fakeGetter[T]) Get(name string) (T, bool) {
+	obj, ok := f.objs[name]
+	return obj, ok
+}
+
+func (f *fakeGetter[T]) GetWithNamespace(name, namespace string) (T, bool) {
+	obj, ok := f.objs[namespace+"/"+name]
+	return obj, ok
+}
+
+func (f *fakeGetter[T]) List() []T {
+	objs := []T{}
+	for _, obj := range f.objs {
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+func TestStatsSummary(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node0",
+		},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+			DaemonEndpoints: corev1.NodeDaemonEndpoints{
+				KubeletEndpoint: corev1.DaemonEndpoint{Port: 10250},
+			},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceMemory:           resource.MustParse("1Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+			},
+		},
+	}
+	running := corev1.ContainerState{
+		Running: &corev1.ContainerStateRunning{},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod0",
+			Namespace: "default",
+			UID:       "uid0",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node0",
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "data"},
+					},
+				},
+				{
+					Name: "sidecar",
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: "claim0",
+						},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", State: running},
+				{Name: "sidecar", State: running},
+			},
+		},
+	}
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "default",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+		},
+	}
+
+	dataSource := &fakeStatsDataSource{
+		pods: map[string][]log.ObjectRef{
+			"node0": {
+				{Name: "pod0", Namespace: "default"},
+				{Name: "pod1", Namespace: "default"},
+			},
+		},
+	}
+	nodeGetter := &fakeGetter[*corev1.Node]{
+		objs: map[string]*corev1.Node{"node0": node},
+	}
+
+	svc, err := NewServer(Config{
+		ResourceUsages: []*internalversion.ResourceUsage{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod0",
+					Namespace: "default",
+				},
+				Spec: internalversion.ResourceUsageSpec{
+					Usages: []internalversion.ResourceUsageContainer{
+						{
+							Usage: map[string]internalversion.ResourceUsageValue{
+								"cpu": {
+									Value: new(resource.MustParse("500m")),
+								},
+								"memory": {
+									Expression: new(`Quantity("100Mi")`),
+								},
+								"ephemeral-storage": {
+									Value: new(resource.MustParse("1Mi")),
+								},
+								"volume/data": {
+									Value: new(resource.MustParse("2Mi")),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		DataSource:      dataSource,
+		NodeCacheGetter: nodeGetter,
+		PodCacheGetter: &fakeGetter[*corev1.Pod]{
+			objs: map[string]*corev1.Pod{
+				"default/pod0": pod,
+				"default/pod1": pending,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.ctx = context.Background()
+	err = svc.InstallMetrics(svc.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = svc.InstallStatsSummary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/nodes/node0/stats/summary", "/stats/summary"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			resp := httptest.NewRecorder()
+			svc.restfulCont.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+			}
+
+			var summary statsSummary
+			err := json.Unmarshal(resp.Body.Bytes(), &summary)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if summary.Node.NodeName != "node0" {
+				t.Errorf("expected node node0, got %q", summary.Node.NodeName)
+			}
+			if got, want := *summary.Node.CPU.UsageNanoCores, uint64(1e9); got != want {
+				t.Errorf("expected node usage nano cores %d, got %d", want, got)
+			}
+			if got, want := *summary.Node.Memory.WorkingSetBytes, uint64(200<<20); got != want {
+				t.Errorf("expected node working set bytes %d, got %d", want, got)
+			}
+			if got, want := *summary.Node.Memory.AvailableBytes, uint64(1<<30-200<<20); got != want {
+				t.Errorf("expected node available bytes %d, got %d", want, got)
+			}
+			if got, want := *summary.Node.Fs.UsedBytes, uint64(2<<20); got != want {
+				t.Errorf("expected node fs used bytes %d, got %d", want, got)
+			}
+
+			if len(summary.Pods) != 1 {
+				t.Fatalf("expected 1 running pod, got %d", len(summary.Pods))
+			}
+			ps := summary.Pods[0]
+			if ps.PodRef != (statsPodReference{Name: "pod0", Namespace: "default", UID: "uid0"}) {
+				t.Errorf("unexpected pod ref %+v", ps.PodRef)
+			}
+			if len(ps.Containers) != 2 {
+				t.Fatalf("expected 2 containers, got %d", len(ps.Containers))
+			}
+			if got, want := *ps.Containers[0].CPU.UsageNanoCores, uint64(5e8); got != want {
+				t.Errorf("expected container usage nano cores %d, got %d", want, got)
+			}
+			if got, want := *ps.Memory.WorkingSetBytes, uint64(200<<20); got != want {
+				t.Errorf("expected pod working set bytes %d, got %d", want, got)
+			}
+			if len(ps.VolumeStats) != 1 {
+				t.Fatalf("expected 1 volume, got %d", len(ps.VolumeStats))
+			}
+			vs := ps.VolumeStats[0]
+			if vs.Name != "data" || vs.PVCRef == nil || vs.PVCRef.Name != "claim0" {
+				t.Errorf("unexpected volume %+v", vs)
+			}
+			// Only the app container mounts the volume.
+			if got, want := *vs.UsedBytes, uint64(2<<20); got != want {
+				t.Errorf("expected volume used bytes %d, got %d", want, got)
+			}
+		})
+	}
+
+	t.Run("node by host", func(t *testing.T) {
+		other := node.DeepCopy()
+		other.Name = "node1"
+		other.Status.Addresses = []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+		}
+		dataSource.pods["node1"] = nil
+		nodeGetter.objs["node1"] = other
+		defer func() {
+			delete(dataSource.pods, "node1")
+			delete(nodeGetter.objs, "node1")
+		}()
+
+		for host, want := range map[string]int{
+			"10.0.0.1:10250": http.StatusOK,
+			"10.0.0.1":       http.StatusOK,
+			"node0:10250":    http.StatusOK,
+			"10.0.0.1:10255": http.StatusBadRequest,
+			"10.0.0.3:10250": http.StatusBadRequest,
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/stats/summary", nil)
+			req.Host = host
+			resp := httptest.NewRecorder()
+			svc.restfulCont.ServeHTTP(resp, req)
+			if resp.Code != want {
+				t.Errorf("expected status %d for host %q, got %d: %s", want, host, resp.Code, resp.Body.String())
+			}
+		}
+	})
+
+	t.Run("unknown node", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/nodes/node1/stats/summary", nil)
+		resp := httptest.NewRecorder()
+		svc.restfulCont.ServeHTTP(resp, req)
+		if resp.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.Code)
+		}
+	})
+
+	t.Run("resource metrics", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/nodes/node0/metrics/resource", nil)
+		resp := httptest.NewRecorder()
+		svc.restfulCont.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, resp.Code, resp.Body.String())
+		}
+		body := resp.Body.String()
+		for _, want := range []string{
+			`container_cpu_usage_seconds_total{container="app",namespace="default",pod="pod0"}`,
+			`container_memory_working_set_bytes{container="app",namespace="default",pod="pod0"} 1.048576e+08`,
+			`pod_memory_working_set_bytes{namespace="default",pod="pod0"} 2.097152e+08`,
+			`node_memory_working_set_bytes 2.097152e+08`,
+			`resource_scrape_error 0`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected %q in the metrics, got:\n%s", want, body)
+			}
+		}
+	})
+}
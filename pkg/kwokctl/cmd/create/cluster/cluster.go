@@ -206,6 +206,8 @@ func NewCommand(ctx context.Context) *cobra.Command {
 			v1alpha1.ClusterLogsKind,
 			v1alpha1.ResourceUsageKind,
 			v1alpha1.ClusterResourceUsageKind,
+			v1alpha1.ProbeKind,
+			v1alpha1.ClusterProbeKind,
 			v1alpha1.MetricKind,
 		}
 
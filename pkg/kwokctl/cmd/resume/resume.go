@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resume implements the resume command
+package resume
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kwok/pkg/config"
+	"sigs.k8s.io/kwok/pkg/kwokctl/runtime"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/completion"
+	utilspath "sigs.k8s.io/kwok/pkg/utils/path"
+)
+
+type flagpole struct {
+	Name   string
+	Delays string
+}
+
+// NewCommand returns a new cobra.Command for resuming the simulation
+func NewCommand(ctx context.Context) *cobra.Command {
+	flags := &flagpole{
+		Delays: "preserve",
+	}
+	cmd := &cobra.Command{
+		Args:              cobra.NoArgs,
+		Use:               "resume",
+		Short:             "Resume the simulation of the cluster",
+		GroupID:           "cluster",
+		ValidArgsFunction: completion.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.Name = config.DefaultCluster
+			return runE(cmd.Context(), flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Delays, "delays", flags.Delays, `How to handle the delays of the stages when resuming, one of [preserve, shift]
+preserve: the stages keep their scheduled time, the ones that are due while paused are played immediately
+shift: the stages are shifted by the paused duration, the remaining delays are the same as when paused`)
+	return cmd
+}
+
+func runE(ctx context.Context, flags *flagpole) error {
+	switch flags.Delays {
+	case "preserve", "shift":
+	default:
+		return fmt.Errorf("unknown delays %q, must be preserve or shift", flags.Delays)
+	}
+
+	name := flags.Name
+	workdir := utilspath.Join(config.ClustersDir, flags.Name)
+
+	logger := log.FromContext(ctx)
+	logger = logger.With(
+		"cluster", flags.Name,
+	)
+	ctx = log.NewContext(ctx, logger)
+
+	rt, err := runtime.DefaultRegistry.Load(ctx, name, workdir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Cluster does not exist")
+		}
+		return err
+	}
+
+	_, err = runtime.KwokControllerAdmin(ctx, rt, http.MethodPost, "/admin/resume?delays="+url.QueryEscape(flags.Delays))
+	if err != nil {
+		return err
+	}
+	logger.Info("Simulation is resumed",
+		"delays", flags.Delays,
+	)
+	return nil
+}
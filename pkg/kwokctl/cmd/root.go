@@ -32,9 +32,12 @@ import (
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/kectl"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/kubectl"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/logs"
+	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/pause"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/port_forward"
+	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/resume"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/scale"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/snapshot"
+	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/stage"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/start"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/stop"
 	"sigs.k8s.io/kwok/pkg/kwokctl/dryrun"
@@ -65,6 +68,8 @@ func NewCommand(ctx context.Context) *cobra.Command {
 		del.NewCommand(ctx),
 		start.NewCommand(ctx),
 		stop.NewCommand(ctx),
+		pause.NewCommand(ctx),
+		resume.NewCommand(ctx),
 		get.NewCommand(ctx),
 		snapshot.NewCommand(ctx),
 		logs.NewCommand(ctx),
@@ -81,6 +86,7 @@ func NewCommand(ctx context.Context) *cobra.Command {
 		kubectl.NewCommand(ctx),
 		etcdctl.NewCommand(ctx),
 		kectl.NewCommand(ctx),
+		stage.NewCommand(ctx),
 	)
 	cmd.AddGroup(
 		&cobra.Group{ID: "tool", Title: "Tool Commands:"},
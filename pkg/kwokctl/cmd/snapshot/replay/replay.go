@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay provides a command to replay the recording of a cluster.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"sigs.k8s.io/kwok/pkg/config"
+	"sigs.k8s.io/kwok/pkg/kwokctl/runtime"
+	"sigs.k8s.io/kwok/pkg/kwokctl/snapshot"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/completion"
+	"sigs.k8s.io/kwok/pkg/utils/file"
+	utilspath "sigs.k8s.io/kwok/pkg/utils/path"
+)
+
+type flagpole struct {
+	Name    string
+	Path    string
+	Filters []string
+	Speed   float64
+	StartAt time.Duration
+	StopAt  time.Duration
+}
+
+// NewCommand returns a new cobra.Command to replay the recording of the cluster.
+func NewCommand(ctx context.Context) *cobra.Command {
+	flags := &flagpole{
+		Filters: snapshot.Resources,
+		Speed:   1,
+	}
+
+	cmd := &cobra.Command{
+		Args:              cobra.NoArgs,
+		Use:               "replay",
+		Short:             "[experimental] Restore the snapshot of the cluster and replay the recorded resource changes",
+		ValidArgsFunction: completion.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.Name = config.DefaultCluster
+			return runE(cmd.Context(), flags)
+		},
+	}
+	cmd.Flags().StringVar(&flags.Path, "path", flags.Path, "Path to the recording")
+	cmd.Flags().StringSliceVar(&flags.Filters, "filter", flags.Filters, "Filter the resources to replay")
+	_ = cmd.RegisterFlagCompletionFunc("filter", completion.FixedCompletions(snapshot.Resources))
+	cmd.Flags().Float64Var(&flags.Speed, "speed", flags.Speed, "Speed multiplier of the recorded timing, e.g. 0.1 for slow motion or 100 for fast forward")
+	cmd.Flags().DurationVar(&flags.StartAt, "start-at", flags.StartAt, "Offset of the recording to start the replay at, the changes before it are applied immediately")
+	cmd.Flags().DurationVar(&flags.StopAt, "stop-at", flags.StopAt, "Offset of the recording to stop the replay at, 0 means replaying until the end")
+	return cmd
+}
+
+func runE(ctx context.Context, flags *flagpole) error {
+	name := flags.Name
+	workdir := utilspath.Join(config.ClustersDir, flags.Name)
+	if flags.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if !file.Exists(flags.Path) {
+		return fmt.Errorf("path %q does not exist", flags.Path)
+	}
+	if flags.Speed <= 0 {
+		return fmt.Errorf("speed must be greater than 0")
+	}
+	if flags.StartAt < 0 {
+		return fmt.Errorf("start-at must not be negative")
+	}
+	if flags.StopAt != 0 && flags.StopAt < flags.StartAt {
+		return fmt.Errorf("stop-at must not be less than start-at")
+	}
+
+	logger := log.FromContext(ctx)
+	logger = logger.With(
+		"cluster", flags.Name,
+	)
+	ctx = log.NewContext(ctx, logger)
+
+	rt, err := runtime.DefaultRegistry.Load(ctx, name, workdir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Cluster does not exist")
+		}
+		return err
+	}
+
+	var pause chan struct{}
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		pause = make(chan struct{})
+		go togglePauseOnEnter(ctx, pause)
+		logger.Info("Press Enter to pause or resume replaying, Ctrl+C to stop replaying")
+	}
+
+	err = rt.SnapshotRestoreWithYAML(ctx, flags.Path, runtime.SnapshotRestoreWithYAMLConfig{
+		Filters: flags.Filters,
+		Replay: snapshot.ReplayConfig{
+			Speed:   flags.Speed,
+			StartAt: flags.StartAt,
+			StopAt:  flags.StopAt,
+			Pause:   pause,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// togglePauseOnEnter sends to the pause channel on each line read from the stdin.
+func togglePauseOnEnter(ctx context.Context, pause chan<- struct{}) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		_, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case pause <- struct{}{}:
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/snapshot/export"
+	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/snapshot/replay"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/snapshot/restore"
 	"sigs.k8s.io/kwok/pkg/kwokctl/cmd/snapshot/save"
 )
@@ -32,7 +33,7 @@ func NewCommand(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Args:    cobra.NoArgs,
 		Use:     "snapshot [command]",
-		Short:   "[experimental] Snapshot [save, restore, export] one of cluster",
+		Short:   "[experimental] Snapshot [save, restore, export, replay] one of cluster",
 		GroupID: "cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
@@ -41,5 +42,6 @@ func NewCommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(save.NewCommand(ctx))
 	cmd.AddCommand(restore.NewCommand(ctx))
 	cmd.AddCommand(export.NewCommand(ctx))
+	cmd.AddCommand(replay.NewCommand(ctx))
 	return cmd
 }
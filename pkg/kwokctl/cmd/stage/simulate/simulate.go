@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulate provides a command to simulate the stages offline.
+package simulate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/config"
+	"sigs.k8s.io/kwok/pkg/kwokctl/stage"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/completion"
+	"sigs.k8s.io/kwok/pkg/utils/yaml"
+)
+
+type flagpole struct {
+	Stages   []string
+	Inputs   []string
+	MaxDepth int
+	Output   string
+}
+
+// NewCommand returns a new cobra.Command to simulate the stages offline.
+func NewCommand(ctx context.Context) *cobra.Command {
+	flags := &flagpole{
+		MaxDepth: 10,
+		Output:   "tree",
+	}
+
+	cmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "simulate",
+		Short: "Simulate the lifecycle of resources by playing the stages without a cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runE(cmd.Context(), flags)
+		},
+	}
+	cmd.Flags().StringSliceVar(&flags.Stages, "stage", flags.Stages, "Path to the files of stages")
+	cmd.Flags().StringSliceVar(&flags.Inputs, "input", flags.Inputs, "Path to the files of resources to simulate")
+	cmd.Flags().IntVar(&flags.MaxDepth, "max-depth", flags.MaxDepth, "Max number of stages played in a row")
+	cmd.Flags().StringVarP(&flags.Output, "output", "o", flags.Output, "Output format (tree, yaml)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completion.FixedCompletions([]string{"tree", "yaml"}))
+	return cmd
+}
+
+func runE(ctx context.Context, flags *flagpole) error {
+	if len(flags.Stages) == 0 {
+		return fmt.Errorf("stage is required")
+	}
+	if len(flags.Inputs) == 0 {
+		return fmt.Errorf("input is required")
+	}
+	if flags.MaxDepth <= 0 {
+		return fmt.Errorf("max-depth must be greater than 0")
+	}
+	if flags.Output != "tree" && flags.Output != "yaml" {
+		return fmt.Errorf("unknown output format %q", flags.Output)
+	}
+
+	logger := log.FromContext(ctx)
+
+	sio, err := config.Load(ctx, flags.Stages...)
+	if err != nil {
+		return fmt.Errorf("failed to load stages: %w", err)
+	}
+	stages := config.FilterWithType[*internalversion.Stage](sio)
+	if len(stages) == 0 {
+		return fmt.Errorf("no stages found in %v", flags.Stages)
+	}
+
+	rio, err := config.LoadUnstructured(flags.Inputs...)
+	if err != nil {
+		return fmt.Errorf("failed to load inputs: %w", err)
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	for _, r := range rio {
+		obj, ok := r.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+
+		transitions, err := stage.Simulate(ctx, obj, stage.SimulateConfig{
+			Stages:   stages,
+			MaxDepth: flags.MaxDepth,
+		})
+		if err != nil {
+			logger.Error("Failed to simulate",
+				"err", err,
+				"kind", obj.GetKind(),
+				"name", log.KObj(obj),
+			)
+			continue
+		}
+
+		switch flags.Output {
+		case "yaml":
+			err = encoder.Encode(map[string]any{
+				"apiVersion":  obj.GetAPIVersion(),
+				"kind":        obj.GetKind(),
+				"name":        obj.GetName(),
+				"namespace":   obj.GetNamespace(),
+				"transitions": transitions,
+			})
+		case "tree":
+			err = printTree(os.Stdout, obj, transitions)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTree prints the transitions of the resource as a tree.
+func printTree(w io.Writer, obj *unstructured.Unstructured, transitions []*stage.Transition) error {
+	_, err := fmt.Fprintf(w, "%s %s\n", obj.GetKind(), log.KObj(obj))
+	if err != nil {
+		return err
+	}
+	return printTransitions(w, transitions, "")
+}
+
+func printTransitions(w io.Writer, transitions []*stage.Transition, prefix string) error {
+	for i, t := range transitions {
+		branch, indent := "├── ", "│   "
+		if i == len(transitions)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		_, err := fmt.Fprintf(w, "%s%s%s\n", prefix, branch, formatTransition(t))
+		if err != nil {
+			return err
+		}
+
+		err = printTransitions(w, t.Next, prefix+indent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatTransition(t *stage.Transition) string {
+	attrs := []string{}
+	if t.Weight != nil {
+		attrs = append(attrs, fmt.Sprintf("weight: %d", *t.Weight))
+	}
+	if t.FailureRate != nil {
+		attrs = append(attrs, fmt.Sprintf("failure rate: %d%%", *t.FailureRate))
+	}
+	switch len(t.Delay) {
+	case 1:
+		attrs = append(attrs, fmt.Sprintf("delay: %s", t.Delay[0].Duration))
+	case 2:
+		attrs = append(attrs, fmt.Sprintf("delay: %s-%s", t.Delay[0].Duration, t.Delay[1].Duration))
+	}
+	for _, wait := range t.Waits {
+		attrs = append(attrs, fmt.Sprintf("wait: %s", wait))
+	}
+	for _, event := range t.Events {
+		attrs = append(attrs, fmt.Sprintf("event: %s", event.Reason))
+	}
+	switch {
+	case t.Error != "":
+		attrs = append(attrs, fmt.Sprintf("error: %s", t.Error))
+	case t.Deleted:
+		attrs = append(attrs, "deleted")
+	case t.Loop:
+		attrs = append(attrs, "loop")
+	case t.Truncated:
+		attrs = append(attrs, "truncated")
+	}
+	if len(attrs) == 0 {
+		return t.Stage
+	}
+	return fmt.Sprintf("%s (%s)", t.Stage, strings.Join(attrs, ", "))
+}
@@ -42,6 +42,7 @@ type BuildKwokControllerComponentConfig struct {
 	CaCertPath                        string
 	AdminCertPath                     string
 	AdminKeyPath                      string
+	AdminTokenPath                    string
 	NodeIP                            string
 	NodeName                          string
 	ManageNodesWithAnnotationSelector string
@@ -119,6 +120,18 @@ func BuildKwokControllerComponent(conf BuildKwokControllerComponentConfig) (comp
 			"--server-address="+conf.BindAddress+":10247",
 			"--node-lease-duration-seconds="+format.String(conf.NodeLeaseDurationSeconds),
 		)
+		if conf.AdminTokenPath != "" {
+			volumes = append(volumes,
+				internalversion.Volume{
+					HostPath:  conf.AdminTokenPath,
+					MountPath: pkiAdminTokenPath,
+					ReadOnly:  true,
+				},
+			)
+			args = append(args,
+				"--admin-token-file="+pkiAdminTokenPath,
+			)
+		}
 	} else {
 		ports = append(
 			ports,
@@ -140,6 +153,11 @@ func BuildKwokControllerComponent(conf BuildKwokControllerComponentConfig) (comp
 			"--server-address="+conf.BindAddress+":"+format.String(conf.Port),
 			"--node-lease-duration-seconds="+format.String(conf.NodeLeaseDurationSeconds),
 		)
+		if conf.AdminTokenPath != "" {
+			args = append(args,
+				"--admin-token-file="+conf.AdminTokenPath,
+			)
+		}
 	}
 
 	var metricsHost string
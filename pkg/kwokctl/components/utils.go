@@ -76,12 +76,13 @@ const (
 )
 
 const (
-	kubeconfigPath   = "/etc/kubernetes/kubeconfig.yaml"
-	pkiCACertPath    = "/etc/kubernetes/pki/ca.crt"
-	pkiAdminCertPath = "/etc/kubernetes/pki/admin.crt"
-	pkiAdminKeyPath  = "/etc/kubernetes/pki/admin.key"
-	metricsPath      = "/metrics"
-	schemeHTTPS      = "https"
+	kubeconfigPath    = "/etc/kubernetes/kubeconfig.yaml"
+	pkiCACertPath     = "/etc/kubernetes/pki/ca.crt"
+	pkiAdminCertPath  = "/etc/kubernetes/pki/admin.crt"
+	pkiAdminKeyPath   = "/etc/kubernetes/pki/admin.key"
+	pkiAdminTokenPath = "/etc/kubernetes/pki/admin.token"
+	metricsPath       = "/metrics"
+	schemeHTTPS       = "https"
 )
 
 var (
@@ -79,6 +79,12 @@ func GeneratePki(pkiPath string, sans ...string) error {
 	if err != nil {
 		return fmt.Errorf("failed to write admin cert and key: %w", err)
 	}
+
+	// Generate admin token for the admin API of kwok-controller
+	err = WriteToken(pkiPath, "admin")
+	if err != nil {
+		return fmt.Errorf("failed to write admin token: %w", err)
+	}
 	return nil
 }
 
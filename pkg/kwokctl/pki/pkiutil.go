@@ -24,6 +24,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"math"
@@ -297,6 +298,20 @@ func writeKey(pkiPath, name string, key crypto.Signer) error {
 	return nil
 }
 
+// WriteToken generates a random token and stores it at the specified location
+func WriteToken(pkiPath string, name string) error {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return fmt.Errorf("unable to generate token: %w", err)
+	}
+	tokenPath := pathForToken(pkiPath, name)
+	if err := writeFile(tokenPath, []byte(hex.EncodeToString(buf))); err != nil {
+		return fmt.Errorf("unable to write token to file %s: %w", tokenPath, err)
+	}
+	return nil
+}
+
 // EncodeCertToPEM returns PEM-encoded certificate data
 func EncodeCertToPEM(cert *x509.Certificate) []byte {
 	block := pem.Block{
@@ -343,6 +358,10 @@ func pathForKey(pkiPath, name string) string {
 	return utilspath.Join(pkiPath, fmt.Sprintf("%s.key", name))
 }
 
+func pathForToken(pkiPath, name string) string {
+	return utilspath.Join(pkiPath, fmt.Sprintf("%s.token", name))
+}
+
 func pathForCert(pkiPath, name string) string {
 	return utilspath.Join(pkiPath, fmt.Sprintf("%s.crt", name))
 }
@@ -52,6 +52,13 @@ func ReplaceTimeToRelative(baseTime time.Time, data []byte) []byte {
 
 // RevertTimeFromRelative reverts the time from relative to absolute.
 func RevertTimeFromRelative(baseTime time.Time, data []byte) []byte {
+	return RevertTimeFromRelativeWith(data, func(offset time.Duration) time.Time {
+		return baseTime.Add(offset)
+	})
+}
+
+// RevertTimeFromRelativeWith reverts the time from relative to absolute by the given function.
+func RevertTimeFromRelativeWith(data []byte, timeFunc func(offset time.Duration) time.Time) []byte {
 	return regRevertTimeOffset.ReplaceAllFunc(data, func(s []byte) []byte {
 		// $(time-offset-nanosecond 0)
 		i, err := strconv.ParseInt(string(s[25:len(s)-1]), 0, 0)
@@ -59,7 +66,7 @@ func RevertTimeFromRelative(baseTime time.Time, data []byte) []byte {
 			return s
 		}
 
-		t := baseTime.Add(time.Duration(i)).UTC()
+		t := timeFunc(time.Duration(i)).UTC()
 		return []byte(t.Format(formatRFC3339Micro))
 	})
 }
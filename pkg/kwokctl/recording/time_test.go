@@ -101,3 +101,16 @@ func TestRevertTimeFromRelative(t *testing.T) {
 		})
 	}
 }
+
+func TestRevertTimeFromRelativeWith(t *testing.T) {
+	baseTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := "any xxx $(time-offset-nanosecond 10000000000) any xxx"
+	want := "any xxx 2021-01-01T00:00:01.000000Z any xxx"
+
+	got := string(RevertTimeFromRelativeWith([]byte(data), func(offset time.Duration) time.Time {
+		return baseTime.Add(offset / 10)
+	}))
+	if got != want {
+		t.Errorf("RevertTimeFromRelativeWith() = %v, want %v", got, want)
+	}
+}
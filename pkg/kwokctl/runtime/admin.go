@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kwok/pkg/consts"
+	"sigs.k8s.io/kwok/pkg/kwokctl/dryrun"
+	"sigs.k8s.io/kwok/pkg/utils/format"
+	utilsnet "sigs.k8s.io/kwok/pkg/utils/net"
+	utilspath "sigs.k8s.io/kwok/pkg/utils/path"
+)
+
+// KwokControllerAdmin sends a request to the admin API of the kwok-controller of the cluster,
+// it forwards a local port to the kwok-controller and authenticates with the admin token of the cluster.
+func KwokControllerAdmin(ctx context.Context, rt Runtime, method, path string) ([]byte, error) {
+	if rt.IsDryRun() {
+		dryrun.PrintMessagef("# %s %s to the admin API of %s", method, path, consts.ComponentKwokController)
+		return nil, nil
+	}
+
+	tokenPath := utilspath.Join(rt.GetWorkdirPath(PkiName), "admin.token")
+	token, err := os.ReadFile(tokenPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("admin token %s not found, the cluster needs to be recreated to enable the admin API: %w", tokenPath, err)
+		}
+		return nil, fmt.Errorf("failed to read admin token: %w", err)
+	}
+
+	port, err := utilsnet.GetUnusedPort(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	cancel, err := rt.PortForward(ctx, consts.ComponentKwokController, "http", port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to forward port of %s: %w", consts.ComponentKwokController, err)
+	}
+	defer cancel()
+
+	url := "http://" + utilsnet.LocalAddress + ":" + format.String(port) + path
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", path, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to request %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
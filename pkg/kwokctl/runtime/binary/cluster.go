@@ -57,6 +57,7 @@ type env struct {
 	workdir                 string
 	caCertPath              string
 	adminKeyPath            string
+	adminTokenPath          string
 	adminCertPath           string
 	scheme                  string
 	usedPorts               sets.Sets[uint32]
@@ -91,6 +92,7 @@ func (c *Cluster) env(ctx context.Context) (*env, error) {
 	pkiPath := c.GetWorkdirPath(runtime.PkiName)
 	caCertPath := utilspath.Join(pkiPath, "ca.crt")
 	adminKeyPath := utilspath.Join(pkiPath, "admin.key")
+	adminTokenPath := utilspath.Join(pkiPath, "admin.token")
 	adminCertPath := utilspath.Join(pkiPath, "admin.crt")
 	auditLogPath := ""
 	auditPolicyPath := ""
@@ -119,6 +121,7 @@ func (c *Cluster) env(ctx context.Context) (*env, error) {
 		workdir:                 workdir,
 		caCertPath:              caCertPath,
 		adminKeyPath:            adminKeyPath,
+		adminTokenPath:          adminTokenPath,
 		adminCertPath:           adminCertPath,
 		scheme:                  scheme,
 		usedPorts:               usedPorts,
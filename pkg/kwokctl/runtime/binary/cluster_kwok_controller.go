@@ -62,6 +62,7 @@ func (c *Cluster) addKwokController(ctx context.Context, env *env) (err error) {
 		CaCertPath:               env.caCertPath,
 		AdminCertPath:            env.adminCertPath,
 		AdminKeyPath:             env.adminKeyPath,
+		AdminTokenPath:           env.adminTokenPath,
 		NodeName:                 "localhost",
 		Verbosity:                env.verbosity,
 		NodeLeaseDurationSeconds: conf.NodeLeaseDurationSeconds,
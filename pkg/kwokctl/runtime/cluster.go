@@ -284,6 +284,16 @@ func (c *Cluster) Save(ctx context.Context) error {
 		objs = appendIntoInternalObjects(objs, stages...)
 	}
 
+	if !slices.Contains(conf.Options.EnableCRDs, v1alpha1.ProbeKind) {
+		probes := config.FilterWithTypeFromContext[*internalversion.Probe](ctx)
+		objs = appendIntoInternalObjects(objs, probes...)
+	}
+
+	if !slices.Contains(conf.Options.EnableCRDs, v1alpha1.ClusterProbeKind) {
+		clusterProbes := config.FilterWithTypeFromContext[*internalversion.ClusterProbe](ctx)
+		objs = appendIntoInternalObjects(objs, clusterProbes...)
+	}
+
 	return config.Save(ctx, c.GetWorkdirPath(ConfigName), objs)
 }
 
@@ -98,6 +98,8 @@ var crdDefines = map[string][]byte{
 	v1alpha1.ClusterLogsKind:          crd.ClusterLogs,
 	v1alpha1.ResourceUsageKind:        crd.ResourceUsage,
 	v1alpha1.ClusterResourceUsageKind: crd.ClusterResourceUsage,
+	v1alpha1.ProbeKind:                crd.Probe,
+	v1alpha1.ClusterProbeKind:         crd.ClusterProbe,
 	v1alpha1.MetricKind:               crd.Metric,
 }
 
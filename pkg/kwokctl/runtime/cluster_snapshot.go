@@ -21,11 +21,16 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
+
+	"k8s.io/utils/clock"
 
 	"sigs.k8s.io/kwok/pkg/kwokctl/dryrun"
+	"sigs.k8s.io/kwok/pkg/kwokctl/recording"
 	"sigs.k8s.io/kwok/pkg/kwokctl/snapshot"
 	"sigs.k8s.io/kwok/pkg/log"
 	"sigs.k8s.io/kwok/pkg/utils/client"
+	"sigs.k8s.io/kwok/pkg/utils/file"
 	"sigs.k8s.io/kwok/pkg/utils/yaml"
 )
 
@@ -128,7 +133,28 @@ func (c *Cluster) SnapshotRestoreWithYAML(ctx context.Context, path string, conf
 		return err
 	}
 
-	var r io.Reader = f
+	press, err := file.Decompress(path, f)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = press.Close()
+	}()
+
+	var r io.Reader = press
+
+	replayConfig := conf.Replay
+	startTime := replayConfig.StartTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	// The same timeline is used to revert the recorded times and to replay,
+	// so that the reverted times follow the pauses of the replay,
+	// it is started again when the replay starts after the resources are loaded.
+	replayConfig.Timeline = snapshot.NewTimeline(clock.RealClock{}, startTime, replayConfig.StartAt, replayConfig.Speed)
+	r = recording.NewReadHook(r, func(b []byte) []byte {
+		return recording.RevertTimeFromRelativeWith(b, replayConfig.Timeline.Time)
+	})
 
 	decoder := yaml.NewDecoder(r)
 	err = loader.Load(ctx, decoder)
@@ -136,5 +162,11 @@ func (c *Cluster) SnapshotRestoreWithYAML(ctx context.Context, path string, conf
 		return err
 	}
 
+	// The rest of the snapshot is the recorded resource patches if it was exported with --record
+	err = loader.Replay(ctx, decoder, replayConfig)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -103,6 +103,7 @@ type env struct {
 	workdir                       string
 	caCertPath                    string
 	adminKeyPath                  string
+	adminTokenPath                string
 	adminCertPath                 string
 	inClusterPkiPath              string
 	inClusterCaCertPath           string
@@ -140,6 +141,7 @@ func (c *Cluster) env(ctx context.Context) (*env, error) {
 	workdir := c.Workdir()
 	caCertPath := utilspath.Join(pkiPath, "ca.crt")
 	adminKeyPath := utilspath.Join(pkiPath, "admin.key")
+	adminTokenPath := utilspath.Join(pkiPath, "admin.token")
 	adminCertPath := utilspath.Join(pkiPath, "admin.crt")
 	inClusterPkiPath := "/etc/kubernetes/pki/"
 	inClusterCaCertPath := utilspath.Join(inClusterPkiPath, "ca.crt")
@@ -173,6 +175,7 @@ func (c *Cluster) env(ctx context.Context) (*env, error) {
 		workdir:                       workdir,
 		caCertPath:                    caCertPath,
 		adminKeyPath:                  adminKeyPath,
+		adminTokenPath:                adminTokenPath,
 		adminCertPath:                 adminCertPath,
 		inClusterPkiPath:              inClusterPkiPath,
 		inClusterCaCertPath:           inClusterCaCertPath,
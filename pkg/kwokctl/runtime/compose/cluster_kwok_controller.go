@@ -64,6 +64,7 @@ func (c *Cluster) addKwokController(ctx context.Context, env *env) (err error) {
 		CaCertPath:               env.caCertPath,
 		AdminCertPath:            env.adminCertPath,
 		AdminKeyPath:             env.adminKeyPath,
+		AdminTokenPath:           env.adminTokenPath,
 		NodeName:                 c.Name() + "-kwok-controller",
 		Verbosity:                env.verbosity,
 		NodeLeaseDurationSeconds: conf.NodeLeaseDurationSeconds,
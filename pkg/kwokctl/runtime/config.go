@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/kwokctl/snapshot"
 	"sigs.k8s.io/kwok/pkg/utils/client"
 )
 
@@ -161,6 +162,8 @@ type SnapshotSaveWithYAMLConfig struct {
 type SnapshotRestoreWithYAMLConfig struct {
 	// Filters specifies which resources to restore from the snapshot
 	Filters []string
+	// Replay specifies how to replay the recorded resource patches in the snapshot
+	Replay snapshot.ReplayConfig
 }
 
 // ComponentStatus represents the status of a cluster component
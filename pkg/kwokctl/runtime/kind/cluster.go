@@ -95,6 +95,7 @@ type env struct {
 	workdir                       string
 	caCertPath                    string
 	adminKeyPath                  string
+	adminTokenPath                string
 	adminCertPath                 string
 
 	kwokConfigPath string
@@ -132,6 +133,7 @@ func (c *Cluster) env(ctx context.Context) (*env, error) {
 	workdir := c.Workdir()
 	caCertPath := utilspath.Join(pkiPath, "ca.crt")
 	adminKeyPath := utilspath.Join(pkiPath, "admin.key")
+	adminTokenPath := utilspath.Join(pkiPath, "admin.token")
 	adminCertPath := utilspath.Join(pkiPath, "admin.crt")
 
 	usedPorts := runtime.GetUsedPorts(ctx)
@@ -147,6 +149,7 @@ func (c *Cluster) env(ctx context.Context) (*env, error) {
 		workdir:                       workdir,
 		caCertPath:                    caCertPath,
 		adminKeyPath:                  adminKeyPath,
+		adminTokenPath:                adminTokenPath,
 		adminCertPath:                 adminCertPath,
 		kwokConfigPath:                kwokConfigPath,
 		usedPorts:                     usedPorts,
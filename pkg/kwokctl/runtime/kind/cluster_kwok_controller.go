@@ -74,6 +74,7 @@ func (c *Cluster) addKwokController(ctx context.Context, env *env) (err error) {
 		CaCertPath:                        env.caCertPath,
 		AdminCertPath:                     env.adminCertPath,
 		AdminKeyPath:                      env.adminKeyPath,
+		AdminTokenPath:                    env.adminTokenPath,
 		NodeIP:                            "$(POD_IP)",
 		NodeName:                          "localhost",
 		ManageNodesWithAnnotationSelector: "kwok.x-k8s.io/node=fake",
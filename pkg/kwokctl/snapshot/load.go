@@ -35,6 +35,7 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
 
 	"sigs.k8s.io/kwok/pkg/kwokctl/recording"
 	"sigs.k8s.io/kwok/pkg/log"
@@ -75,6 +76,8 @@ type Loader struct {
 	dynamicClient dynamic.Interface
 
 	loadConfig LoadConfig
+
+	clock clock.Clock
 }
 
 // NewLoader creates a new snapshot Loader.
@@ -94,6 +97,7 @@ func NewLoader(loadConfig LoadConfig) (*Loader, error) {
 		restMapper:    restMapper,
 		dynamicClient: dynamicClient,
 		loadConfig:    loadConfig,
+		clock:         clock.RealClock{},
 	}
 
 	return l, nil
@@ -128,7 +132,7 @@ func (l *Loader) Load(ctx context.Context, decoder *yaml.Decoder) error {
 				"kind", obj.GetKind(),
 				"name", log.KObj(obj),
 			)
-			return nil
+			continue
 		}
 
 		l.load(ctx, obj)
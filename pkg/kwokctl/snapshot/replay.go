@@ -0,0 +1,384 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/kwok/pkg/apis/action/v1alpha1"
+	"sigs.k8s.io/kwok/pkg/kwokctl/recording"
+	"sigs.k8s.io/kwok/pkg/log"
+	"sigs.k8s.io/kwok/pkg/utils/yaml"
+)
+
+// ReplayConfig is the configuration of the replay.
+type ReplayConfig struct {
+	// StartTime is the time that the recording offsets are relative to.
+	// If it is zero, the time when the replay starts is used.
+	StartTime time.Time
+	// Speed is the multiplier of the recorded timing, 1 means real time.
+	// If it is zero, 1 is used.
+	Speed float64
+	// StartAt is the offset of the recording to start the replay at,
+	// the resource patches before it are applied immediately.
+	StartAt time.Duration
+	// StopAt is the offset of the recording to stop the replay at,
+	// zero means replaying until the end.
+	StopAt time.Duration
+	// Pause toggles the pause and resume of the replay on each receive.
+	Pause <-chan struct{}
+	// Timeline is the timeline of the replay shared with the reverting of the recorded times,
+	// so that both are consistent when the replay is paused and resumed.
+	// If it is nil, a timeline is created from the StartTime, StartAt and Speed,
+	// otherwise it is started at the StartTime.
+	Timeline *Timeline
+}
+
+// Replay replays the recorded resource patches to cluster.
+// Each resource patch is applied once its offset has been reached in the timeline of the replay.
+func (l *Loader) Replay(ctx context.Context, decoder *yaml.Decoder, conf ReplayConfig) error {
+	logger := log.FromContext(ctx)
+
+	// The timeline starts when the replay starts rather than when the snapshot starts to load,
+	// so that the resource patches are not played in a hurry to catch up the time spent on loading.
+	if conf.StartTime.IsZero() {
+		conf.StartTime = l.clock.Now()
+	}
+	tl := conf.Timeline
+	if tl == nil {
+		tl = NewTimeline(l.clock, conf.StartTime, conf.StartAt, conf.Speed)
+	} else {
+		tl.Start(conf.StartTime)
+	}
+
+	successCounter := 0
+	failedCounter := 0
+	for ctx.Err() == nil {
+		obj, err := decoder.DecodeUnstructured()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			logger.Warn("Failed to decode resource",
+				"err", err,
+			)
+			continue
+		}
+
+		if obj.GetKind() != recording.ResourcePatchType.Kind || obj.GetAPIVersion() != recording.ResourcePatchType.APIVersion {
+			logger.Warn("Skipped",
+				"resource", "not a resource patch",
+				"kind", obj.GetKind(),
+				"name", log.KObj(obj),
+			)
+			continue
+		}
+
+		resourcePatch, err := yaml.Convert[recording.ResourcePatch](obj)
+		if err != nil {
+			logger.Warn("Failed to convert resource patch",
+				"err", err,
+			)
+			continue
+		}
+
+		offset := resourcePatch.GetDuration()
+		if conf.StopAt > 0 && offset > conf.StopAt {
+			break
+		}
+
+		if !l.wait(ctx, tl, offset, conf.Pause) {
+			break
+		}
+
+		err = l.replay(ctx, &resourcePatch)
+		if err != nil {
+			failedCounter++
+			name, namespace := resourcePatch.GetTargetName()
+			logger.Warn("Failed to replay resource patch",
+				"err", err,
+				"method", resourcePatch.Method,
+				"resource", resourcePatch.GetTargetGroupVersionResource(),
+				"name", log.KRef(namespace, name),
+			)
+			continue
+		}
+		successCounter++
+	}
+
+	if successCounter+failedCounter == 0 {
+		return nil
+	}
+
+	logger.Info("Replay resources",
+		"counter", successCounter+failedCounter,
+		"successCounter", successCounter,
+		"failedCounter", failedCounter,
+		"offset", tl.Offset(),
+		"elapsed", l.clock.Since(conf.StartTime),
+	)
+	return nil
+}
+
+// wait waits until the offset is reached in the timeline, it returns false if the context is done.
+func (l *Loader) wait(ctx context.Context, tl *Timeline, offset time.Duration, pause <-chan struct{}) bool {
+	logger := log.FromContext(ctx)
+	for {
+		delay, ok := tl.Until(offset)
+		if ok && delay <= 0 {
+			return ctx.Err() == nil
+		}
+
+		// The timer is not started when paused, only the pause channel can wake it up.
+		var timer clock.Timer
+		var timerCh <-chan time.Time
+		if ok {
+			timer = l.clock.NewTimer(delay)
+			timerCh = timer.C()
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-timerCh:
+		case _, open := <-pause:
+			if !open {
+				pause = nil
+			} else if tl.Paused() {
+				tl.Resume()
+				logger.Info("Resumed",
+					"offset", tl.Offset(),
+				)
+			} else {
+				tl.Pause()
+				logger.Info("Paused",
+					"offset", tl.Offset(),
+				)
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+	}
+}
+
+// Timeline maps the offsets of the recording to the wall time of the replay.
+type Timeline struct {
+	clock clock.PassiveClock
+	speed float64
+
+	// anchorTime is the wall time when the anchorOffset is reached.
+	anchorTime   time.Time
+	anchorOffset time.Duration
+	paused       bool
+
+	startAt time.Duration
+}
+
+// NewTimeline creates a new timeline that reaches the startAt offset at the startTime,
+// and moves forward at the speed multiplier of the wall time.
+func NewTimeline(clock clock.PassiveClock, startTime time.Time, startAt time.Duration, speed float64) *Timeline {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &Timeline{
+		clock:        clock,
+		speed:        speed,
+		anchorTime:   startTime,
+		anchorOffset: startAt,
+		startAt:      startAt,
+	}
+}
+
+// Offset returns the current offset of the recording.
+func (t *Timeline) Offset() time.Duration {
+	if t.paused {
+		return t.anchorOffset
+	}
+	elapsed := t.clock.Since(t.anchorTime)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return t.anchorOffset + time.Duration(float64(elapsed)*t.speed)
+}
+
+// Until returns the wall time duration until the offset is reached,
+// it returns false if the timeline is paused before the offset.
+func (t *Timeline) Until(offset time.Duration) (time.Duration, bool) {
+	current := t.Offset()
+	if offset <= current {
+		return 0, true
+	}
+	if t.paused {
+		return 0, false
+	}
+	return time.Duration(float64(offset-current) / t.speed), true
+}
+
+// Time returns the wall time that the offset of the recording is mapped to,
+// the offset is mapped as if the timeline is resumed now if it is paused.
+func (t *Timeline) Time(offset time.Duration) time.Time {
+	anchorTime := t.anchorTime
+	if t.paused {
+		anchorTime = t.clock.Now()
+	}
+	return anchorTime.Add(time.Duration(float64(offset-t.anchorOffset) / t.speed))
+}
+
+// Start starts the timeline again that reaches the startAt offset at the startTime.
+func (t *Timeline) Start(startTime time.Time) {
+	t.anchorTime = startTime
+	t.anchorOffset = t.startAt
+	t.paused = false
+}
+
+// Paused returns true if the timeline is paused.
+func (t *Timeline) Paused() bool {
+	return t.paused
+}
+
+// Pause pauses the timeline at the current offset.
+func (t *Timeline) Pause() {
+	if t.paused {
+		return
+	}
+	t.anchorOffset = t.Offset()
+	t.anchorTime = t.clock.Now()
+	t.paused = true
+}
+
+// Resume resumes the timeline from the paused offset.
+func (t *Timeline) Resume() {
+	if !t.paused {
+		return
+	}
+	t.anchorTime = t.clock.Now()
+	t.paused = false
+}
+
+func (l *Loader) replay(ctx context.Context, resourcePatch *recording.ResourcePatch) error {
+	gvr := resourcePatch.GetTargetGroupVersionResource()
+	gvk, err := l.restMapper.KindFor(gvr)
+	if err != nil {
+		return fmt.Errorf("failed to get kind: %w", err)
+	}
+
+	if !l.filterGK(gvk.GroupKind()) {
+		return nil
+	}
+
+	switch resourcePatch.Method {
+	case v1alpha1.PatchMethodCreate:
+		return l.replayCreate(ctx, resourcePatch)
+	case v1alpha1.PatchMethodPatch:
+		return l.replayPatch(ctx, gvr, resourcePatch)
+	case v1alpha1.PatchMethodDelete:
+		return l.replayDelete(ctx, gvr, resourcePatch)
+	default:
+		return fmt.Errorf("unknown patch method %q", resourcePatch.Method)
+	}
+}
+
+func (l *Loader) resourceInterface(gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	nri := l.dynamicClient.Resource(gvr)
+	if namespace != "" {
+		return nri.Namespace(namespace)
+	}
+	return nri
+}
+
+func (l *Loader) replayCreate(ctx context.Context, resourcePatch *recording.ResourcePatch) error {
+	obj := &unstructured.Unstructured{}
+	err := obj.UnmarshalJSON(resourcePatch.Template)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal template: %w", err)
+	}
+
+	// The load will handle the ownerReference and the uid mapping
+	// so that the created resource is related to the existing resources.
+	l.load(ctx, obj)
+	return nil
+}
+
+func (l *Loader) replayPatch(ctx context.Context, gvr schema.GroupVersionResource, resourcePatch *recording.ResourcePatch) error {
+	name, namespace := resourcePatch.GetTargetName()
+	ri := l.resourceInterface(gvr, namespace)
+
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(resourcePatch.Template, &fields)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal template: %w", err)
+	}
+
+	err = l.patch(ctx, ri, name, resourcePatch.Template)
+	if err != nil {
+		return err
+	}
+
+	// The status of resources with the status subresource is ignored by the above patch,
+	// so it needs to be patched again via the status subresource.
+	if _, ok := fields["status"]; ok {
+		err = l.patch(ctx, ri, name, resourcePatch.Template, "status")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loader) patch(ctx context.Context, ri dynamic.ResourceInterface, name string, data []byte, subresources ...string) error {
+	_, err := ri.Patch(ctx, name, types.StrategicMergePatchType, data, metav1.PatchOptions{FieldValidation: "Ignore"}, subresources...)
+	if err != nil && apierrors.IsUnsupportedMediaType(err) {
+		// Custom resources do not support strategic merge patch.
+		_, err = ri.Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{FieldValidation: "Ignore"}, subresources...)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Loader) replayDelete(ctx context.Context, gvr schema.GroupVersionResource, resourcePatch *recording.ResourcePatch) error {
+	name, namespace := resourcePatch.GetTargetName()
+	ri := l.resourceInterface(gvr, namespace)
+
+	// The recorded delete is observed after the graceful deletion has finished,
+	// so there is no need to wait for the grace period again.
+	err := ri.Delete(ctx, name, metav1.DeleteOptions{
+		GracePeriodSeconds: new(int64(0)),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
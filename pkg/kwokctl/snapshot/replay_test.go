@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/kwok/pkg/apis/action/v1alpha1"
+	"sigs.k8s.io/kwok/pkg/kwokctl/recording"
+	"sigs.k8s.io/kwok/pkg/utils/yaml"
+)
+
+func TestTimeline(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakePassiveClock(startTime)
+
+	tl := NewTimeline(clock, startTime, time.Minute, 10)
+
+	if got := tl.Offset(); got != time.Minute {
+		t.Fatalf("Offset() = %v, want %v", got, time.Minute)
+	}
+
+	if got, ok := tl.Until(30 * time.Second); !ok || got != 0 {
+		t.Fatalf("Until() = %v, %v, want 0, true", got, ok)
+	}
+
+	if got, ok := tl.Until(2 * time.Minute); !ok || got != 6*time.Second {
+		t.Fatalf("Until() = %v, %v, want %v, true", got, ok, 6*time.Second)
+	}
+
+	if got, want := tl.Time(2*time.Minute), startTime.Add(6*time.Second); !got.Equal(want) {
+		t.Fatalf("Time() = %v, want %v", got, want)
+	}
+
+	clock.SetTime(startTime.Add(3 * time.Second))
+	if got := tl.Offset(); got != time.Minute+30*time.Second {
+		t.Fatalf("Offset() = %v, want %v", got, time.Minute+30*time.Second)
+	}
+
+	tl.Pause()
+	clock.SetTime(startTime.Add(time.Hour))
+	if got := tl.Offset(); got != time.Minute+30*time.Second {
+		t.Fatalf("Offset() after pause = %v, want %v", got, time.Minute+30*time.Second)
+	}
+	if _, ok := tl.Until(2 * time.Minute); ok {
+		t.Fatalf("Until() after pause should not be reachable")
+	}
+	if got, want := tl.Time(2*time.Minute), startTime.Add(time.Hour+3*time.Second); !got.Equal(want) {
+		t.Fatalf("Time() after pause = %v, want %v", got, want)
+	}
+
+	tl.Resume()
+	if got, ok := tl.Until(2 * time.Minute); !ok || got != 3*time.Second {
+		t.Fatalf("Until() after resume = %v, %v, want %v, true", got, ok, 3*time.Second)
+	}
+	if got, want := tl.Time(2*time.Minute), startTime.Add(time.Hour+3*time.Second); !got.Equal(want) {
+		t.Fatalf("Time() after resume = %v, want %v", got, want)
+	}
+}
+
+var (
+	configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	widgetsGVR    = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+)
+
+func newResourcePatch(gvr schema.GroupVersionResource, name string, offset time.Duration, method v1alpha1.PatchMethod, template string) recording.ResourcePatch {
+	r := recording.ResourcePatch{
+		TypeMeta: recording.ResourcePatchType,
+		Method:   method,
+		Template: json.RawMessage(template),
+	}
+	r.SetTargetGroupVersionResource(gvr)
+	r.SetTargetName(name, "default")
+	r.SetDuration(offset)
+	return r
+}
+
+func TestLoaderReplay(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		patches []recording.ResourcePatch
+		stopAt  time.Duration
+		want    []string
+	}{
+		{
+			name: "create",
+			patches: []recording.ResourcePatch{
+				newResourcePatch(configMapsGVR, "new", time.Second, v1alpha1.PatchMethodCreate,
+					`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"new","namespace":"default"}}`),
+			},
+			want: []string{
+				"create configmaps default/new",
+			},
+		},
+		{
+			name: "strategic merge patch",
+			patches: []recording.ResourcePatch{
+				newResourcePatch(configMapsGVR, "cm", time.Second, v1alpha1.PatchMethodPatch,
+					`{"data":{"key":"value"}}`),
+			},
+			want: []string{
+				"patch configmaps default/cm " + string(types.StrategicMergePatchType),
+			},
+		},
+		{
+			name: "merge patch fallback with status",
+			patches: []recording.ResourcePatch{
+				newResourcePatch(widgetsGVR, "widget", time.Second, v1alpha1.PatchMethodPatch,
+					`{"spec":{"size":1},"status":{"ready":true}}`),
+			},
+			want: []string{
+				"patch widgets default/widget " + string(types.StrategicMergePatchType),
+				"patch widgets default/widget " + string(types.MergePatchType),
+				"patch widgets/status default/widget " + string(types.StrategicMergePatchType),
+				"patch widgets/status default/widget " + string(types.MergePatchType),
+			},
+		},
+		{
+			name: "delete",
+			patches: []recording.ResourcePatch{
+				newResourcePatch(configMapsGVR, "cm", time.Second, v1alpha1.PatchMethodDelete, ""),
+			},
+			want: []string{
+				"delete configmaps default/cm",
+			},
+		},
+		{
+			name: "stop at",
+			patches: []recording.ResourcePatch{
+				newResourcePatch(configMapsGVR, "cm", time.Second, v1alpha1.PatchMethodPatch,
+					`{"data":{"key":"1"}}`),
+				newResourcePatch(configMapsGVR, "cm", 3*time.Second, v1alpha1.PatchMethodPatch,
+					`{"data":{"key":"3"}}`),
+			},
+			stopAt: 2 * time.Second,
+			want: []string{
+				"patch configmaps default/cm " + string(types.StrategicMergePatchType),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restMapper := meta.NewDefaultRESTMapper(nil)
+			restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+			restMapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+
+			cm := &unstructured.Unstructured{}
+			cm.SetAPIVersion("v1")
+			cm.SetKind("ConfigMap")
+			cm.SetName("cm")
+			cm.SetNamespace("default")
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				configMapsGVR: "ConfigMapList",
+				widgetsGVR:    "WidgetList",
+			}, cm)
+			dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				patch := action.(clienttesting.PatchAction)
+				if patch.GetResource() == widgetsGVR && patch.GetPatchType() == types.StrategicMergePatchType {
+					// Custom resources do not support strategic merge patch.
+					return true, nil, &apierrors.StatusError{ErrStatus: metav1.Status{
+						Status: metav1.StatusFailure,
+						Code:   http.StatusUnsupportedMediaType,
+						Reason: metav1.StatusReasonUnsupportedMediaType,
+					}}
+				}
+				return true, &unstructured.Unstructured{}, nil
+			})
+
+			l := &Loader{
+				exist:         map[uniqueKey]types.UID{},
+				pending:       map[uniqueKey][]*unstructured.Unstructured{},
+				restMapper:    restMapper,
+				dynamicClient: dynamicClient,
+				loadConfig:    LoadConfig{NoFilers: true},
+				clock:         clocktesting.NewFakeClock(startTime),
+			}
+
+			docs := make([]string, 0, len(tt.patches))
+			for _, patch := range tt.patches {
+				data, err := json.Marshal(patch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				docs = append(docs, string(data))
+			}
+			decoder := yaml.NewDecoder(strings.NewReader(strings.Join(docs, "\n---\n")))
+
+			// All the patches are due at the start, so the replay does not wait on the fake clock.
+			err := l.Replay(context.Background(), decoder, ReplayConfig{
+				StartTime: startTime,
+				StartAt:   time.Hour,
+				StopAt:    tt.stopAt,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, action := range dynamicClient.Actions() {
+				resource := action.GetResource().Resource
+				if sub := action.GetSubresource(); sub != "" {
+					resource += "/" + sub
+				}
+				switch action := action.(type) {
+				case clienttesting.CreateAction:
+					obj := action.GetObject().(*unstructured.Unstructured)
+					got = append(got, "create "+resource+" "+action.GetNamespace()+"/"+obj.GetName())
+				case clienttesting.PatchAction:
+					got = append(got, "patch "+resource+" "+action.GetNamespace()+"/"+action.GetName()+" "+string(action.GetPatchType()))
+				case clienttesting.DeleteAction:
+					got = append(got, "delete "+resource+" "+action.GetNamespace()+"/"+action.GetName())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected actions %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoaderReplayStartsTimeline(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := clocktesting.NewFakeClock(startTime)
+	l := &Loader{
+		clock: clock,
+	}
+
+	// The timeline is created before the resources are loaded, which takes a minute.
+	tl := NewTimeline(clock, startTime, 0, 1)
+	clock.SetTime(startTime.Add(time.Minute))
+
+	err := l.Replay(context.Background(), yaml.NewDecoder(strings.NewReader("")), ReplayConfig{
+		Timeline: tl,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tl.Offset(); got != 0 {
+		t.Errorf("expected the timeline to start when the replay starts, got offset %v", got)
+	}
+}
@@ -0,0 +1,387 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stage provides the offline simulation of stages.
+package stage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+	"sigs.k8s.io/kwok/pkg/utils/lifecycle"
+	utilsmaps "sigs.k8s.io/kwok/pkg/utils/maps"
+)
+
+// SimulateConfig is the configuration of the simulation.
+type SimulateConfig struct {
+	// Stages is the list of stages to simulate,
+	// the stages whose resourceRef does not match the resource are ignored.
+	Stages []*internalversion.Stage
+	// Now is the time used to calculate the delay of stages.
+	// If it is zero, the current time is used.
+	Now time.Time
+	// MaxDepth is the max number of stages played in a row.
+	MaxDepth int
+	// FuncMap overrides the functions used to render the templates of stages.
+	FuncMap gotpl.FuncMap
+}
+
+// Transition is a possible transition of the resource by playing a stage.
+type Transition struct {
+	// Stage is the name of the played stage.
+	Stage string `json:"stage"`
+	// Weight is the weight of the stage among the other matched stages.
+	Weight *int64 `json:"weight,omitempty"`
+	// FailureRate is the percentage of the times the stage fails.
+	FailureRate *int64 `json:"failureRate,omitempty"`
+	// Delay is the possible range of the delay before playing the stage.
+	Delay []metav1.Duration `json:"delay,omitempty"`
+	// Waits is the conditions of the wait steps of the stage,
+	// they are assumed to be satisfied eventually in the simulation.
+	Waits []string `json:"waits,omitempty"`
+	// Events is the events sent by the stage.
+	Events []StageEvent `json:"events,omitempty"`
+	// Applies is the resources applied by the stage.
+	Applies []json.RawMessage `json:"applies,omitempty"`
+	// Deleted is true if the resource is deleted by the stage.
+	Deleted bool `json:"deleted,omitempty"`
+	// Result is the resource after playing the stage.
+	Result *unstructured.Unstructured `json:"result,omitempty"`
+	// Loop is true if the result is the same as a previous state,
+	// so the simulation does not go further.
+	Loop bool `json:"loop,omitempty"`
+	// Truncated is true if the max depth is reached,
+	// so the simulation does not go further.
+	Truncated bool `json:"truncated,omitempty"`
+	// Error is the error occurred while playing the stage.
+	Error string `json:"error,omitempty"`
+	// Next is the possible transitions after this one.
+	Next []*Transition `json:"next,omitempty"`
+}
+
+// StageEvent is an event sent by the stage.
+type StageEvent struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+// DefaultFuncMap is the default functions used to render the templates of stages,
+// which return placeholder values instead of the values allocated by kwok.
+var DefaultFuncMap = gotpl.FuncMap{
+	"NodeIP": func() string {
+		return "10.0.0.1"
+	},
+	"NodeName": func() string {
+		return ""
+	},
+	"NodePort": func() int {
+		return 0
+	},
+	"PodIP": func() string {
+		return "10.0.0.2"
+	},
+	"NodeIPWith": func(nodeName string) string {
+		return "10.0.0.1"
+	},
+	"NodeIPsWith": func(nodeName string) []string {
+		return []string{"10.0.0.1"}
+	},
+	"PodIPWith": func(nodeName string, hostNetwork bool, uid, name, namespace string) (string, error) {
+		if hostNetwork {
+			return "10.0.0.1", nil
+		}
+		return "10.0.0.2", nil
+	},
+	"PodIPsWith": func(nodeName string, hostNetwork bool, uid, name, namespace string) ([]string, error) {
+		if hostNetwork {
+			return []string{"10.0.0.1"}, nil
+		}
+		return []string{"10.0.0.2"}, nil
+	},
+}
+
+// Simulate walks all possible transitions of the resource by playing the stages without a cluster.
+func Simulate(ctx context.Context, obj *unstructured.Unstructured, conf SimulateConfig) ([]*Transition, error) {
+	if conf.MaxDepth <= 0 {
+		return nil, fmt.Errorf("max depth must be greater than 0")
+	}
+	if conf.Now.IsZero() {
+		conf.Now = time.Now()
+	}
+
+	gvk := obj.GroupVersionKind()
+	stages := slices.DeleteFunc(slices.Clone(conf.Stages), func(stage *internalversion.Stage) bool {
+		return stage.Spec.ResourceRef.APIGroup != gvk.GroupVersion().String() ||
+			stage.Spec.ResourceRef.Kind != gvk.Kind
+	})
+
+	lc, err := lifecycle.NewLifecycle(stages)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &simulator{
+		lifecycle: lc,
+		renderer:  gotpl.NewRenderer(utilsmaps.Merge(DefaultFuncMap, conf.FuncMap)),
+		now:       conf.Now,
+		maxDepth:  conf.MaxDepth,
+	}
+
+	current, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return s.walk(ctx, obj, [][]byte{current})
+}
+
+type simulator struct {
+	lifecycle lifecycle.Lifecycle
+	renderer  gotpl.Renderer
+	now       time.Time
+	maxDepth  int
+}
+
+// walk returns the possible transitions of the resource,
+// the path is the states of the resource from the input to the current one.
+func (s *simulator) walk(ctx context.Context, obj *unstructured.Unstructured, path [][]byte) ([]*Transition, error) {
+	data, err := typedData(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &lifecycle.Event{
+		Labels:      obj.GetLabels(),
+		Annotations: obj.GetAnnotations(),
+		Data:        data,
+	}
+
+	stages, err := s.lifecycle.ListAllPossible(ctx, event)
+	if err != nil {
+		return nil, fmt.Errorf("stage match: %w", err)
+	}
+
+	transitions := make([]*Transition, 0, len(stages))
+	for _, stage := range stages {
+		t := s.play(ctx, stage, event, obj)
+		transitions = append(transitions, t)
+		if t.Error != "" || t.Deleted {
+			continue
+		}
+
+		current, err := t.Result.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.ContainsFunc(path, func(state []byte) bool {
+			return bytes.Equal(state, current)
+		}) {
+			t.Loop = true
+			continue
+		}
+
+		if len(path) >= s.maxDepth {
+			t.Truncated = true
+			continue
+		}
+
+		t.Next, err = s.walk(ctx, t.Result, append(slices.Clone(path), current))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return transitions, nil
+}
+
+// play plays the stage on the resource and returns the transition.
+func (s *simulator) play(ctx context.Context, stage *lifecycle.Stage, event *lifecycle.Event, obj *unstructured.Unstructured) *Transition {
+	t := &Transition{
+		Stage: stage.Name(),
+	}
+
+	weight, ok, err := stage.Weight(ctx, event)
+	if err != nil {
+		t.Error = fmt.Sprintf("failed to get weight: %v", err)
+		return t
+	}
+	if ok {
+		t.Weight = &weight
+	}
+
+	failureRate, ok, err := stage.FailureRate(ctx, event)
+	if err != nil {
+		t.Error = fmt.Sprintf("failed to get failure rate: %v", err)
+		return t
+	}
+	if ok && failureRate > 0 {
+		t.FailureRate = &failureRate
+	}
+
+	delays, ok, err := stage.DelayRangePossible(ctx, event, s.now)
+	if err != nil {
+		t.Error = fmt.Sprintf("failed to get delay: %v", err)
+		return t
+	}
+	if ok {
+		for _, delay := range delays {
+			t.Delay = append(t.Delay, metav1.Duration{Duration: delay})
+		}
+	}
+
+	current, err := obj.MarshalJSON()
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+
+	err = s.doSteps(stage, event, obj, t, &current)
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+
+	if t.Deleted {
+		return t
+	}
+
+	result := &unstructured.Unstructured{}
+	err = result.UnmarshalJSON(current)
+	if err != nil {
+		t.Error = fmt.Sprintf("failed to unmarshal result: %v", err)
+		return t
+	}
+	t.Result = result
+	return t
+}
+
+// doSteps does the steps of the stage on the current data of the resource,
+// the wait steps are resumed as if their conditions are satisfied.
+func (s *simulator) doSteps(stage *lifecycle.Stage, event *lifecycle.Event, obj *unstructured.Unstructured, t *Transition, current *[]byte) error {
+	remainIndex, err := s.doStepsFrom(stage.DoSteps, 0, event, obj, t, current)
+	for {
+		var waitErr *lifecycle.WaitError
+		if !errors.As(err, &waitErr) {
+			return err
+		}
+		t.Waits = append(t.Waits, waitErr.Wait.CEL.Expression)
+		remainIndex, err = s.doStepsFrom(stage.ResumeSteps, remainIndex, event, obj, t, current)
+	}
+}
+
+type doStepsFunc func(
+	stepIndex int,
+	metaFinalizers []string,
+	resource any,
+	renderer gotpl.Renderer,
+	sendEvent func(event *internalversion.StageEvent) error,
+	deleteResource func() error,
+	patchResource func(patch *lifecycle.Patch) error,
+	applyResource func(apply *lifecycle.Apply) error,
+) (int, error)
+
+func (s *simulator) doStepsFrom(doSteps doStepsFunc, stepIndex int, event *lifecycle.Event, obj *unstructured.Unstructured, t *Transition, current *[]byte) (int, error) {
+	return doSteps(
+		stepIndex, obj.GetFinalizers(), event.Data, s.renderer,
+		func(event *internalversion.StageEvent) error {
+			t.Events = append(t.Events, StageEvent{
+				Type:    event.Type,
+				Reason:  event.Reason,
+				Message: event.Message,
+			})
+			return nil
+		},
+		func() error {
+			t.Deleted = true
+			return nil
+		},
+		func(patch *lifecycle.Patch) error {
+			data, err := applyPatch(*current, patch, event.Data)
+			if err != nil {
+				return err
+			}
+			*current = data
+			return nil
+		},
+		func(apply *lifecycle.Apply) error {
+			t.Applies = append(t.Applies, apply.Data)
+			return nil
+		},
+	)
+}
+
+// typedData returns the typed object of the resource as the kwok controller plays stages on.
+func typedData(obj *unstructured.Unstructured) (any, error) {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group != "" || gvk.Version != "v1" {
+		return obj, nil
+	}
+
+	var data any
+	switch gvk.Kind {
+	case "Pod":
+		data = &corev1.Pod{}
+	case "Node":
+		data = &corev1.Node{}
+	default:
+		return obj, nil
+	}
+
+	raw, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(raw, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", gvk.Kind, err)
+	}
+	return data, nil
+}
+
+// applyPatch applies the patch to the original data of the resource.
+func applyPatch(original []byte, patch *lifecycle.Patch, dataStruct any) ([]byte, error) {
+	switch patch.Type {
+	case types.JSONPatchType:
+		p, err := jsonpatch.DecodePatch(patch.Data)
+		if err != nil {
+			return nil, err
+		}
+		return p.Apply(original)
+	case types.MergePatchType:
+		return jsonpatch.MergePatch(original, patch.Data)
+	case types.StrategicMergePatchType:
+		if _, ok := dataStruct.(*unstructured.Unstructured); ok {
+			// There is no schema of the custom resources, fallback to the merge patch.
+			return jsonpatch.MergePatch(original, patch.Data)
+		}
+		return strategicpatch.StrategicMergePatch(original, patch.Data, dataStruct)
+	}
+	return nil, fmt.Errorf("unknown patch type %s", patch.Type)
+}
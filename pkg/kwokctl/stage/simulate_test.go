@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	podfast "sigs.k8s.io/kwok/kustomize/stage/pod/fast"
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/config"
+	"sigs.k8s.io/kwok/pkg/utils/slices"
+)
+
+func TestSimulate(t *testing.T) {
+	stages, err := slices.MapWithError([]string{
+		podfast.DefaultPodReady,
+		podfast.DefaultPodComplete,
+		podfast.DefaultPodDelete,
+	}, config.UnmarshalWithType[*internalversion.Stage, string])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]any{
+				"name":      "pod",
+				"namespace": "default",
+			},
+			"spec": map[string]any{
+				"nodeName": "node",
+				"containers": []any{
+					map[string]any{
+						"name":  "container",
+						"image": "image",
+					},
+				},
+			},
+			"status": map[string]any{
+				"phase": "Pending",
+			},
+		},
+	}
+
+	transitions, err := Simulate(context.Background(), pod, SimulateConfig{
+		Stages:   stages,
+		Now:      time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		MaxDepth: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transitions) != 1 || transitions[0].Stage != "pod-ready" {
+		t.Fatalf("expected only pod-ready to be matched, got %+v", transitions)
+	}
+
+	ready := transitions[0]
+	if ready.Error != "" {
+		t.Fatalf("unexpected error: %s", ready.Error)
+	}
+	phase, _, _ := unstructured.NestedString(ready.Result.Object, "status", "phase")
+	if phase != "Running" {
+		t.Errorf("expected pod to be running after pod-ready, got %q", phase)
+	}
+	podIP, _, _ := unstructured.NestedString(ready.Result.Object, "status", "podIP")
+	if podIP != "10.0.0.2" {
+		t.Errorf("expected pod ip from the default func map, got %q", podIP)
+	}
+
+	_, err = Simulate(context.Background(), pod, SimulateConfig{
+		Stages: stages,
+	})
+	if err == nil {
+		t.Errorf("expected error for zero max depth")
+	}
+
+	truncated, err := Simulate(context.Background(), pod, SimulateConfig{
+		Stages:   stages,
+		MaxDepth: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(truncated) != 1 || len(truncated[0].Next) != 0 {
+		t.Errorf("expected simulation to stop at max depth, got %+v", truncated)
+	}
+}
+
+func TestSimulateWait(t *testing.T) {
+	stage, err := config.UnmarshalWithType[*internalversion.Stage](`
+kind: Stage
+apiVersion: kwok.x-k8s.io/v1alpha1
+metadata:
+  name: pod-ready
+spec:
+  resourceRef:
+    apiGroup: v1
+    kind: Pod
+  selector:
+    matchExpressions:
+    - key: '.status.phase'
+      operator: 'In'
+      values:
+      - 'Pending'
+  steps:
+  - wait:
+      cel:
+        expression: 'self.metadata.name == "other"'
+    patch:
+      subresource: status
+      root: status
+      template: |
+        phase: Running
+  - wait:
+      cel:
+        expression: 'self.metadata.name == "another"'
+    event:
+      type: Normal
+      reason: Ready
+      message: Pod is ready
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]any{
+				"name":      "pod",
+				"namespace": "default",
+			},
+			"status": map[string]any{
+				"phase": "Pending",
+			},
+		},
+	}
+
+	transitions, err := Simulate(context.Background(), pod, SimulateConfig{
+		Stages:   []*internalversion.Stage{stage},
+		MaxDepth: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transitions) != 1 {
+		t.Fatalf("expected one transition, got %+v", transitions)
+	}
+
+	ready := transitions[0]
+	if ready.Error != "" {
+		t.Fatalf("unexpected error: %s", ready.Error)
+	}
+	if len(ready.Waits) != 2 {
+		t.Errorf("expected both waits to be reported, got %v", ready.Waits)
+	}
+	phase, _, _ := unstructured.NestedString(ready.Result.Object, "status", "phase")
+	if phase != "Running" {
+		t.Errorf("expected the patch in the step of the wait to be done, got phase %q", phase)
+	}
+	if len(ready.Events) != 1 || ready.Events[0].Reason != "Ready" {
+		t.Errorf("expected the event in the step of the wait to be sent, got %+v", ready.Events)
+	}
+}
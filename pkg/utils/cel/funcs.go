@@ -17,10 +17,11 @@ limitations under the License.
 package cel
 
 import (
-	"math/rand"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	utilsrand "sigs.k8s.io/kwok/pkg/utils/rand"
 )
 
 func timeNow() time.Time {
@@ -40,6 +41,5 @@ func sinceSecond[T sinceResource](t T) float64 {
 }
 
 func mathRand() float64 {
-	//nolint: gosec
-	return rand.Float64()
+	return utilsrand.Float64()
 }
@@ -19,6 +19,7 @@ package gotpl
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -79,6 +80,23 @@ var (
 		"NodeConditions": func() any {
 			return nodeConditionsData
 		},
+		"NodeConditionsWith": func(nodeName string) any {
+			return nodeConditionsData
+		},
+		"NodeImagesWith": func(nodeName string) any {
+			return []any{}
+		},
+		"NodeVolumesInUseWith": func(nodeName string) any {
+			return []any{}
+		},
+		"NodeVolumesAttachedWith": func(nodeName string) any {
+			return []any{}
+		},
+		"NodeAllocatableWith": func(nodeName string) any {
+			return nil
+		},
+
+		"CrashLoopBackOff": crashLoopBackOff,
 	}
 
 	// https://kubernetes.io/docs/concepts/architecture/nodes/#condition
@@ -116,3 +134,51 @@ var (
 	}
 	nodeConditionsData, _ = expression.ToJSONStandard(nodeConditions)
 )
+
+// NodeConditions returns the default conditions of nodes.
+func NodeConditions() []corev1.NodeCondition {
+	return slices.Clone(nodeConditions)
+}
+
+const (
+	crashLoopBackOffInitial = 10 * time.Second
+	crashLoopBackOffMax     = 300 * time.Second
+)
+
+// CrashLoopBackOff returns the back-off before restarting the container that has restarted the given times,
+// it starts at 10s and doubles after each restart up to 5m, the same as kubelet.
+func CrashLoopBackOff(restartCount int64) time.Duration {
+	backoff := crashLoopBackOffInitial
+	for i := int64(0); i < restartCount && backoff < crashLoopBackOffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, crashLoopBackOffMax)
+}
+
+func crashLoopBackOff(restartCount any) (string, error) {
+	n, err := toInt64(restartCount)
+	if err != nil {
+		return "", fmt.Errorf("invalid restart count: %w", err)
+	}
+	return CrashLoopBackOff(n).String(), nil
+}
+
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	}
+	return 0, fmt.Errorf("unsupported type %T", v)
+}
@@ -76,6 +76,13 @@ func TestRenderToJSON(t *testing.T) {
 			templText: `{"foo":{{ list Foo .k | join "-" }}}`,
 			expected:  `{"foo":"bar-v1"}`,
 		},
+		{
+			name:      "with crash loop back-off",
+			funcMap:   template.FuncMap{},
+			original:  map[string]any{"first": 0, "third": 2.0, "many": 10},
+			templText: `{"first":{{ CrashLoopBackOff .first | Quote }},"third":{{ CrashLoopBackOff .third | Quote }},"many":{{ CrashLoopBackOff .many | Quote }}}`,
+			expected:  `{"first":"10s","many":"5m0s","third":"40s"}`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -139,6 +140,30 @@ func (i *Informer[T, L]) WatchWithCache(ctx context.Context, opt Option, events
 	return g, nil
 }
 
+// WatchWithSyncedCache is like WatchWithCache, but it waits for the cache to be synced before returning.
+// The informer is stopped if the cache is not synced within the timeout.
+func (i *Informer[T, L]) WatchWithSyncedCache(ctx context.Context, opt Option, events chan<- Event[T], timeout time.Duration) (Getter[T], error) {
+	store, controller := newCacheInformer[T](ctx, i.listWatch(ctx), opt, events)
+
+	stopCh := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		close(stopCh)
+	})
+	go controller.Run(stopCh)
+
+	syncCtx, syncCancel := context.WithTimeout(ctx, timeout)
+	defer syncCancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), controller.HasSynced) {
+		if stop() {
+			close(stopCh)
+		}
+		return nil, fmt.Errorf("failed to wait for cache sync: %w", syncCtx.Err())
+	}
+
+	g := &getter[T]{store: store}
+	return g, nil
+}
+
 func newCacheInformer[T runtime.Object](ctx context.Context, listWatch cache.ListerWatcherWithContext, opt Option, events chan<- Event[T]) (cache.Store, cache.Controller) {
 	var t T
 	eventHandler := cache.ResourceEventHandlerFuncs{}
@@ -20,11 +20,11 @@ import (
 	"context"
 	"fmt"
 	"maps"
-	"math/rand"
 	"reflect"
 	"slices"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -32,13 +32,19 @@ import (
 	"sigs.k8s.io/kwok/pkg/utils/cel"
 	"sigs.k8s.io/kwok/pkg/utils/expression"
 	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+	utilsrand "sigs.k8s.io/kwok/pkg/utils/rand"
 )
 
 // NewLifecycle returns a new Lifecycle.
 func NewLifecycle(stages []*internalversion.Stage) (Lifecycle, error) {
+	return NewLifecycleWithLookup(stages, nil)
+}
+
+// NewLifecycleWithLookup returns a new Lifecycle whose stages are able to look up the related objects.
+func NewLifecycleWithLookup(stages []*internalversion.Stage, lookup *Lookup) (Lifecycle, error) {
 	lcs := Lifecycle{}
 	for _, stage := range stages {
-		lc := NewStage(stage)
+		lc := NewStageWithLookup(stage, lookup)
 		if lc == nil {
 			continue
 		}
@@ -64,8 +70,44 @@ func (s Lifecycle) match(ctx context.Context, event *Event) ([]*Stage, error) {
 	return out, nil
 }
 
-// ListAllPossible returns all possible stages.
+// StageByName returns the stage with the name, or nil if not found.
+func (s Lifecycle) StageByName(name string) *Stage {
+	for _, stage := range s {
+		if stage.Name() == name {
+			return stage
+		}
+	}
+	return nil
+}
+
+// ListAllPossible returns all possible stages, including the failure stages of them.
 func (s Lifecycle) ListAllPossible(ctx context.Context, event *Event) ([]*Stage, error) {
+	stages, err := s.listAllPossible(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stage := range stages {
+		rate, ok, err := stage.FailureRate(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || rate <= 0 {
+			continue
+		}
+		failureStage, err := s.failureStage(event, stage)
+		if err != nil {
+			return nil, err
+		}
+		if failureStage == nil || slices.Contains(stages, failureStage) {
+			continue
+		}
+		stages = append(stages, failureStage)
+	}
+	return stages, nil
+}
+
+func (s Lifecycle) listAllPossible(ctx context.Context, event *Event) ([]*Stage, error) {
 	stages, err := s.match(ctx, event)
 	if err != nil {
 		return nil, err
@@ -132,9 +174,10 @@ func (s Lifecycle) Match(ctx context.Context, event *Event) (*Stage, error) {
 		return nil, nil
 	}
 	if len(stages) == 1 {
-		return stages[0], nil
+		return s.failover(ctx, event, stages[0])
 	}
 
+	source := randFor(event, stages[0].config.Spec.ResourceRef.Kind, "match")
 	var weights = make([]int64, 0, len(stages))
 	var totalWeights int64
 	var countError int
@@ -153,14 +196,12 @@ func (s Lifecycle) Match(ctx context.Context, event *Event) (*Stage, error) {
 	}
 
 	if countError == len(stages) {
-		//nolint:gosec
-		return stages[rand.Intn(len(stages))], nil
+		return s.failover(ctx, event, stages[source.Intn(len(stages))])
 	}
 
 	if totalWeights == 0 {
 		if countError == 0 {
-			//nolint:gosec
-			return stages[rand.Intn(len(stages))], nil
+			return s.failover(ctx, event, stages[source.Intn(len(stages))])
 		}
 
 		stagesWithWeights := make([]*Stage, 0, len(stages))
@@ -171,27 +212,59 @@ func (s Lifecycle) Match(ctx context.Context, event *Event) (*Stage, error) {
 			stagesWithWeights = append(stagesWithWeights, stage)
 		}
 
-		//nolint:gosec
-		off := rand.Intn(len(stagesWithWeights))
-		return stagesWithWeights[off], nil
+		off := source.Intn(len(stagesWithWeights))
+		return s.failover(ctx, event, stagesWithWeights[off])
 	}
 
-	//nolint:gosec
-	off := rand.Int63n(totalWeights)
+	off := source.Int63n(totalWeights)
 	for i, stage := range stages {
 		if weights[i] <= 0 {
 			continue
 		}
 		off -= weights[i]
 		if off < 0 {
-			return stage, nil
+			return s.failover(ctx, event, stage)
 		}
 	}
-	return stages[len(stages)-1], nil
+	return s.failover(ctx, event, stages[len(stages)-1])
+}
+
+// failover returns the failure stage instead of the stage if the stage fails by its failure rate,
+// or nil if the stage fails without a failure stage.
+func (s Lifecycle) failover(ctx context.Context, event *Event, stage *Stage) (*Stage, error) {
+	failed, err := stage.failed(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if !failed {
+		return stage, nil
+	}
+	return s.failureStage(event, stage)
+}
+
+func (s Lifecycle) failureStage(event *Event, stage *Stage) (*Stage, error) {
+	name := stage.config.Spec.FailureStage
+	if name == "" {
+		return nil, nil
+	}
+	failureStage := s.StageByName(name)
+	if failureStage == nil {
+		return nil, fmt.Errorf("failure stage %q of stage %q not found", name, stage.Name())
+	}
+	err := failureStage.init(event)
+	if err != nil {
+		return nil, err
+	}
+	return failureStage, nil
 }
 
 // NewStage returns a new Stage.
 func NewStage(s *internalversion.Stage) *Stage {
+	return NewStageWithLookup(s, nil)
+}
+
+// NewStageWithLookup returns a new Stage which is able to look up the related objects.
+func NewStageWithLookup(s *internalversion.Stage, lookup *Lookup) *Stage {
 	if s.Spec.Selector == nil {
 		return nil
 	}
@@ -199,19 +272,35 @@ func NewStage(s *internalversion.Stage) *Stage {
 	stage := &Stage{
 		name:   s.Name,
 		config: s,
+		lookup: lookup,
 	}
 
+	lookup.watchReferenced(s)
 	return stage
 }
 
+// Compile compiles the expressions of the stage for the type of the data,
+// it returns the error if any of the expressions is invalid.
+func (s *Stage) Compile(data any) error {
+	return s.init(&Event{Data: data})
+}
+
 func (s *Stage) init(event *Event) error {
-	if s.env != nil {
-		return nil
+	if s.env == nil && s.initErr == nil {
+		s.initErr = s.compile(event)
 	}
+	return s.initErr
+}
+
+func (s *Stage) compile(event *Event) error {
 	types := slices.Clone(cel.DefaultTypes)
 	conversions := slices.Clone(cel.DefaultConversions)
 	funcs := maps.Clone(cel.DefaultFuncs)
 	methods := maps.Clone(cel.FuncsToMethods(cel.DefaultFuncs))
+	if s.lookup != nil {
+		types = append(types, lookupTypes...)
+		maps.Copy(methods, s.lookup.methods())
+	}
 	env, err := cel.NewEnvironment(cel.EnvironmentConfig{
 		Types:       types,
 		Conversions: conversions,
@@ -255,6 +344,19 @@ func (s *Stage) init(event *Event) error {
 	}
 
 	s.nextSteps = config.Spec.Steps
+	for i, step := range s.nextSteps {
+		if step.Wait == nil {
+			continue
+		}
+		program, err := env.Compile(step.Wait.CEL.Expression)
+		if err != nil {
+			return err
+		}
+		if s.waitConditions == nil {
+			s.waitConditions = map[int]cel.Program{}
+		}
+		s.waitConditions[i] = program
+	}
 	if delay := config.Spec.Delay; delay != nil {
 		var durationFrom *internalversion.ExpressionFrom
 		if delay.DurationFrom != nil {
@@ -299,6 +401,14 @@ func (s *Stage) init(event *Event) error {
 
 	s.weight = weightGetter
 
+	if config.Spec.FailureRate != 0 || config.Spec.FailureRateFrom != nil {
+		failureRateGetter, err := newInt64From(new(int64(config.Spec.FailureRate)), env, config.Spec.FailureRateFrom)
+		if err != nil {
+			return err
+		}
+		s.failureRate = failureRateGetter
+	}
+
 	s.immediateNextStage = config.Spec.ImmediateNextStage
 
 	return nil
@@ -312,17 +422,22 @@ type Stage struct {
 	matchExpressions []*expression.Requirement
 	matchConditions  []cel.Program
 
-	weight    int64Getter
-	nextSteps []internalversion.StageStep
+	weight         int64Getter
+	failureRate    int64Getter
+	nextSteps      []internalversion.StageStep
+	waitConditions map[int]cel.Program
 
 	duration       durationGetter
 	jitterDuration durationGetter
 
 	immediateNextStage bool
 
+	lookup *Lookup
+
 	config *internalversion.Stage
 
-	env *cel.Environment
+	env     *cel.Environment
+	initErr error
 }
 
 // Event represents a lifecycle event that can be matched against stage conditions
@@ -450,8 +565,7 @@ func (s *Stage) Delay(ctx context.Context, event *Event, now time.Time) (time.Du
 		return jitterDuration, true, nil
 	}
 
-	//nolint:gosec
-	duration += time.Duration(rand.Int63n(int64(jitterDuration - duration)))
+	duration += time.Duration(s.randFor(event, "delay").Int63n(int64(jitterDuration - duration)))
 
 	return duration, true, nil
 }
@@ -490,6 +604,7 @@ func (s *Stage) DelayRangePossible(ctx context.Context, event *Event, now time.T
 }
 
 // DoSteps executes the steps of the stage starting from the given stepIndex.
+// A WaitError is returned along with the index of the wait step if its condition is not satisfied.
 func (s *Stage) DoSteps(
 	stepIndex int,
 	metaFinalizers []string,
@@ -500,7 +615,45 @@ func (s *Stage) DoSteps(
 	patchResource func(patch *Patch) error,
 	applyResource func(apply *Apply) error,
 ) (int, error) {
-	remainStepIndex, err := doStageSteps(s.nextSteps[stepIndex:], metaFinalizers, resource, renderer, sendEvent, deleteResource, patchResource, applyResource)
+	waitFor := func(i int, wait *internalversion.StageWait) error {
+		return s.wait(stepIndex+i, resource, wait)
+	}
+	return s.doSteps(stepIndex, metaFinalizers, resource, renderer, sendEvent, deleteResource, patchResource, applyResource, waitFor)
+}
+
+// ResumeSteps executes the steps of the stage starting from the wait step at the given stepIndex,
+// as if the condition of that wait step is satisfied, the conditions of the later wait steps are still checked.
+func (s *Stage) ResumeSteps(
+	stepIndex int,
+	metaFinalizers []string,
+	resource any,
+	renderer gotpl.Renderer,
+	sendEvent func(event *internalversion.StageEvent) error,
+	deleteResource func() error,
+	patchResource func(patch *Patch) error,
+	applyResource func(apply *Apply) error,
+) (int, error) {
+	waitFor := func(i int, wait *internalversion.StageWait) error {
+		if i == 0 {
+			return nil
+		}
+		return s.wait(stepIndex+i, resource, wait)
+	}
+	return s.doSteps(stepIndex, metaFinalizers, resource, renderer, sendEvent, deleteResource, patchResource, applyResource, waitFor)
+}
+
+func (s *Stage) doSteps(
+	stepIndex int,
+	metaFinalizers []string,
+	resource any,
+	renderer gotpl.Renderer,
+	sendEvent func(event *internalversion.StageEvent) error,
+	deleteResource func() error,
+	patchResource func(patch *Patch) error,
+	applyResource func(apply *Apply) error,
+	waitFor func(i int, wait *internalversion.StageWait) error,
+) (int, error) {
+	remainStepIndex, err := doStageSteps(s.nextSteps[stepIndex:], metaFinalizers, resource, renderer, sendEvent, deleteResource, patchResource, applyResource, waitFor)
 	if remainStepIndex >= 0 {
 		remainStepIndex += stepIndex
 	}
@@ -517,6 +670,46 @@ func (s *Stage) ImmediateNextStage() bool {
 	return s.immediateNextStage
 }
 
+// FailureRate returns the percentage of the times the stage fails.
+func (s *Stage) FailureRate(ctx context.Context, event *Event) (int64, bool, error) {
+	if s.failureRate == nil {
+		return 0, false, nil
+	}
+	return s.failureRate.Get(ctx, event)
+}
+
+// failed returns whether the stage fails by its failure rate.
+func (s *Stage) failed(ctx context.Context, event *Event) (bool, error) {
+	rate, ok, err := s.FailureRate(ctx, event)
+	if err != nil {
+		return false, err
+	}
+	if !ok || rate <= 0 {
+		return false, nil
+	}
+	if rate >= 100 {
+		return true, nil
+	}
+	return s.randFor(event, "failure").Int63n(100) < rate, nil
+}
+
+// randFor returns the random source of the resource of the event for the purpose in the stage.
+func (s *Stage) randFor(event *Event, purpose string) utilsrand.Source {
+	return randFor(event, s.config.Spec.ResourceRef.Kind, s.name+"/"+purpose)
+}
+
+// randFor returns the random source of the resource of the event for the purpose,
+// which is derived from the kind, namespace and name of the resource and the number of the draws for it when the seed is set,
+// so the random choices of a resource are reproducible regardless of the order the resources are played,
+// and vary each time the resource draws.
+func randFor(event *Event, kind string, purpose string) utilsrand.Source {
+	key := kind
+	if obj, ok := event.Data.(metav1.Object); ok {
+		key += "/" + obj.GetNamespace() + "/" + obj.GetName()
+	}
+	return utilsrand.ForKey(key + "/" + purpose)
+}
+
 // Weight returns the weight of the stage.
 func (s *Stage) Weight(ctx context.Context, event *Event) (int64, bool, error) {
 	return s.weight.Get(ctx, event)
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	utilsrand "sigs.k8s.io/kwok/pkg/utils/rand"
+)
+
+func TestLifecycleMatchFailure(t *testing.T) {
+	newLifecycle := func(failureRate int, failureStage string) Lifecycle {
+		lc, err := NewLifecycle([]*internalversion.Stage{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pod-ready",
+				},
+				Spec: internalversion.StageSpec{
+					Selector: &internalversion.StageSelector{
+						MatchLabels: map[string]string{
+							"app": "test",
+						},
+					},
+					FailureRate:  failureRate,
+					FailureStage: failureStage,
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pod-failed",
+				},
+				Spec: internalversion.StageSpec{
+					Selector: &internalversion.StageSelector{
+						MatchLabels: map[string]string{
+							"app": "failed",
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lc
+	}
+
+	match := func(lc Lifecycle) string {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"app": "test",
+				},
+			},
+		}
+		stage, err := lc.Match(context.Background(), &Event{
+			Labels: pod.Labels,
+			Data:   pod,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stage == nil {
+			return ""
+		}
+		return stage.Name()
+	}
+
+	if got := match(newLifecycle(0, "pod-failed")); got != "pod-ready" {
+		t.Errorf("expected pod-ready without failure, got %q", got)
+	}
+	if got := match(newLifecycle(100, "pod-failed")); got != "pod-failed" {
+		t.Errorf("expected pod-failed on failure, got %q", got)
+	}
+	if got := match(newLifecycle(100, "")); got != "" {
+		t.Errorf("expected no stage on failure without failure stage, got %q", got)
+	}
+
+	lc := newLifecycle(50, "pod-failed")
+	sequence := func() []string {
+		utilsrand.Seed(1)
+		var names []string
+		for range 20 {
+			names = append(names, match(lc))
+		}
+		return names
+	}
+	want := sequence()
+	got := sequence()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected the same failure sequence with the same seed, got %v and %v", want, got)
+		}
+	}
+	if !slices.Contains(want, "pod-ready") || !slices.Contains(want, "pod-failed") {
+		t.Errorf("expected the plays of the same pod to fail sometimes, got %v", want)
+	}
+
+	// Each play of the same pod fails by the failure rate, rather than the pod always or never failing.
+	lc = newLifecycle(30, "pod-failed")
+	utilsrand.Seed(1)
+	failures := 0
+	const plays = 1000
+	for range plays {
+		if match(lc) == "pod-failed" {
+			failures++
+		}
+	}
+	if rate := float64(failures) / plays; rate < 0.25 || rate > 0.35 {
+		t.Errorf("expected the failure rate of the same pod to be about 30%%, got %.1f%%", rate*100)
+	}
+
+	stages, err := lc.ListAllPossible(context.Background(), &Event{
+		Labels: map[string]string{
+			"app": "test",
+		},
+		Data: &corev1.Pod{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stages) != 2 || stages[0].Name() != "pod-ready" || stages[1].Name() != "pod-failed" {
+		t.Errorf("expected the failure stage to be possible, got %d stages", len(stages))
+	}
+}
+
+func TestStageDelaySeed(t *testing.T) {
+	stage := NewStage(&internalversion.Stage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-ready",
+		},
+		Spec: internalversion.StageSpec{
+			Selector: &internalversion.StageSelector{},
+			Delay: &internalversion.StageDelay{
+				DurationMilliseconds:       new(int64(1000)),
+				JitterDurationMilliseconds: new(int64(5000)),
+			},
+		},
+	})
+	err := stage.Compile(&corev1.Pod{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	sequence := func() []time.Duration {
+		utilsrand.Seed(1)
+		var delays []time.Duration
+		for range 10 {
+			delay, ok, err := stage.Delay(context.Background(), &Event{Data: &corev1.Pod{}}, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatal("expected delay")
+			}
+			if delay < time.Second || delay >= 5*time.Second {
+				t.Fatalf("expected delay in [1s, 5s), got %v", delay)
+			}
+			delays = append(delays, delay)
+		}
+		return delays
+	}
+	want := sequence()
+	got := sequence()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected the same delays with the same seed, got %v and %v", want, got)
+		}
+	}
+	if slices.Equal(want, slices.Repeat(want[:1], len(want))) {
+		t.Errorf("expected the delays of the same pod to vary, got %v", want)
+	}
+}
+
+func TestLifecycleMatchWeightSeed(t *testing.T) {
+	newStage := func(name string) *internalversion.Stage {
+		return &internalversion.Stage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: internalversion.StageSpec{
+				Selector: &internalversion.StageSelector{},
+				Weight:   1,
+			},
+		}
+	}
+	lc, err := NewLifecycle([]*internalversion.Stage{newStage("a"), newStage("b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod0",
+			Namespace: "default",
+		},
+	}
+	sequence := func() []string {
+		utilsrand.Seed(1)
+		var names []string
+		for range 20 {
+			stage, err := lc.Match(context.Background(), &Event{Data: pod})
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, stage.Name())
+		}
+		return names
+	}
+	want := sequence()
+	got := sequence()
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected the same stages with the same seed, got %v and %v", want, got)
+	}
+	if !slices.Contains(want, "a") || !slices.Contains(want, "b") {
+		t.Errorf("expected the stages matched by the same pod to vary, got %v", want)
+	}
+}
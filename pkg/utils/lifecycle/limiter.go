@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+// Limiters limits the rate and the concurrency of playing the stages,
+// the states are kept by the name of the stage so that they are carried across rebuilding the stages.
+type Limiters struct {
+	mut      sync.Mutex
+	limiters map[string]*limiter
+	version  string
+}
+
+// NewLimiters returns a new Limiters.
+func NewLimiters() *Limiters {
+	return &Limiters{
+		limiters: map[string]*limiter{},
+	}
+}
+
+// Acquire acquires to play the stage at the time within its rate limit,
+// it returns false and the delay to retry if the stage is limited.
+// If the stage is limited by the max in-flight, the delay is zero and the wake is called once instead
+// when a release makes room, it returns false if the job is no longer waiting so that the next one is woken.
+// Release must be called after the stage is played if it returns true.
+func (l *Limiters) Acquire(stage *Stage, now time.Time, wake func() bool) (time.Duration, bool) {
+	conf := stage.config.Spec.RateLimit
+	lim := l.get(stage.Name(), conf != nil)
+	if lim == nil {
+		return 0, true
+	}
+	return lim.acquire(conf, now, wake)
+}
+
+// Release releases what is acquired by Acquire.
+func (l *Limiters) Release(stage *Stage) {
+	lim := l.get(stage.Name(), false)
+	if lim == nil {
+		return
+	}
+	lim.release()
+}
+
+// Prune removes the limiters of the stages removed from the lifecycle,
+// it does nothing unless the version of the lifecycle is changed.
+func (l *Limiters) Prune(version string, lc Lifecycle) {
+	l.mut.Lock()
+	if version == l.version {
+		l.mut.Unlock()
+		return
+	}
+	l.version = version
+	removed := []*limiter{}
+	for name, lim := range l.limiters {
+		if lc.StageByName(name) == nil {
+			delete(l.limiters, name)
+			removed = append(removed, lim)
+		}
+	}
+	l.mut.Unlock()
+
+	// The jobs waiting for the removed stages are not limited by them anymore.
+	for _, lim := range removed {
+		lim.wakeAll()
+	}
+}
+
+func (l *Limiters) get(name string, create bool) *limiter {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	lim, ok := l.limiters[name]
+	if !ok && create {
+		lim = &limiter{}
+		l.limiters[name] = lim
+	}
+	return lim
+}
+
+// limiter limits the rate and the concurrency of playing a stage.
+type limiter struct {
+	mut      sync.Mutex
+	conf     internalversion.StageRateLimit
+	rate     *rate.Limiter
+	inFlight int32
+	// waiters are woken in order when the stage is released under the max in-flight.
+	waiters []func() bool
+}
+
+// update updates the limiter to the rate limit of the stage,
+// the tokens and the in-flight are kept if the stage is rebuilt with a changed rate limit.
+// It returns true if the rate limit is changed.
+func (l *limiter) update(conf *internalversion.StageRateLimit, now time.Time) bool {
+	if conf == nil {
+		conf = &internalversion.StageRateLimit{}
+	}
+	if l.conf == *conf {
+		return false
+	}
+	l.conf = *conf
+
+	if conf.QPS <= 0 {
+		l.rate = nil
+		return true
+	}
+	burst := conf.Burst
+	if burst <= 0 {
+		burst = conf.QPS
+	}
+	if l.rate == nil {
+		l.rate = rate.NewLimiter(rate.Limit(conf.QPS), int(burst))
+		return true
+	}
+	l.rate.SetLimitAt(now, rate.Limit(conf.QPS))
+	l.rate.SetBurstAt(now, int(burst))
+	return true
+}
+
+func (l *limiter) acquire(conf *internalversion.StageRateLimit, now time.Time, wake func() bool) (time.Duration, bool) {
+	l.mut.Lock()
+	var woken []func() bool
+	if l.update(conf, now) {
+		// The max in-flight may be raised, the waiters try again.
+		woken, l.waiters = l.waiters, nil
+	}
+	delay, ok := l.acquireLocked(now, wake)
+	l.mut.Unlock()
+
+	for _, wake := range woken {
+		wake()
+	}
+	return delay, ok
+}
+
+func (l *limiter) acquireLocked(now time.Time, wake func() bool) (time.Duration, bool) {
+	if l.conf.MaxInFlight > 0 && l.inFlight >= l.conf.MaxInFlight {
+		l.waiters = append(l.waiters, wake)
+		return 0, false
+	}
+
+	if l.rate != nil {
+		r := l.rate.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); delay > 0 {
+			r.CancelAt(now)
+			return delay, false
+		}
+	}
+	l.inFlight++
+	return 0, true
+}
+
+func (l *limiter) release() {
+	l.mut.Lock()
+	if l.inFlight > 0 {
+		l.inFlight--
+	}
+	l.mut.Unlock()
+
+	l.wakeOne()
+}
+
+// wakeOne wakes the first waiter that is still waiting.
+func (l *limiter) wakeOne() {
+	for {
+		l.mut.Lock()
+		if len(l.waiters) == 0 {
+			l.mut.Unlock()
+			return
+		}
+		wake := l.waiters[0]
+		l.waiters[0] = nil
+		l.waiters = l.waiters[1:]
+		l.mut.Unlock()
+
+		if wake() {
+			return
+		}
+	}
+}
+
+// wakeAll wakes all the waiters.
+func (l *limiter) wakeAll() {
+	l.mut.Lock()
+	woken := l.waiters
+	l.waiters = nil
+	l.mut.Unlock()
+
+	for _, wake := range woken {
+		wake()
+	}
+}
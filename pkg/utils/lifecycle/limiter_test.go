@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+func newRateLimitedStage(name string, rateLimit *internalversion.StageRateLimit) *Stage {
+	return NewStage(&internalversion.Stage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: internalversion.StageSpec{
+			Selector:  &internalversion.StageSelector{},
+			RateLimit: rateLimit,
+		},
+	})
+}
+
+func nop() bool {
+	return true
+}
+
+func TestLimiters(t *testing.T) {
+	now := time.Now()
+	l := NewLimiters()
+
+	if _, ok := l.Acquire(newRateLimitedStage("unlimited", nil), now, nop); !ok {
+		t.Fatalf("expected to acquire without rate limit")
+	}
+
+	stage := newRateLimitedStage("qps", &internalversion.StageRateLimit{QPS: 1, Burst: 2})
+	for i := range 2 {
+		if _, ok := l.Acquire(stage, now, nop); !ok {
+			t.Fatalf("expected to acquire within burst at %d", i)
+		}
+		l.Release(stage)
+	}
+
+	// The tokens are kept when the stage is rebuilt.
+	stage = newRateLimitedStage("qps", &internalversion.StageRateLimit{QPS: 1, Burst: 2})
+	delay, ok := l.Acquire(stage, now, nop)
+	if ok {
+		t.Fatalf("expected to be limited after burst")
+	}
+	if delay <= 0 || delay > time.Second {
+		t.Errorf("expected delay within one second, got %v", delay)
+	}
+	if _, ok := l.Acquire(stage, now.Add(delay), nop); !ok {
+		t.Errorf("expected to acquire after the delay")
+	}
+	l.Release(stage)
+
+	stage = newRateLimitedStage("in-flight", &internalversion.StageRateLimit{MaxInFlight: 1})
+	if _, ok := l.Acquire(stage, now, nop); !ok {
+		t.Fatalf("expected to acquire the first one")
+	}
+
+	// The in-flight is kept when the stage is rebuilt.
+	rebuilt := newRateLimitedStage("in-flight", &internalversion.StageRateLimit{MaxInFlight: 1})
+	if _, ok := l.Acquire(rebuilt, now, nop); ok {
+		t.Fatalf("expected to be limited by max in-flight")
+	}
+	l.Release(stage)
+	if _, ok := l.Acquire(rebuilt, now, nop); !ok {
+		t.Errorf("expected to acquire after release")
+	}
+
+	// The in-flight is still counted when the rate limit is changed.
+	changed := newRateLimitedStage("in-flight", &internalversion.StageRateLimit{MaxInFlight: 2})
+	if _, ok := l.Acquire(changed, now, nop); !ok {
+		t.Fatalf("expected to acquire with the raised max in-flight")
+	}
+	if _, ok := l.Acquire(changed, now, nop); ok {
+		t.Errorf("expected to be limited by the raised max in-flight")
+	}
+}
+
+func TestLimitersPrune(t *testing.T) {
+	now := time.Now()
+	l := NewLimiters()
+
+	kept := newRateLimitedStage("kept", &internalversion.StageRateLimit{QPS: 1, Burst: 1})
+	removed := newRateLimitedStage("removed", &internalversion.StageRateLimit{QPS: 1, Burst: 1})
+	for _, stage := range []*Stage{kept, removed} {
+		if _, ok := l.Acquire(stage, now, nop); !ok {
+			t.Fatalf("expected to acquire %s", stage.Name())
+		}
+		l.Release(stage)
+	}
+
+	l.Prune("", Lifecycle{kept})
+	if len(l.limiters) != 2 {
+		t.Fatalf("expected the limiters to be kept with the same version, got %d", len(l.limiters))
+	}
+
+	l.Prune("1", Lifecycle{kept})
+	if _, ok := l.limiters["removed"]; ok {
+		t.Errorf("expected the limiter of the removed stage to be pruned")
+	}
+	if _, ok := l.Acquire(kept, now, nop); ok {
+		t.Errorf("expected the limiter of the kept stage to keep its tokens")
+	}
+}
+
+func TestLimitersWaitInFlight(t *testing.T) {
+	now := time.Now()
+	l := NewLimiters()
+	stage := newRateLimitedStage("in-flight", &internalversion.StageRateLimit{MaxInFlight: 1})
+
+	if _, ok := l.Acquire(stage, now, nop); !ok {
+		t.Fatalf("expected to acquire the first one")
+	}
+
+	// The blocked jobs wait to be woken instead of retrying.
+	woken := make([]int, 3)
+	stillWaiting := []bool{false, true, true}
+	for i := range woken {
+		delay, ok := l.Acquire(stage, now, func() bool {
+			woken[i]++
+			return stillWaiting[i]
+		})
+		if ok {
+			t.Fatalf("expected to be limited by max in-flight at %d", i)
+		}
+		if delay != 0 {
+			t.Errorf("expected no delay to retry at %d, got %v", i, delay)
+		}
+	}
+	if !slices.Equal(woken, []int{0, 0, 0}) {
+		t.Fatalf("expected no waiter to be woken before release, got %v", woken)
+	}
+
+	// The waiter no longer waiting is skipped, and only one waiter is woken by a release.
+	l.Release(stage)
+	if !slices.Equal(woken, []int{1, 1, 0}) {
+		t.Fatalf("expected the first waiter still waiting to be woken, got %v", woken)
+	}
+	if _, ok := l.Acquire(stage, now, nop); !ok {
+		t.Fatalf("expected to acquire after release")
+	}
+	l.Release(stage)
+	if !slices.Equal(woken, []int{1, 1, 1}) {
+		t.Fatalf("expected the next waiter to be woken, got %v", woken)
+	}
+	l.Release(stage)
+	if !slices.Equal(woken, []int{1, 1, 1}) {
+		t.Errorf("expected each waiter to be woken once, got %v", woken)
+	}
+
+	// The waiters are woken when the stage is removed.
+	if _, ok := l.Acquire(stage, now, nop); !ok {
+		t.Fatalf("expected to acquire")
+	}
+	removed := false
+	if _, ok := l.Acquire(stage, now, func() bool {
+		removed = true
+		return true
+	}); ok {
+		t.Fatalf("expected to be limited by max in-flight")
+	}
+	l.Prune("1", Lifecycle{})
+	if !removed {
+		t.Errorf("expected the waiter to be woken when the stage is removed")
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+const (
+	lookupNodeName                  = "Node"
+	lookupObjectName                = "Object"
+	lookupOwnerName                 = "Owner"
+	lookupPersistentVolumeClaimName = "PersistentVolumeClaim"
+)
+
+// Lookup looks up the objects related to the resource,
+// they are exposed as methods of the resource in the CEL expressions of stages.
+type Lookup struct {
+	// Node returns the node by name.
+	Node func(name string) (*corev1.Node, bool)
+	// Object returns the object by apiVersion, kind, namespace and name.
+	Object func(apiVersion, kind, namespace, name string) (*unstructured.Unstructured, bool)
+	// Watch starts caching the objects of the kind referenced by a stage,
+	// so they are able to be found by the lookups of the stage.
+	Watch func(gvk schema.GroupVersionKind)
+}
+
+// lookupOwnerKinds is the kinds of the built-in workloads watched for the stages looking up the owner.
+var lookupOwnerKinds = []schema.GroupVersionKind{
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+}
+
+// lookupObjectKindRegexp matches the lookups of objects with a literal apiVersion and kind.
+var lookupObjectKindRegexp = regexp.MustCompile(`\.` + lookupObjectName + `\(\s*["']([^"']+)["']\s*,\s*["']([^"']+)["']`)
+
+// watchReferenced starts watching the kinds referenced by the lookups in the CEL expressions of the stage,
+// the kinds of self.Object() with a non-literal apiVersion or kind are watched on their first lookup.
+func (l *Lookup) watchReferenced(s *internalversion.Stage) {
+	if l == nil || l.Watch == nil || l.Object == nil {
+		return
+	}
+
+	for _, expr := range stageCELExpressions(s) {
+		if strings.Contains(expr, "."+lookupOwnerName+"(") {
+			for _, gvk := range lookupOwnerKinds {
+				l.Watch(gvk)
+			}
+		}
+		if strings.Contains(expr, "."+lookupPersistentVolumeClaimName+"(") {
+			l.Watch(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+		}
+		for _, match := range lookupObjectKindRegexp.FindAllStringSubmatch(expr, -1) {
+			l.Watch(schema.FromAPIVersionAndKind(match[1], match[2]))
+		}
+	}
+}
+
+// stageCELExpressions returns all the CEL expressions of the stage.
+func stageCELExpressions(s *internalversion.Stage) []string {
+	var exprs []string
+	addFrom := func(from *internalversion.ExpressionFrom) {
+		if from != nil && from.CEL != nil {
+			exprs = append(exprs, from.CEL.Expression)
+		}
+	}
+
+	spec := s.Spec
+	if spec.Selector != nil {
+		for _, express := range spec.Selector.MatchExpressions {
+			if express.CEL != nil {
+				exprs = append(exprs, express.CEL.Expression)
+			}
+		}
+	}
+	for _, step := range spec.Steps {
+		if step.Wait != nil {
+			exprs = append(exprs, step.Wait.CEL.Expression)
+		}
+	}
+	if spec.Delay != nil {
+		addFrom(spec.Delay.DurationFrom)
+		addFrom(spec.Delay.JitterDurationFrom)
+	}
+	addFrom(spec.WeightFrom)
+	addFrom(spec.FailureRateFrom)
+	return exprs
+}
+
+// lookupTypes is the types of the objects returned by the lookup methods.
+var lookupTypes = []any{
+	corev1.Taint{},
+	corev1.NodeCondition{},
+	corev1.PersistentVolumeClaim{},
+	corev1.PersistentVolumeClaimSpec{},
+	corev1.PersistentVolumeClaimStatus{},
+}
+
+// methods returns the lookup methods,
+// an empty object is returned if the related object is not found.
+func (l *Lookup) methods() map[string][]any {
+	methods := map[string][]any{}
+	if l == nil {
+		return methods
+	}
+
+	if l.Node != nil {
+		methods[lookupNodeName] = []any{
+			func(pod *corev1.Pod) corev1.Node {
+				if pod == nil || pod.Spec.NodeName == "" {
+					return corev1.Node{}
+				}
+				node, ok := l.Node(pod.Spec.NodeName)
+				if !ok {
+					return corev1.Node{}
+				}
+				return *node
+			},
+		}
+	}
+
+	if l.Object != nil {
+		methods[lookupOwnerName] = []any{
+			func(pod *corev1.Pod) map[string]any {
+				if pod == nil {
+					return map[string]any{}
+				}
+				owner := metav1.GetControllerOf(pod)
+				if owner == nil {
+					return map[string]any{}
+				}
+				obj, ok := l.Object(owner.APIVersion, owner.Kind, pod.Namespace, owner.Name)
+				if !ok {
+					return map[string]any{}
+				}
+				return obj.Object
+			},
+		}
+
+		methods[lookupObjectName] = []any{
+			func(pod *corev1.Pod, apiVersion, kind, name string) map[string]any {
+				if pod == nil {
+					return map[string]any{}
+				}
+				obj, ok := l.Object(apiVersion, kind, pod.Namespace, name)
+				if !ok {
+					return map[string]any{}
+				}
+				return obj.Object
+			},
+		}
+
+		methods[lookupPersistentVolumeClaimName] = []any{
+			func(pod *corev1.Pod, claimName string) corev1.PersistentVolumeClaim {
+				if pod == nil {
+					return corev1.PersistentVolumeClaim{}
+				}
+				obj, ok := l.Object("v1", "PersistentVolumeClaim", pod.Namespace, claimName)
+				if !ok {
+					return corev1.PersistentVolumeClaim{}
+				}
+				var pvc corev1.PersistentVolumeClaim
+				err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pvc)
+				if err != nil {
+					return corev1.PersistentVolumeClaim{}
+				}
+				return pvc
+			},
+		}
+	}
+	return methods
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+)
+
+func TestLookup(t *testing.T) {
+	lookup := &Lookup{
+		Node: func(name string) (*corev1.Node, bool) {
+			if name != "node-0" {
+				return nil, false
+			}
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Spec: corev1.NodeSpec{
+					Taints: []corev1.Taint{
+						{
+							Key:    "gpu",
+							Effect: corev1.TaintEffectNoSchedule,
+						},
+					},
+				},
+			}, true
+		},
+		Object: func(apiVersion, kind, namespace, name string) (*unstructured.Unstructured, bool) {
+			switch {
+			case kind == "PersistentVolumeClaim" && name == "data":
+				return &unstructured.Unstructured{
+					Object: map[string]any{
+						"apiVersion": apiVersion,
+						"kind":       kind,
+						"metadata": map[string]any{
+							"name":      name,
+							"namespace": namespace,
+						},
+						"status": map[string]any{
+							"phase": "Bound",
+						},
+					},
+				}, true
+			case kind == "ConfigMap" && name == "config":
+				return &unstructured.Unstructured{
+					Object: map[string]any{
+						"apiVersion": apiVersion,
+						"kind":       kind,
+						"metadata": map[string]any{
+							"name":      name,
+							"namespace": namespace,
+						},
+					},
+				}, true
+			case kind == "Job" && name == "job-0":
+				return &unstructured.Unstructured{
+					Object: map[string]any{
+						"apiVersion": apiVersion,
+						"kind":       kind,
+						"metadata": map[string]any{
+							"name":      name,
+							"namespace": namespace,
+						},
+						"spec": map[string]any{
+							"backoffLimit": int64(3),
+						},
+					},
+				}, true
+			}
+			return nil, false
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-0",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "batch/v1",
+					Kind:       "Job",
+					Name:       "job-0",
+					Controller: new(true),
+				},
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-0",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		data       any
+		want       bool
+	}{
+		{
+			name:       "node taint",
+			expression: `self.Node().spec.taints.exists(t, t.key == "gpu")`,
+			data:       pod,
+			want:       true,
+		},
+		{
+			name:       "node not found",
+			expression: `self.Node().metadata.name == ""`,
+			data: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					NodeName: "node-1",
+				},
+			},
+			want: true,
+		},
+		{
+			name:       "pvc phase",
+			expression: `self.PersistentVolumeClaim("data").status.phase == "Bound"`,
+			data:       pod,
+			want:       true,
+		},
+		{
+			name:       "pvc not found",
+			expression: `self.PersistentVolumeClaim("other").status.phase == "Bound"`,
+			data:       pod,
+			want:       false,
+		},
+		{
+			name:       "object",
+			expression: `has(self.Object("v1", "ConfigMap", "config").metadata)`,
+			data:       pod,
+			want:       true,
+		},
+		{
+			name:       "object not found",
+			expression: `has(self.Object("v1", "ConfigMap", "other").metadata)`,
+			data:       pod,
+			want:       false,
+		},
+		{
+			name:       "owner",
+			expression: `self.Owner().spec.backoffLimit == 3`,
+			data:       pod,
+			want:       true,
+		},
+		{
+			name:       "owner not found",
+			expression: `!has(self.Owner().spec)`,
+			data:       &corev1.Pod{},
+			want:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := NewStageWithLookup(&internalversion.Stage{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "stage",
+				},
+				Spec: internalversion.StageSpec{
+					Selector: &internalversion.StageSelector{
+						MatchExpressions: []internalversion.MatchExpression{
+							{
+								CEL: &internalversion.ExpressionCEL{
+									Expression: tt.expression,
+								},
+							},
+						},
+					},
+				},
+			}, lookup)
+
+			lc := Lifecycle{stage}
+			got, err := lc.ListAllPossible(context.Background(), &Event{
+				Data: tt.data,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(got) != 0) != tt.want {
+				t.Errorf("ListAllPossible() matched = %v, want %v", len(got) != 0, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupWatchReferenced(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       []schema.GroupVersionKind
+	}{
+		{
+			name:       "node",
+			expression: `self.Node().metadata.name != ""`,
+		},
+		{
+			name:       "pvc",
+			expression: `self.PersistentVolumeClaim("data").status.phase == "Bound"`,
+			want: []schema.GroupVersionKind{
+				{Version: "v1", Kind: "PersistentVolumeClaim"},
+			},
+		},
+		{
+			name:       "owner",
+			expression: `has(self.Owner().kind)`,
+			want:       lookupOwnerKinds,
+		},
+		{
+			name:       "object",
+			expression: `has(self.Object("v1", "ConfigMap", "config").metadata) && has(self.Object('apps/v1', 'Deployment', 'app').metadata)`,
+			want: []schema.GroupVersionKind{
+				{Version: "v1", Kind: "ConfigMap"},
+				{Group: "apps", Version: "v1", Kind: "Deployment"},
+			},
+		},
+		{
+			name:       "object with non-literal kind",
+			expression: `has(self.Object("v1", self.metadata.labels["kind"], "config").metadata)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []schema.GroupVersionKind
+			lookup := &Lookup{
+				Object: func(apiVersion, kind, namespace, name string) (*unstructured.Unstructured, bool) {
+					return nil, false
+				},
+				Watch: func(gvk schema.GroupVersionKind) {
+					got = append(got, gvk)
+				},
+			}
+			_ = NewStageWithLookup(&internalversion.Stage{
+				Spec: internalversion.StageSpec{
+					Selector: &internalversion.StageSelector{
+						MatchExpressions: []internalversion.MatchExpression{
+							{
+								CEL: &internalversion.ExpressionCEL{
+									Expression: tt.expression,
+								},
+							},
+						},
+					},
+				},
+			}, lookup)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Watch() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
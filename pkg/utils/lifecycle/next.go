@@ -38,9 +38,17 @@ func doStageSteps(
 	deleteResource func() error,
 	patchResource func(patch *Patch) error,
 	applyResource func(apply *Apply) error,
+	waitFor func(i int, wait *internalversion.StageWait) error,
 ) (int, error) {
 	var deleted bool
 	for i, step := range nextSteps {
+		if step.Wait != nil && !deleted {
+			err := waitFor(i, step.Wait)
+			if err != nil {
+				return i, err
+			}
+		}
+
 		if step.Event != nil {
 			err := sendEvent(step.Event)
 			if err != nil {
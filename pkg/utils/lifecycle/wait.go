@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/utils/cel"
+)
+
+// WaitError is returned by DoSteps if the condition of a wait step is not satisfied,
+// the steps need to be done again from the returned index later.
+type WaitError struct {
+	Wait *internalversion.StageWait
+}
+
+func (e *WaitError) Error() string {
+	return fmt.Sprintf("waiting for condition %q", e.Wait.CEL.Expression)
+}
+
+// Timeout returns the max time to wait, zero means waiting forever.
+func (e *WaitError) Timeout() time.Duration {
+	if e.Wait.TimeoutMilliseconds == nil {
+		return 0
+	}
+	return time.Duration(*e.Wait.TimeoutMilliseconds) * time.Millisecond
+}
+
+// wait returns a WaitError if the condition of the wait step is not satisfied by the resource.
+func (s *Stage) wait(index int, resource any, wait *internalversion.StageWait) error {
+	program, ok := s.waitConditions[index]
+	if !ok {
+		return fmt.Errorf("wait condition for step %d is not compiled", index)
+	}
+
+	event := &Event{
+		Data: resource,
+	}
+	val, _, err := program.Eval(event.toCELStandard())
+	if err != nil {
+		return fmt.Errorf("failed to evaluate wait condition for step %d: %w", index, err)
+	}
+	satisfied, err := cel.AsBool(val)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate wait condition for step %d: %w", index, err)
+	}
+	if !satisfied {
+		return &WaitError{
+			Wait: wait,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kwok/pkg/apis/internalversion"
+	"sigs.k8s.io/kwok/pkg/utils/gotpl"
+)
+
+func TestStageDoStepsWait(t *testing.T) {
+	stage := NewStage(&internalversion.Stage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-wait",
+		},
+		Spec: internalversion.StageSpec{
+			Selector: &internalversion.StageSelector{},
+			Steps: []internalversion.StageStep{
+				{
+					Event: &internalversion.StageEvent{
+						Reason: "Waiting",
+					},
+				},
+				{
+					Wait: &internalversion.StageWait{
+						CEL: internalversion.ExpressionCEL{
+							Expression: `"ready" in self.metadata.labels`,
+						},
+					},
+				},
+				{
+					Event: &internalversion.StageEvent{
+						Reason: "Ready",
+					},
+				},
+			},
+		},
+	})
+
+	pod := &corev1.Pod{}
+	err := stage.Compile(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doSteps := func(stepIndex int, pod *corev1.Pod) ([]string, int, error) {
+		var reasons []string
+		remainIndex, err := stage.DoSteps(stepIndex, nil, pod, gotpl.NewRenderer(nil),
+			func(event *internalversion.StageEvent) error {
+				reasons = append(reasons, event.Reason)
+				return nil
+			},
+			nil, nil, nil,
+		)
+		return reasons, remainIndex, err
+	}
+
+	reasons, remainIndex, err := doSteps(0, pod)
+	var waitErr *WaitError
+	if !errors.As(err, &waitErr) {
+		t.Fatalf("expected wait error, got %v", err)
+	}
+	if remainIndex != 1 {
+		t.Errorf("expected remain index 1, got %d", remainIndex)
+	}
+	if len(reasons) != 1 || reasons[0] != "Waiting" {
+		t.Errorf("unexpected events %v", reasons)
+	}
+
+	reasons, remainIndex, err = doSteps(remainIndex, pod)
+	if !errors.As(err, &waitErr) {
+		t.Fatalf("expected wait error, got %v", err)
+	}
+	if len(reasons) != 0 {
+		t.Errorf("unexpected events %v", reasons)
+	}
+
+	pod.Labels = map[string]string{"ready": ""}
+	reasons, remainIndex, err = doSteps(remainIndex, pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remainIndex != -1 {
+		t.Errorf("expected remain index -1, got %d", remainIndex)
+	}
+	if len(reasons) != 1 || reasons[0] != "Ready" {
+		t.Errorf("unexpected events %v", reasons)
+	}
+}
@@ -79,6 +79,18 @@ func (m *SyncMap[K, V]) Swap(key K, value V) (V, bool) {
 	return v.(V), loaded
 }
 
+// CompareAndSwap swaps the old and new values for key if the value stored in the map is equal to old,
+// the value must be of a comparable type.
+func (m *SyncMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	return m.m.CompareAndSwap(key, old, new)
+}
+
+// CompareAndDelete deletes the entry for key if its value is equal to old,
+// the value must be of a comparable type.
+func (m *SyncMap[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	return m.m.CompareAndDelete(key, old)
+}
+
 // Size returns the number of items in the map.
 func (m *SyncMap[K, V]) Size() int {
 	size := 0
@@ -164,6 +164,46 @@ func TestSyncMap_LoadAndDelete(t *testing.T) {
 	}
 }
 
+func TestSyncMap_CompareAndDelete(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		old         string
+		wantDeleted bool
+	}{
+		{
+			name:        "test equal value",
+			key:         "key",
+			old:         "value",
+			wantDeleted: true,
+		},
+		{
+			name:        "test different value",
+			key:         "key",
+			old:         "other",
+			wantDeleted: false,
+		},
+		{
+			name:        "test not exists key",
+			key:         "other",
+			old:         "value",
+			wantDeleted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &SyncMap[string, string]{}
+			m.Store("key", "value")
+			if got := m.CompareAndDelete(tt.key, tt.old); got != tt.wantDeleted {
+				t.Errorf("CompareAndDelete() = %v, want %v", got, tt.wantDeleted)
+			}
+			if _, ok := m.Load("key"); ok == tt.wantDeleted {
+				t.Errorf("CompareAndDelete() key exists = %v, want %v", ok, !tt.wantDeleted)
+			}
+		})
+	}
+}
+
 func TestSyncMap_LoadOrStore(t *testing.T) {
 	type args[K comparable, V any] struct {
 		key   K
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+	"time"
+)
+
+// FreezableClock is a Clock that can be frozen,
+// the time stands still while it is frozen, so the delaying queues using it do not release any item.
+type FreezableClock struct {
+	clock Clock
+
+	mut      sync.Mutex
+	frozen   bool
+	frozenAt time.Time
+	// offset is the total frozen duration that has been shifted,
+	// the time of this clock is behind the underlying clock by the offset.
+	offset time.Duration
+	// resumed is closed when the clock is resumed.
+	resumed chan time.Time
+}
+
+// NewFreezableClock returns a new FreezableClock on top of the given clock.
+func NewFreezableClock(clock Clock) *FreezableClock {
+	return &FreezableClock{
+		clock: clock,
+	}
+}
+
+// Now returns the current time of the clock, it does not change while the clock is frozen.
+func (c *FreezableClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.frozen {
+		return c.frozenAt.Add(-c.offset)
+	}
+	return c.clock.Now().Add(-c.offset)
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel,
+// if the clock is frozen, the returned channel is closed when the clock is resumed instead,
+// so that the waiter can check the time again.
+func (c *FreezableClock) After(d time.Duration) <-chan time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.frozen {
+		return c.resumed
+	}
+	return c.clock.After(d)
+}
+
+// Sleep pauses the current goroutine for at least the duration of the unfrozen time.
+func (c *FreezableClock) Sleep(d time.Duration) {
+	for d > 0 {
+		start := c.Now()
+		<-c.After(d)
+		d -= c.Now().Sub(start)
+	}
+}
+
+// Freeze freezes the clock, it returns false if the clock is already frozen.
+func (c *FreezableClock) Freeze() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.frozen {
+		return false
+	}
+	c.frozen = true
+	c.frozenAt = c.clock.Now()
+	c.resumed = make(chan time.Time)
+	return true
+}
+
+// Resume resumes the clock, it returns false if the clock is not frozen.
+// If shift is true, the time continues from when the clock was frozen,
+// so the remaining delays are the same as when it was frozen,
+// otherwise the time jumps to the current time and the delays that passed while frozen are elapsed.
+func (c *FreezableClock) Resume(shift bool) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if !c.frozen {
+		return false
+	}
+	if shift {
+		c.offset += c.clock.Now().Sub(c.frozenAt)
+	}
+	c.frozen = false
+	close(c.resumed)
+	c.resumed = nil
+	return true
+}
+
+// Frozen returns true if the clock is frozen.
+func (c *FreezableClock) Frozen() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.frozen
+}
+
+// WallTime returns the time of the underlying clock corresponding to the given time of this clock,
+// the time that the clock is frozen from now on is not taken into account.
+func (c *FreezableClock) WallTime(t time.Time) time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return t.Add(c.offset)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	fakeclock "k8s.io/utils/clock/testing"
+)
+
+func TestFreezableClockWithPreservedDelays(t *testing.T) {
+	fakeClock := fakeclock.NewFakeClock(time.Now())
+	clock := NewFreezableClock(fakeClock)
+	pdq := NewWeightDelayingQueue[string](clock)
+
+	pdq.AddWeightAfter("foo", 1, 500*time.Millisecond)
+
+	if !clock.Freeze() {
+		t.Fatal("expected to freeze the clock")
+	}
+	if clock.Freeze() {
+		t.Fatal("expected the clock to be already frozen")
+	}
+
+	fakeClock.Step(600 * time.Millisecond)
+	err := checkIncreased(pdq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !clock.Resume(false) {
+		t.Fatal("expected to resume the clock")
+	}
+	err = checkLength(pdq, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := clock.WallTime(clock.Now()); !got.Equal(fakeClock.Now()) {
+		t.Fatalf("expected wall time %v, got %v", fakeClock.Now(), got)
+	}
+}
+
+func TestFreezableClockWithShiftedDelays(t *testing.T) {
+	fakeClock := fakeclock.NewFakeClock(time.Now())
+	clock := NewFreezableClock(fakeClock)
+	pdq := NewWeightDelayingQueue[string](clock)
+
+	pdq.AddWeightAfter("foo", 1, 500*time.Millisecond)
+
+	fakeClock.Step(100 * time.Millisecond)
+	clock.Freeze()
+
+	fakeClock.Step(time.Second)
+	err := checkIncreased(pdq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Resume(true)
+	if clock.Resume(true) {
+		t.Fatal("expected the clock to be not frozen")
+	}
+	err = checkIncreased(pdq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeClock.Step(400 * time.Millisecond)
+	err = checkLength(pdq, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := fakeClock.Now().Add(-time.Second)
+	if got := clock.Now(); !got.Equal(want) {
+		t.Fatalf("expected time %v, got %v", want, got)
+	}
+	if got := clock.WallTime(clock.Now()); !got.Equal(fakeClock.Now()) {
+		t.Fatalf("expected wall time %v, got %v", fakeClock.Now(), got)
+	}
+}
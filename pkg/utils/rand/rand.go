@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rand
+
+import (
+	"hash/fnv"
+	"math/rand"
+	randv2 "math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	mut    sync.Mutex
+	seed   int64
+	source = newSource(time.Now().UnixNano())
+	// draws is the number of the sources returned for the hash of each key since seeded.
+	draws = map[uint64]uint64{}
+)
+
+func newSource(seed int64) *rand.Rand {
+	//nolint:gosec
+	return rand.New(rand.NewSource(seed))
+}
+
+// Seed resets the source with the seed,
+// so the same sequence of random numbers is returned after it.
+func Seed(s int64) {
+	mut.Lock()
+	defer mut.Unlock()
+	seed = s
+	source = newSource(s)
+	clear(draws)
+}
+
+// Source is a source of random numbers.
+type Source interface {
+	// Int63n returns a random number in [0, n), it panics if n <= 0.
+	Int63n(n int64) int64
+	// Intn returns a random number in [0, n), it panics if n <= 0.
+	Intn(n int) int
+	// Float64 returns a random number in [0.0, 1.0).
+	Float64() float64
+}
+
+// ForKey returns the source of the next draw for the key, which is derived from the seed,
+// the key and the number of the draws for the key before,
+// so the numbers drawn for a key do not depend on the order of drawing for the other keys,
+// and vary between the draws of the same key.
+// The shared source is returned if no seed is set.
+func ForKey(key string) Source {
+	mut.Lock()
+	s := seed
+	if s == 0 {
+		mut.Unlock()
+		return sharedSource{}
+	}
+	k := hashKey(key)
+	n := draws[k]
+	draws[k] = n + 1
+	mut.Unlock()
+
+	//nolint:gosec
+	return keyedSource{randv2.New(randv2.NewPCG(uint64(s), hashKey(key+"/"+strconv.FormatUint(n, 10))))}
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Jitter returns a duration between duration and duration + maxFactor * duration,
+// drawn from the source of the key, the same as wait.Jitter if no seed is set.
+func Jitter(key string, duration time.Duration, maxFactor float64) time.Duration {
+	if maxFactor <= 0.0 {
+		maxFactor = 1.0
+	}
+	return duration + time.Duration(ForKey(key).Float64()*maxFactor*float64(duration))
+}
+
+type sharedSource struct{}
+
+func (sharedSource) Int63n(n int64) int64 {
+	return Int63n(n)
+}
+
+func (sharedSource) Intn(n int) int {
+	return Intn(n)
+}
+
+func (sharedSource) Float64() float64 {
+	return Float64()
+}
+
+type keyedSource struct {
+	rand *randv2.Rand
+}
+
+func (s keyedSource) Int63n(n int64) int64 {
+	return s.rand.Int64N(n)
+}
+
+func (s keyedSource) Intn(n int) int {
+	return s.rand.IntN(n)
+}
+
+func (s keyedSource) Float64() float64 {
+	return s.rand.Float64()
+}
+
+// Int63n returns a random number in [0, n), it panics if n <= 0.
+func Int63n(n int64) int64 {
+	mut.Lock()
+	defer mut.Unlock()
+	return source.Int63n(n)
+}
+
+// Intn returns a random number in [0, n), it panics if n <= 0.
+func Intn(n int) int {
+	mut.Lock()
+	defer mut.Unlock()
+	return source.Intn(n)
+}
+
+// Float64 returns a random number in [0.0, 1.0).
+func Float64() float64 {
+	mut.Lock()
+	defer mut.Unlock()
+	return source.Float64()
+}
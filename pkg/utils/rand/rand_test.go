@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rand
+
+import (
+	"testing"
+)
+
+func TestSeed(t *testing.T) {
+	Seed(1)
+	want := []int64{Int63n(100), Int63n(100), Int63n(100)}
+
+	Seed(1)
+	got := []int64{Int63n(100), Int63n(100), Int63n(100)}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v after seeding again, got %v", want, got)
+		}
+	}
+}
+
+func TestForKey(t *testing.T) {
+	Seed(1)
+	want := []int64{ForKey("a").Int63n(100), ForKey("b").Int63n(100)}
+
+	// The numbers of a key do not depend on the other keys drawn before.
+	Seed(1)
+	for range 10 {
+		Int63n(100)
+		ForKey("c").Int63n(100)
+	}
+	got := []int64{ForKey("a").Int63n(100), ForKey("b").Int63n(100)}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v for the same keys, got %v", want, got)
+		}
+	}
+
+	// The numbers of a key vary between the draws, and are the same for the same seed.
+	draw := func() []int64 {
+		Seed(1)
+		var nums []int64
+		for range 10 {
+			nums = append(nums, ForKey("a").Int63n(100))
+		}
+		return nums
+	}
+	want = draw()
+	got = draw()
+	distinct := map[int64]struct{}{}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v for the same seed, got %v", want, got)
+		}
+		distinct[want[i]] = struct{}{}
+	}
+	if len(distinct) == 1 {
+		t.Errorf("expected the numbers to vary between the draws of a key, got %v", want)
+	}
+
+	Seed(1)
+	a := ForKey("a").Int63n(1 << 62)
+	Seed(2)
+	if ForKey("a").Int63n(1<<62) == a {
+		t.Errorf("expected different numbers with a different seed")
+	}
+}